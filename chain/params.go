@@ -1,7 +1,10 @@
 package chain
 
 import (
+	"encoding/json"
 	"math/big"
+
+	"github.com/juanidrobo/polygon-edge/types"
 )
 
 // Params are all the set of params for the chain
@@ -10,6 +13,18 @@ type Params struct {
 	ChainID        int                    `json:"chainID"`
 	Engine         map[string]interface{} `json:"engine"`
 	BlockGasTarget uint64                 `json:"blockGasTarget"`
+
+	// StatefulPrecompiles are the native contracts enabled on the chain
+	StatefulPrecompiles []*StatefulPrecompile `json:"statefulPrecompiles,omitempty"`
+}
+
+// StatefulPrecompile enables a stateful precompiled contract at an address, from the given block.
+// The name selects the contract implementation, which is configured with the given config
+type StatefulPrecompile struct {
+	Name    string          `json:"name"`
+	Address types.Address   `json:"address"`
+	Block   Fork            `json:"block"`
+	Config  json.RawMessage `json:"config,omitempty"`
 }
 
 func (p *Params) GetEngine() string {
@@ -31,6 +46,14 @@ type Forks struct {
 	EIP150         *Fork `json:"EIP150,omitempty"`
 	EIP158         *Fork `json:"EIP158,omitempty"`
 	EIP155         *Fork `json:"EIP155,omitempty"`
+	EIP3529        *Fork `json:"EIP3529,omitempty"`
+	EIP3541        *Fork `json:"EIP3541,omitempty"`
+	EIP3855        *Fork `json:"EIP3855,omitempty"`
+	EIP3860        *Fork `json:"EIP3860,omitempty"`
+	EIP1153        *Fork `json:"EIP1153,omitempty"`
+	EIP5656        *Fork `json:"EIP5656,omitempty"`
+	EIP2537        *Fork `json:"EIP2537,omitempty"`
+	RIP7212        *Fork `json:"RIP7212,omitempty"`
 }
 
 func (f *Forks) active(ff *Fork, block uint64) bool {
@@ -69,6 +92,38 @@ func (f *Forks) IsEIP155(block uint64) bool {
 	return f.active(f.EIP155, block)
 }
 
+func (f *Forks) IsEIP3529(block uint64) bool {
+	return f.active(f.EIP3529, block)
+}
+
+func (f *Forks) IsEIP3541(block uint64) bool {
+	return f.active(f.EIP3541, block)
+}
+
+func (f *Forks) IsEIP3855(block uint64) bool {
+	return f.active(f.EIP3855, block)
+}
+
+func (f *Forks) IsEIP3860(block uint64) bool {
+	return f.active(f.EIP3860, block)
+}
+
+func (f *Forks) IsEIP1153(block uint64) bool {
+	return f.active(f.EIP1153, block)
+}
+
+func (f *Forks) IsEIP5656(block uint64) bool {
+	return f.active(f.EIP5656, block)
+}
+
+func (f *Forks) IsEIP2537(block uint64) bool {
+	return f.active(f.EIP2537, block)
+}
+
+func (f *Forks) IsRIP7212(block uint64) bool {
+	return f.active(f.RIP7212, block)
+}
+
 func (f *Forks) At(block uint64) ForksInTime {
 	return ForksInTime{
 		Homestead:      f.active(f.Homestead, block),
@@ -79,6 +134,14 @@ func (f *Forks) At(block uint64) ForksInTime {
 		EIP150:         f.active(f.EIP150, block),
 		EIP158:         f.active(f.EIP158, block),
 		EIP155:         f.active(f.EIP155, block),
+		EIP3529:        f.active(f.EIP3529, block),
+		EIP3541:        f.active(f.EIP3541, block),
+		EIP3855:        f.active(f.EIP3855, block),
+		EIP3860:        f.active(f.EIP3860, block),
+		EIP1153:        f.active(f.EIP1153, block),
+		EIP5656:        f.active(f.EIP5656, block),
+		EIP2537:        f.active(f.EIP2537, block),
+		RIP7212:        f.active(f.RIP7212, block),
 	}
 }
 
@@ -106,7 +169,15 @@ type ForksInTime struct {
 	Istanbul,
 	EIP150,
 	EIP158,
-	EIP155 bool
+	EIP155,
+	EIP3529,
+	EIP3541,
+	EIP3855,
+	EIP3860,
+	EIP1153,
+	EIP5656,
+	EIP2537,
+	RIP7212 bool
 }
 
 var AllForksEnabled = &Forks{
@@ -118,4 +189,12 @@ var AllForksEnabled = &Forks{
 	Constantinople: NewFork(0),
 	Petersburg:     NewFork(0),
 	Istanbul:       NewFork(0),
+	EIP3529:        NewFork(0),
+	EIP3541:        NewFork(0),
+	EIP3855:        NewFork(0),
+	EIP3860:        NewFork(0),
+	EIP1153:        NewFork(0),
+	EIP5656:        NewFork(0),
+	EIP2537:        NewFork(0),
+	RIP7212:        NewFork(0),
 }
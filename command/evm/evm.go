@@ -0,0 +1,24 @@
+package evm
+
+import (
+	"github.com/juanidrobo/polygon-edge/command/evm/t8n"
+	"github.com/spf13/cobra"
+)
+
+func GetCommand() *cobra.Command {
+	evmCmd := &cobra.Command{
+		Use:   "evm",
+		Short: "Top level command for the standalone EVM tools. Only accepts subcommands.",
+	}
+
+	registerSubcommands(evmCmd)
+
+	return evmCmd
+}
+
+func registerSubcommands(baseCmd *cobra.Command) {
+	baseCmd.AddCommand(
+		// evm t8n
+		t8n.GetCommand(),
+	)
+}
@@ -0,0 +1,249 @@
+package t8n
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/juanidrobo/polygon-edge/chain"
+	"github.com/juanidrobo/polygon-edge/crypto"
+	"github.com/juanidrobo/polygon-edge/types"
+)
+
+// env is the environment of the block the transactions are executed in
+type env struct {
+	Coinbase   types.Address
+	Difficulty uint64
+	GasLimit   uint64
+	Number     uint64
+	Timestamp  uint64
+
+	// BlockHashes are the hashes of the previous blocks, by number
+	BlockHashes map[uint64]types.Hash
+
+	// Ommers are the uncles of the block, rewarded along with the coinbase
+	Ommers []*ommer
+}
+
+type ommer struct {
+	Delta   uint64        `json:"delta"`
+	Address types.Address `json:"address"`
+}
+
+func (e *env) UnmarshalJSON(data []byte) error {
+	type env struct {
+		Coinbase    *types.Address        `json:"currentCoinbase"`
+		Difficulty  *string               `json:"currentDifficulty"`
+		GasLimit    *string               `json:"currentGasLimit"`
+		Number      *string               `json:"currentNumber"`
+		Timestamp   *string               `json:"currentTimestamp"`
+		BlockHashes map[string]types.Hash `json:"blockHashes"`
+		Ommers      []*ommer              `json:"ommers"`
+	}
+
+	var dec env
+	if err := json.Unmarshal(data, &dec); err != nil {
+		return err
+	}
+
+	var err, subErr error
+
+	parseError := func(field string, subErr error) {
+		err = multierror.Append(err, fmt.Errorf("%s: %w", field, subErr))
+	}
+
+	if dec.Coinbase == nil {
+		parseError("currentCoinbase", fmt.Errorf("missing"))
+	} else {
+		e.Coinbase = *dec.Coinbase
+	}
+
+	for _, f := range []struct {
+		name  string
+		value *string
+		dst   *uint64
+	}{
+		{"currentDifficulty", dec.Difficulty, &e.Difficulty},
+		{"currentGasLimit", dec.GasLimit, &e.GasLimit},
+		{"currentNumber", dec.Number, &e.Number},
+		{"currentTimestamp", dec.Timestamp, &e.Timestamp},
+	} {
+		if *f.dst, subErr = types.ParseUint64orHex(f.value); subErr != nil {
+			parseError(f.name, subErr)
+		}
+	}
+
+	e.BlockHashes = make(map[uint64]types.Hash, len(dec.BlockHashes))
+
+	for num, hash := range dec.BlockHashes {
+		n, subErr := strconv.ParseUint(num, 10, 64)
+		if subErr != nil {
+			parseError("blockHashes", subErr)
+
+			continue
+		}
+
+		e.BlockHashes[n] = hash
+	}
+
+	for _, o := range dec.Ommers {
+		// the uncles are at most 7 blocks behind
+		if o.Delta == 0 || o.Delta > 7 {
+			parseError("ommers", fmt.Errorf("invalid delta %d", o.Delta))
+		}
+	}
+
+	e.Ommers = dec.Ommers
+
+	return err
+}
+
+// transaction is an input transaction, either signed or along with the key to sign it with
+type transaction struct {
+	tx  *types.Transaction
+	key *ecdsa.PrivateKey
+}
+
+func (t *transaction) UnmarshalJSON(data []byte) error {
+	type transaction struct {
+		Nonce     *string `json:"nonce"`
+		GasPrice  *string `json:"gasPrice"`
+		Gas       *string `json:"gas"`
+		To        *string `json:"to"`
+		Value     *string `json:"value"`
+		Input     *string `json:"input"`
+		V         *string `json:"v"`
+		R         *string `json:"r"`
+		S         *string `json:"s"`
+		SecretKey *string `json:"secretKey"`
+	}
+
+	var dec transaction
+	if err := json.Unmarshal(data, &dec); err != nil {
+		return err
+	}
+
+	var err, subErr error
+
+	parseError := func(field string, subErr error) {
+		err = multierror.Append(err, fmt.Errorf("%s: %w", field, subErr))
+	}
+
+	tx := &types.Transaction{}
+
+	if tx.Nonce, subErr = types.ParseUint64orHex(dec.Nonce); subErr != nil {
+		parseError("nonce", subErr)
+	}
+
+	if tx.Gas, subErr = types.ParseUint64orHex(dec.Gas); subErr != nil {
+		parseError("gas", subErr)
+	}
+
+	if dec.To != nil && *dec.To != "" {
+		to := types.StringToAddress(*dec.To)
+		tx.To = &to
+	}
+
+	if tx.Input, subErr = types.ParseBytes(dec.Input); subErr != nil {
+		parseError("input", subErr)
+	}
+
+	for _, f := range []struct {
+		name  string
+		value *string
+		dst   **big.Int
+	}{
+		{"gasPrice", dec.GasPrice, &tx.GasPrice},
+		{"value", dec.Value, &tx.Value},
+		{"v", dec.V, &tx.V},
+		{"r", dec.R, &tx.R},
+		{"s", dec.S, &tx.S},
+	} {
+		if *f.dst, subErr = types.ParseUint256orHex(f.value); subErr != nil {
+			parseError(f.name, subErr)
+		}
+
+		if *f.dst == nil {
+			*f.dst = new(big.Int)
+		}
+	}
+
+	if dec.SecretKey != nil {
+		key, subErr := types.ParseBytes(dec.SecretKey)
+		if subErr == nil {
+			t.key, subErr = crypto.ParsePrivateKey(key)
+		}
+
+		if subErr != nil {
+			parseError("secretKey", subErr)
+		}
+	}
+
+	t.tx = tx
+
+	return err
+}
+
+// forks are the rules of the transition, by the fork names of the test suites
+var forks = map[string]*chain.Forks{
+	"Frontier": {},
+	"Homestead": {
+		Homestead: chain.NewFork(0),
+	},
+	"EIP150": {
+		Homestead: chain.NewFork(0),
+		EIP150:    chain.NewFork(0),
+	},
+	"EIP158": {
+		Homestead: chain.NewFork(0),
+		EIP150:    chain.NewFork(0),
+		EIP155:    chain.NewFork(0),
+		EIP158:    chain.NewFork(0),
+	},
+	"Byzantium": {
+		Homestead: chain.NewFork(0),
+		EIP150:    chain.NewFork(0),
+		EIP155:    chain.NewFork(0),
+		EIP158:    chain.NewFork(0),
+		Byzantium: chain.NewFork(0),
+	},
+	"Constantinople": {
+		Homestead:      chain.NewFork(0),
+		EIP150:         chain.NewFork(0),
+		EIP155:         chain.NewFork(0),
+		EIP158:         chain.NewFork(0),
+		Byzantium:      chain.NewFork(0),
+		Constantinople: chain.NewFork(0),
+	},
+	"ConstantinopleFix": {
+		Homestead:      chain.NewFork(0),
+		EIP150:         chain.NewFork(0),
+		EIP155:         chain.NewFork(0),
+		EIP158:         chain.NewFork(0),
+		Byzantium:      chain.NewFork(0),
+		Constantinople: chain.NewFork(0),
+		Petersburg:     chain.NewFork(0),
+	},
+	"Petersburg": {
+		Homestead:      chain.NewFork(0),
+		EIP150:         chain.NewFork(0),
+		EIP155:         chain.NewFork(0),
+		EIP158:         chain.NewFork(0),
+		Byzantium:      chain.NewFork(0),
+		Constantinople: chain.NewFork(0),
+		Petersburg:     chain.NewFork(0),
+	},
+	"Istanbul": {
+		Homestead:      chain.NewFork(0),
+		EIP150:         chain.NewFork(0),
+		EIP155:         chain.NewFork(0),
+		EIP158:         chain.NewFork(0),
+		Byzantium:      chain.NewFork(0),
+		Constantinople: chain.NewFork(0),
+		Petersburg:     chain.NewFork(0),
+		Istanbul:       chain.NewFork(0),
+	},
+}
@@ -0,0 +1,217 @@
+package t8n
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/juanidrobo/polygon-edge/chain"
+	"github.com/juanidrobo/polygon-edge/helper/hex"
+	"github.com/juanidrobo/polygon-edge/types"
+)
+
+const (
+	inputAllocFlag    = "input.alloc"
+	inputEnvFlag      = "input.env"
+	inputTxsFlag      = "input.txs"
+	outputBaseDirFlag = "output.basedir"
+	outputAllocFlag   = "output.alloc"
+	outputResultFlag  = "output.result"
+	outputBodyFlag    = "output.body"
+	forkFlag          = "state.fork"
+	rewardFlag        = "state.reward"
+	chainIDFlag       = "state.chainid"
+)
+
+const (
+	stdinStream  = "stdin"
+	stdoutStream = "stdout"
+	stderrStream = "stderr"
+)
+
+// The exit codes of the failures, the same as the ones of the transition tools of the other clients
+const (
+	exitCodeEVM              = 2
+	exitCodeConfig           = 3
+	exitCodeMissingBlockhash = 4
+	exitCodeJSON             = 10
+	exitCodeIO               = 11
+)
+
+var (
+	params = &t8nParams{}
+)
+
+// t8nError is a failure of the tool, along with its exit code
+type t8nError struct {
+	code int
+	err  error
+}
+
+func (e *t8nError) Error() string {
+	return e.err.Error()
+}
+
+func (e *t8nError) Unwrap() error {
+	return e.err
+}
+
+func newT8nError(code int, err error) error {
+	return &t8nError{code: code, err: err}
+}
+
+type t8nParams struct {
+	inputAlloc string
+	inputEnv   string
+	inputTxs   string
+
+	outputBaseDir string
+	outputAlloc   string
+	outputResult  string
+	outputBody    string
+
+	fork    string
+	reward  int64
+	chainID uint64
+}
+
+// stdinInput is the input read from the standard input, with all of the inputs set to 'stdin'
+type stdinInput struct {
+	Alloc json.RawMessage `json:"alloc"`
+	Env   json.RawMessage `json:"env"`
+	Txs   json.RawMessage `json:"txs"`
+}
+
+type inputs struct {
+	alloc map[types.Address]*chain.GenesisAccount
+	env   *env
+	txs   []*transaction
+}
+
+func (p *t8nParams) run() error {
+	config, ok := forks[p.fork]
+	if !ok {
+		return newT8nError(exitCodeConfig, fmt.Errorf("unsupported fork %q", p.fork))
+	}
+
+	in, err := p.readInputs()
+	if err != nil {
+		return err
+	}
+
+	out, err := applyTransition(in, &chain.Params{Forks: config, ChainID: int(p.chainID)}, p.reward)
+	if err != nil {
+		return err
+	}
+
+	return p.writeOutputs(out)
+}
+
+func (p *t8nParams) readInputs() (*inputs, error) {
+	var stdin stdinInput
+
+	if p.inputAlloc == stdinStream || p.inputEnv == stdinStream || p.inputTxs == stdinStream {
+		if err := json.NewDecoder(os.Stdin).Decode(&stdin); err != nil {
+			return nil, newT8nError(exitCodeJSON, fmt.Errorf("failed to decode the standard input: %w", err))
+		}
+	}
+
+	in := &inputs{}
+
+	for _, i := range []struct {
+		name  string
+		path  string
+		stdin json.RawMessage
+		obj   interface{}
+	}{
+		{"alloc", p.inputAlloc, stdin.Alloc, &in.alloc},
+		{"env", p.inputEnv, stdin.Env, &in.env},
+		{"txs", p.inputTxs, stdin.Txs, &in.txs},
+	} {
+		data := []byte(i.stdin)
+
+		if i.path != stdinStream {
+			var err error
+
+			if data, err = ioutil.ReadFile(i.path); err != nil {
+				return nil, newT8nError(exitCodeIO, fmt.Errorf("failed to read the %s: %w", i.name, err))
+			}
+		}
+
+		if err := json.Unmarshal(data, i.obj); err != nil {
+			return nil, newT8nError(exitCodeJSON, fmt.Errorf("failed to decode the %s: %w", i.name, err))
+		}
+	}
+
+	if in.env == nil {
+		return nil, newT8nError(exitCodeJSON, fmt.Errorf("the env is missing"))
+	}
+
+	return in, nil
+}
+
+func (p *t8nParams) writeOutputs(out *transition) error {
+	stdout := map[string]interface{}{}
+	stderr := map[string]interface{}{}
+
+	for _, o := range []struct {
+		name string
+		path string
+		obj  interface{}
+	}{
+		{"alloc", p.outputAlloc, out.alloc},
+		{"result", p.outputResult, out.result},
+		{"body", p.outputBody, hex.EncodeToHex(out.body)},
+	} {
+		switch o.path {
+		case "":
+			continue
+		case stdoutStream:
+			stdout[o.name] = o.obj
+		case stderrStream:
+			stderr[o.name] = o.obj
+		default:
+			if err := writeJSON(filepath.Join(p.outputBaseDir, o.path), o.obj); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, s := range []struct {
+		file *os.File
+		objs map[string]interface{}
+	}{
+		{os.Stdout, stdout},
+		{os.Stderr, stderr},
+	} {
+		if len(s.objs) == 0 {
+			continue
+		}
+
+		data, err := json.MarshalIndent(s.objs, "", "  ")
+		if err != nil {
+			return newT8nError(exitCodeJSON, err)
+		}
+
+		if _, err := fmt.Fprintln(s.file, string(data)); err != nil {
+			return newT8nError(exitCodeIO, err)
+		}
+	}
+
+	return nil
+}
+
+func writeJSON(path string, obj interface{}) error {
+	data, err := json.MarshalIndent(obj, "", "  ")
+	if err != nil {
+		return newT8nError(exitCodeJSON, err)
+	}
+
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		return newT8nError(exitCodeIO, fmt.Errorf("failed to write %s: %w", path, err))
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package t8n
+
+import (
+	"github.com/juanidrobo/polygon-edge/chain"
+	"github.com/juanidrobo/polygon-edge/state"
+	"github.com/juanidrobo/polygon-edge/types"
+)
+
+// recordingState records the accounts and the storage slots committed through its snapshots.
+// The trie is keyed by their hashes, so they are needed to dump the post-state alloc
+type recordingState struct {
+	state.State
+
+	accounts map[types.Address]map[types.Hash]struct{}
+}
+
+func newRecordingState(s state.State) *recordingState {
+	return &recordingState{
+		State:    s,
+		accounts: map[types.Address]map[types.Hash]struct{}{},
+	}
+}
+
+func (s *recordingState) NewSnapshotAt(root types.Hash) (state.Snapshot, error) {
+	snap, err := s.State.NewSnapshotAt(root)
+	if err != nil {
+		return nil, err
+	}
+
+	return &recordingSnapshot{Snapshot: snap, state: s}, nil
+}
+
+func (s *recordingState) NewSnapshot() state.Snapshot {
+	return &recordingSnapshot{Snapshot: s.State.NewSnapshot(), state: s}
+}
+
+func (s *recordingState) record(objs []*state.Object) {
+	for _, obj := range objs {
+		slots, ok := s.accounts[obj.Address]
+		if !ok {
+			slots = map[types.Hash]struct{}{}
+			s.accounts[obj.Address] = slots
+		}
+
+		for _, entry := range obj.Storage {
+			slots[types.BytesToHash(entry.Key)] = struct{}{}
+		}
+	}
+}
+
+// dump returns the alloc of the recorded accounts that exist in the snapshot,
+// with their non empty storage slots
+func (s *recordingState) dump(snap state.Snapshot) map[types.Address]*chain.GenesisAccount {
+	txn := state.NewTxn(s, snap)
+	alloc := map[types.Address]*chain.GenesisAccount{}
+
+	for addr, slots := range s.accounts {
+		if !txn.Exist(addr) {
+			continue
+		}
+
+		account := &chain.GenesisAccount{
+			Balance: txn.GetBalance(addr),
+			Nonce:   txn.GetNonce(addr),
+		}
+
+		if code := txn.GetCode(addr); len(code) != 0 {
+			account.Code = code
+		}
+
+		for slot := range slots {
+			if value := txn.GetState(addr, slot); value != types.ZeroHash {
+				if account.Storage == nil {
+					account.Storage = map[types.Hash]types.Hash{}
+				}
+
+				account.Storage[slot] = value
+			}
+		}
+
+		alloc[addr] = account
+	}
+
+	return alloc
+}
+
+type recordingSnapshot struct {
+	state.Snapshot
+
+	state *recordingState
+}
+
+func (s *recordingSnapshot) Commit(objs []*state.Object) (state.Snapshot, []byte) {
+	s.state.record(objs)
+
+	snap, root := s.Snapshot.Commit(objs)
+
+	return &recordingSnapshot{Snapshot: snap, state: s.state}, root
+}
@@ -0,0 +1,109 @@
+package t8n
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func GetCommand() *cobra.Command {
+	t8nCmd := &cobra.Command{
+		Use: "t8n",
+		Short: "Executes a state transition with the standard transition tool interface: " +
+			"the pre-state alloc, the block env and the transactions in, the result and the post-state alloc out",
+		Run: runCommand,
+	}
+
+	setFlags(t8nCmd)
+
+	return t8nCmd
+}
+
+func setFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&params.inputAlloc,
+		inputAllocFlag,
+		"alloc.json",
+		"the file of the pre-state alloc, or 'stdin' to read it from the standard input",
+	)
+
+	cmd.Flags().StringVar(
+		&params.inputEnv,
+		inputEnvFlag,
+		"env.json",
+		"the file of the block env, or 'stdin' to read it from the standard input",
+	)
+
+	cmd.Flags().StringVar(
+		&params.inputTxs,
+		inputTxsFlag,
+		"txs.json",
+		"the file of the transactions, or 'stdin' to read them from the standard input",
+	)
+
+	cmd.Flags().StringVar(
+		&params.outputBaseDir,
+		outputBaseDirFlag,
+		"",
+		"the directory of the output files",
+	)
+
+	cmd.Flags().StringVar(
+		&params.outputAlloc,
+		outputAllocFlag,
+		"alloc.json",
+		"the file of the post-state alloc, or 'stdout' / 'stderr' to write it to the standard streams",
+	)
+
+	cmd.Flags().StringVar(
+		&params.outputResult,
+		outputResultFlag,
+		"result.json",
+		"the file of the result, or 'stdout' / 'stderr' to write it to the standard streams",
+	)
+
+	cmd.Flags().StringVar(
+		&params.outputBody,
+		outputBodyFlag,
+		"",
+		"the file of the RLP encoded transactions of the block, or 'stdout' / 'stderr'. Not written if empty",
+	)
+
+	cmd.Flags().StringVar(
+		&params.fork,
+		forkFlag,
+		"Istanbul",
+		"the name of the fork rules",
+	)
+
+	cmd.Flags().Int64Var(
+		&params.reward,
+		rewardFlag,
+		0,
+		"the mining reward of the block, a negative value disables the rewards",
+	)
+
+	cmd.Flags().Uint64Var(
+		&params.chainID,
+		chainIDFlag,
+		1,
+		"the chain ID of the transactions",
+	)
+}
+
+// runCommand does not go through the outputter, since the outputs are the files of the
+// transition tool interface, and the failures are reported with its exit codes
+func runCommand(_ *cobra.Command, _ []string) {
+	if err := params.run(); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+
+		var t8nErr *t8nError
+		if errors.As(err, &t8nErr) {
+			os.Exit(t8nErr.code)
+		}
+
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,255 @@
+package t8n
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/juanidrobo/polygon-edge/chain"
+	"github.com/juanidrobo/polygon-edge/crypto"
+	"github.com/juanidrobo/polygon-edge/helper/hex"
+	"github.com/juanidrobo/polygon-edge/helper/keccak"
+	"github.com/juanidrobo/polygon-edge/state"
+	itrie "github.com/juanidrobo/polygon-edge/state/immutable-trie"
+	"github.com/juanidrobo/polygon-edge/state/runtime/evm"
+	"github.com/juanidrobo/polygon-edge/state/runtime/precompiled"
+	"github.com/juanidrobo/polygon-edge/types"
+	"github.com/juanidrobo/polygon-edge/types/buildroot"
+	"github.com/umbracle/fastrlp"
+)
+
+// transition is the outcome of the execution of the transactions on top of the pre-state
+type transition struct {
+	alloc  map[types.Address]*chain.GenesisAccount
+	result *result
+	body   []byte
+}
+
+type result struct {
+	StateRoot    types.Hash   `json:"stateRoot"`
+	TxRoot       types.Hash   `json:"txRoot"`
+	ReceiptsRoot types.Hash   `json:"receiptsRoot"`
+	LogsHash     types.Hash   `json:"logsHash"`
+	LogsBloom    types.Bloom  `json:"logsBloom"`
+	Receipts     []*receipt   `json:"receipts"`
+	Rejected     []*rejection `json:"rejected,omitempty"`
+	Difficulty   string       `json:"currentDifficulty"`
+	GasUsed      string       `json:"gasUsed"`
+}
+
+type receipt struct {
+	Root              string        `json:"root"`
+	Status            *string       `json:"status,omitempty"`
+	CumulativeGasUsed string        `json:"cumulativeGasUsed"`
+	LogsBloom         types.Bloom   `json:"logsBloom"`
+	Logs              []*log        `json:"logs"`
+	TxHash            types.Hash    `json:"transactionHash"`
+	ContractAddress   types.Address `json:"contractAddress"`
+	GasUsed           string        `json:"gasUsed"`
+	BlockHash         types.Hash    `json:"blockHash"`
+	TxIndex           string        `json:"transactionIndex"`
+}
+
+type log struct {
+	Address     types.Address `json:"address"`
+	Topics      []types.Hash  `json:"topics"`
+	Data        string        `json:"data"`
+	BlockNumber string        `json:"blockNumber"`
+	TxHash      types.Hash    `json:"transactionHash"`
+	TxIndex     string        `json:"transactionIndex"`
+	BlockHash   types.Hash    `json:"blockHash"`
+	LogIndex    string        `json:"logIndex"`
+	Removed     bool          `json:"removed"`
+}
+
+// rejection is a transaction left out of the block, since it is invalid
+type rejection struct {
+	Index int    `json:"index"`
+	Error string `json:"error"`
+}
+
+// applyTransition executes the transactions in the block env on top of the pre-state,
+// and pays the mining rewards unless the reward is negative
+func applyTransition(in *inputs, config *chain.Params, reward int64) (*transition, error) {
+	s := newRecordingState(itrie.NewState(itrie.NewMemoryStorage()))
+
+	executor := state.NewExecutor(config, s, hclog.NewNullLogger())
+	executor.SetRuntime(precompiled.NewPrecompiled())
+	executor.SetRuntime(evm.NewEVM())
+
+	var missingHash error
+
+	executor.GetHash = func(*types.Header) func(uint64) types.Hash {
+		return func(num uint64) types.Hash {
+			hash, ok := in.env.BlockHashes[num]
+			if !ok && missingHash == nil {
+				missingHash = fmt.Errorf("the hash of the block %d is missing from the env", num)
+			}
+
+			return hash
+		}
+	}
+
+	header := &types.Header{
+		Miner:      in.env.Coinbase,
+		Difficulty: in.env.Difficulty,
+		GasLimit:   in.env.GasLimit,
+		Number:     in.env.Number,
+		Timestamp:  in.env.Timestamp,
+	}
+
+	txn, err := executor.BeginTxn(executor.WriteGenesis(in.alloc), header, in.env.Coinbase)
+	if err != nil {
+		return nil, newT8nError(exitCodeEVM, err)
+	}
+
+	signer := crypto.NewSigner(config.Forks.At(header.Number), uint64(config.ChainID))
+
+	var (
+		included []*types.Transaction
+		rejected []*rejection
+	)
+
+	for i, t := range in.txs {
+		tx := t.tx
+
+		if t.key != nil {
+			if tx, err = signer.SignTx(tx, t.key); err != nil {
+				rejected = append(rejected, &rejection{Index: i, Error: err.Error()})
+
+				continue
+			}
+		}
+
+		tx.ComputeHash()
+
+		if err := txn.Write(tx); err != nil {
+			rejected = append(rejected, &rejection{Index: i, Error: err.Error()})
+
+			continue
+		}
+
+		included = append(included, tx)
+	}
+
+	if missingHash != nil {
+		return nil, newT8nError(exitCodeMissingBlockhash, missingHash)
+	}
+
+	if reward >= 0 {
+		payRewards(txn.Txn(), in.env, big.NewInt(reward))
+	}
+
+	snap, root := txn.Commit()
+
+	return &transition{
+		alloc:  s.dump(snap),
+		result: newResult(root, header, included, txn.Receipts(), rejected),
+		body:   encodeTransactions(included),
+	}, nil
+}
+
+// payRewards pays the mining reward to the coinbase and to the uncles, with the ethash rules
+func payRewards(txn *state.Txn, env *env, reward *big.Int) {
+	minerReward := new(big.Int).Set(reward)
+	inclusionReward := new(big.Int).Div(reward, big.NewInt(32))
+
+	for _, o := range env.Ommers {
+		ommerReward := new(big.Int).Mul(reward, new(big.Int).SetUint64(8-o.Delta))
+		ommerReward.Div(ommerReward, big.NewInt(8))
+
+		txn.AddSealingReward(o.Address, ommerReward)
+		minerReward.Add(minerReward, inclusionReward)
+	}
+
+	// the coinbase is touched even without any reward
+	txn.AddSealingReward(env.Coinbase, minerReward)
+}
+
+func newResult(
+	root types.Hash,
+	header *types.Header,
+	txs []*types.Transaction,
+	receipts []*types.Receipt,
+	rejected []*rejection,
+) *result {
+	res := &result{
+		StateRoot:    root,
+		TxRoot:       buildroot.CalculateTransactionsRoot(txs),
+		ReceiptsRoot: buildroot.CalculateReceiptsRoot(receipts),
+		LogsBloom:    types.CreateBloom(receipts),
+		Receipts:     make([]*receipt, 0, len(receipts)),
+		Rejected:     rejected,
+		Difficulty:   hex.EncodeUint64(header.Difficulty),
+	}
+
+	var (
+		logs    []*types.Log
+		gasUsed uint64
+	)
+
+	for i, r := range receipts {
+		rr := &receipt{
+			Root:              hex.EncodeToHex(r.Root.Bytes()),
+			CumulativeGasUsed: hex.EncodeUint64(r.CumulativeGasUsed),
+			LogsBloom:         r.LogsBloom,
+			Logs:              make([]*log, 0, len(r.Logs)),
+			TxHash:            r.TxHash,
+			ContractAddress:   r.ContractAddress,
+			GasUsed:           hex.EncodeUint64(r.GasUsed),
+			TxIndex:           hex.EncodeUint64(uint64(i)),
+		}
+
+		// the receipts have either the intermediate state root, or the status since Byzantium
+		if r.Status != nil {
+			status := hex.EncodeUint64(uint64(*r.Status))
+			rr.Root, rr.Status = "0x", &status
+		}
+
+		for _, l := range r.Logs {
+			rr.Logs = append(rr.Logs, &log{
+				Address:     l.Address,
+				Topics:      l.Topics,
+				Data:        hex.EncodeToHex(l.Data),
+				BlockNumber: hex.EncodeUint64(header.Number),
+				TxHash:      r.TxHash,
+				TxIndex:     rr.TxIndex,
+				LogIndex:    hex.EncodeUint64(uint64(len(logs))),
+			})
+
+			logs = append(logs, l)
+		}
+
+		res.Receipts = append(res.Receipts, rr)
+		gasUsed = r.CumulativeGasUsed
+	}
+
+	res.LogsHash = hashLogs(logs)
+	res.GasUsed = hex.EncodeUint64(gasUsed)
+
+	return res
+}
+
+// hashLogs returns the keccak256 hash of the RLP list of the logs
+func hashLogs(logs []*types.Log) (res types.Hash) {
+	r := &types.Receipt{
+		Logs: logs,
+	}
+
+	ar := &fastrlp.Arena{}
+	keccak.Keccak256Rlp(res[:0], r.MarshalLogsWith(ar))
+
+	return
+}
+
+// encodeTransactions returns the RLP list of the transactions
+func encodeTransactions(txs []*types.Transaction) []byte {
+	ar := &fastrlp.Arena{}
+
+	v := ar.NewArray()
+	for _, tx := range txs {
+		v.Set(tx.MarshalRLPWith(ar))
+	}
+
+	return v.MarshalTo(nil)
+}
@@ -0,0 +1,74 @@
+package t8n
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestT8n(t *testing.T) {
+	cases := []struct {
+		name   string
+		dir    string
+		fork   string
+		reward int64
+		code   int
+	}{
+		{"transfer, call, rejection and creation", "1", "Byzantium", 2e18, 0},
+		{"unsupported fork", "1", "Unknown", 0, exitCodeConfig},
+		{"missing inputs", "missing", "Byzantium", 0, exitCodeIO},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.name, func(t *testing.T) {
+			dir := filepath.Join("testdata", c.dir)
+			out := t.TempDir()
+
+			p := &t8nParams{
+				inputAlloc:    filepath.Join(dir, "alloc.json"),
+				inputEnv:      filepath.Join(dir, "env.json"),
+				inputTxs:      filepath.Join(dir, "txs.json"),
+				outputBaseDir: out,
+				outputAlloc:   "alloc.json",
+				outputResult:  "result.json",
+				fork:          c.fork,
+				reward:        c.reward,
+				chainID:       1,
+			}
+
+			err := p.run()
+			if c.code != 0 {
+				var t8nErr *t8nError
+
+				assert.True(t, errors.As(err, &t8nErr))
+				assert.Equal(t, c.code, t8nErr.code)
+
+				return
+			}
+
+			assert.NoError(t, err)
+
+			expected, err := ioutil.ReadFile(filepath.Join(dir, "exp.json"))
+			assert.NoError(t, err)
+
+			var exp struct {
+				Alloc  json.RawMessage `json:"alloc"`
+				Result json.RawMessage `json:"result"`
+			}
+
+			assert.NoError(t, json.Unmarshal(expected, &exp))
+
+			for name, want := range map[string]json.RawMessage{"alloc.json": exp.Alloc, "result.json": exp.Result} {
+				have, err := ioutil.ReadFile(filepath.Join(out, name))
+				assert.NoError(t, err)
+				assert.JSONEq(t, string(want), string(have), name)
+			}
+		})
+	}
+}
@@ -139,6 +139,20 @@ func setFlags(cmd *cobra.Command) {
 		common.MaxSafeJSInt,
 		"the maximum number of validators in the validator set for PoS",
 	)
+	cmd.Flags().StringArrayVar(
+		&params.txAllowListAdmins,
+		txAllowListAdminFlag,
+		[]string{},
+		"the admin address of the transaction senders allowlist, which is enabled if set. "+
+			"This flag can be used multiple times",
+	)
+	cmd.Flags().StringArrayVar(
+		&params.deployerAllowListAdmins,
+		deployerAllowListAdminFlag,
+		[]string{},
+		"the admin address of the contract deployers allowlist, which is enabled if set. "+
+			"This flag can be used multiple times",
+	)
 }
 
 // setLegacyFlags sets the legacy flags to preserve backwards compatibility
@@ -1,6 +1,7 @@
 package genesis
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 
@@ -9,8 +10,10 @@ import (
 	"github.com/juanidrobo/polygon-edge/command/helper"
 	"github.com/juanidrobo/polygon-edge/consensus/ibft"
 	"github.com/juanidrobo/polygon-edge/contracts/staking"
+	"github.com/juanidrobo/polygon-edge/helper/hex"
 	stakingHelper "github.com/juanidrobo/polygon-edge/helper/staking"
 	"github.com/juanidrobo/polygon-edge/server"
+	"github.com/juanidrobo/polygon-edge/state/runtime/allowlist"
 	"github.com/juanidrobo/polygon-edge/types"
 )
 
@@ -28,6 +31,12 @@ const (
 	maxValidatorCount       = "max-validator-count"
 )
 
+// Allowlist flags
+const (
+	txAllowListAdminFlag       = "tx-allowlist-admin"
+	deployerAllowListAdminFlag = "contract-deployer-allowlist-admin"
+)
+
 // Legacy flags that need to be preserved for running clients
 const (
 	chainIDFlagLEGACY = "chainid"
@@ -44,6 +53,7 @@ var (
 	errUnsupportedConsensus           = errors.New("specified consensusRaw not supported")
 	errMissingBootnode                = errors.New("at least 1 bootnode is required")
 	errInvalidEpochSize               = errors.New("epoch size must be greater than 1")
+	errInvalidAllowListAdmin          = errors.New("invalid allowlist admin, expected a non-zero hex address")
 )
 
 type genesisParams struct {
@@ -65,6 +75,9 @@ type genesisParams struct {
 	minNumValidators uint64
 	maxNumValidators uint64
 
+	txAllowListAdmins       []string
+	deployerAllowListAdmins []string
+
 	extraData []byte
 	consensus server.ConsensusType
 
@@ -116,6 +129,25 @@ func (p *genesisParams) validateFlags() error {
 		return err
 	}
 
+	// Check the allowlist admins are valid addresses, a wrong admin would lock the allowlist
+	for _, admins := range [][]string{p.txAllowListAdmins, p.deployerAllowListAdmins} {
+		for _, admin := range admins {
+			if err := validateAllowListAdmin(admin); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateAllowListAdmin checks the admin is a hex encoded address, other than the zero one
+func validateAllowListAdmin(admin string) error {
+	buf, err := hex.DecodeHex(admin)
+	if err != nil || len(buf) != types.AddressLength || types.BytesToAddress(buf) == types.ZeroAddress {
+		return fmt.Errorf("%w: %s", errInvalidAllowListAdmin, admin)
+	}
+
 	return nil
 }
 
@@ -290,11 +322,55 @@ func (p *genesisParams) initGenesisConfig() error {
 		return err
 	}
 
+	// Enable the allowlists, with their admins
+	allowLists, err := p.getAllowLists()
+	if err != nil {
+		return err
+	}
+
+	chainConfig.Params.StatefulPrecompiles = allowLists
+
 	p.genesisConfig = chainConfig
 
 	return nil
 }
 
+// getAllowLists returns the allowlists enabled at genesis, the ones with admins
+func (p *genesisParams) getAllowLists() ([]*chain.StatefulPrecompile, error) {
+	allowLists := make([]*chain.StatefulPrecompile, 0)
+
+	for _, list := range []struct {
+		name   string
+		addr   types.Address
+		admins []string
+	}{
+		{allowlist.TxAllowListName, allowlist.TxAllowListAddr, p.txAllowListAdmins},
+		{allowlist.DeployerAllowListName, allowlist.DeployerAllowListAddr, p.deployerAllowListAdmins},
+	} {
+		if len(list.admins) == 0 {
+			continue
+		}
+
+		config := &allowlist.Config{}
+		for _, admin := range list.admins {
+			config.Admins = append(config.Admins, types.StringToAddress(admin))
+		}
+
+		rawConfig, err := json.Marshal(config)
+		if err != nil {
+			return nil, err
+		}
+
+		allowLists = append(allowLists, &chain.StatefulPrecompile{
+			Name:    list.name,
+			Address: list.addr,
+			Config:  rawConfig,
+		})
+	}
+
+	return allowLists, nil
+}
+
 func (p *genesisParams) shouldPredeployStakingSC() bool {
 	// If the consensus selected is IBFT / Dev and the mechanism is Proof of Stake,
 	// deploy the Staking SC
@@ -53,5 +53,7 @@ func (p *statusParams) getResult() command.CommandResult {
 		ID:        p.peerStatus.Id,
 		Protocols: p.peerStatus.Protocols,
 		Addresses: p.peerStatus.Addrs,
+		BytesIn:   p.peerStatus.BytesIn,
+		BytesOut:  p.peerStatus.BytesOut,
 	}
 }
@@ -10,6 +10,8 @@ type PeersStatusResult struct {
 	ID        string   `json:"id"`
 	Protocols []string `json:"protocols"`
 	Addresses []string `json:"addresses"`
+	BytesIn   uint64   `json:"bytes_in"`
+	BytesOut  uint64   `json:"bytes_out"`
 }
 
 func (r *PeersStatusResult) GetOutput() string {
@@ -20,6 +22,8 @@ func (r *PeersStatusResult) GetOutput() string {
 		fmt.Sprintf("ID|%s", r.ID),
 		fmt.Sprintf("Protocols|%s", r.Protocols),
 		fmt.Sprintf("Addresses|%s", r.Addresses),
+		fmt.Sprintf("Bytes received|%d", r.BytesIn),
+		fmt.Sprintf("Bytes sent|%d", r.BytesOut),
 	}))
 	buffer.WriteString("\n")
 
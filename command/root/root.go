@@ -3,6 +3,7 @@ package root
 import (
 	"fmt"
 	"github.com/juanidrobo/polygon-edge/command/backup"
+	"github.com/juanidrobo/polygon-edge/command/evm"
 	"github.com/juanidrobo/polygon-edge/command/genesis"
 	"github.com/juanidrobo/polygon-edge/command/helper"
 	"github.com/juanidrobo/polygon-edge/command/ibft"
@@ -12,6 +13,7 @@ import (
 	"github.com/juanidrobo/polygon-edge/command/peers"
 	"github.com/juanidrobo/polygon-edge/command/secrets"
 	"github.com/juanidrobo/polygon-edge/command/server"
+	"github.com/juanidrobo/polygon-edge/command/snapshot"
 	"github.com/juanidrobo/polygon-edge/command/status"
 	"github.com/juanidrobo/polygon-edge/command/txpool"
 	"github.com/juanidrobo/polygon-edge/command/version"
@@ -50,7 +52,9 @@ func (rc *RootCommand) registerSubCommands() {
 		backup.GetCommand(),
 		genesis.GetCommand(),
 		server.GetCommand(),
+		snapshot.GetCommand(),
 		license.GetCommand(),
+		evm.GetCommand(),
 	)
 }
 
@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"strings"
 
+	"github.com/juanidrobo/polygon-edge/jsonrpc"
 	"github.com/juanidrobo/polygon-edge/network"
 
 	"github.com/hashicorp/hcl"
@@ -13,20 +14,32 @@ import (
 
 // Config defines the server configuration params
 type Config struct {
-	GenesisPath       string     `json:"chain_config"`
-	SecretsConfigPath string     `json:"secrets_config"`
-	DataDir           string     `json:"data_dir"`
-	BlockGasTarget    string     `json:"block_gas_target"`
-	GRPCAddr          string     `json:"grpc_addr"`
-	JSONRPCAddr       string     `json:"jsonrpc_addr"`
-	Telemetry         *Telemetry `json:"telemetry"`
-	Network           *Network   `json:"network"`
-	ShouldSeal        bool       `json:"seal"`
-	TxPool            *TxPool    `json:"tx_pool"`
-	LogLevel          string     `json:"log_level"`
-	RestoreFile       string     `json:"restore_file"`
-	BlockTime         uint64     `json:"block_time_s"`
-	Headers           *Headers   `json:"headers"`
+	GenesisPath       string          `json:"chain_config"`
+	SecretsConfigPath string          `json:"secrets_config"`
+	DataDir           string          `json:"data_dir"`
+	BlockGasTarget    string          `json:"block_gas_target"`
+	GRPCAddr          string          `json:"grpc_addr"`
+	JSONRPCAddr       string          `json:"jsonrpc_addr"`
+	WSAddr            string          `json:"ws_addr"`
+	HTTPNamespaces    []string        `json:"http_namespaces"`
+	WSNamespaces      []string        `json:"ws_namespaces"`
+	HTTPJWTSecretPath string          `json:"http_jwt_secret_path"`
+	WSJWTSecretPath   string          `json:"ws_jwt_secret_path"`
+	JSONRPCAdmin      bool            `json:"json_rpc_admin"`
+	IPCPath           string          `json:"ipc_path"`
+	NoIPC             bool            `json:"no_ipc"`
+	Telemetry         *Telemetry      `json:"telemetry"`
+	Network           *Network        `json:"network"`
+	ShouldSeal        bool            `json:"seal"`
+	TxPool            *TxPool         `json:"tx_pool"`
+	GasPriceOracle    *GasPriceOracle `json:"gas_price_oracle"`
+	JSONRPCLimits     *JSONRPCLimits  `json:"json_rpc_limits"`
+	LogLevel          string          `json:"log_level"`
+	RestoreFile       string          `json:"restore_file"`
+	BlockTime         uint64          `json:"block_time_s"`
+	Headers           *Headers        `json:"headers"`
+	ParallelExecution uint64          `json:"parallel_execution"`
+	StateSnapshot     uint64          `json:"state_snapshot_layers"`
 }
 
 // Telemetry holds the config details for metric services.
@@ -36,19 +49,41 @@ type Telemetry struct {
 
 // Network defines the network configuration params
 type Network struct {
-	NoDiscover       bool   `json:"no_discover"`
-	Libp2pAddr       string `json:"libp2p_addr"`
-	NatAddr          string `json:"nat_addr"`
-	DNSAddr          string `json:"dns_addr"`
-	MaxPeers         int64  `json:"max_peers,omitempty"`
-	MaxOutboundPeers int64  `json:"max_outbound_peers,omitempty"`
-	MaxInboundPeers  int64  `json:"max_inbound_peers,omitempty"`
+	NoDiscover       bool     `json:"no_discover"`
+	Libp2pAddr       string   `json:"libp2p_addr"`
+	NatAddr          string   `json:"nat_addr"`
+	DNSAddr          string   `json:"dns_addr"`
+	NATPortMap       bool     `json:"nat_port_map"`
+	NATService       bool     `json:"nat_service"`
+	Relays           []string `json:"relays"`
+	RelayService     bool     `json:"relay_service"`
+	RateLimits       []string `json:"rate_limits"`
+	MaxPeers         int64    `json:"max_peers,omitempty"`
+	MaxOutboundPeers int64    `json:"max_outbound_peers,omitempty"`
+	MaxInboundPeers  int64    `json:"max_inbound_peers,omitempty"`
 }
 
 // TxPool defines the TxPool configuration params
 type TxPool struct {
 	PriceLimit uint64 `json:"price_limit"`
 	MaxSlots   uint64 `json:"max_slots"`
+	NoGossip   bool   `json:"no_gossip"`
+}
+
+// GasPriceOracle defines the JSON-RPC gas price oracle configuration params
+type GasPriceOracle struct {
+	Blocks     uint64 `json:"blocks"`
+	Percentile uint64 `json:"percentile"`
+}
+
+// JSONRPCLimits defines the limits enforced on the JSON-RPC requests, zero values disable them
+type JSONRPCLimits struct {
+	BatchLength      uint64   `json:"batch_length"`
+	BodySize         uint64   `json:"body_size"`
+	BlockRange       uint64   `json:"block_range"`
+	MethodTimeouts   []string `json:"method_timeouts"`
+	IPRateLimit      float64  `json:"ip_rate_limit"`
+	MethodRateLimits []string `json:"method_rate_limits"`
 }
 
 // Headers defines the HTTP response headers required to enable CORS.
@@ -69,6 +104,8 @@ func DefaultConfig() *Config {
 		BlockGasTarget: "0x0", // Special value signaling the parent gas limit should be applied
 		Network: &Network{
 			NoDiscover:       defaultNetworkConfig.NoDiscover,
+			Relays:           []string{},
+			RateLimits:       []string{},
 			MaxPeers:         defaultNetworkConfig.MaxPeers,
 			MaxOutboundPeers: defaultNetworkConfig.MaxOutboundPeers,
 			MaxInboundPeers:  defaultNetworkConfig.MaxInboundPeers,
@@ -78,6 +115,22 @@ func DefaultConfig() *Config {
 		TxPool: &TxPool{
 			PriceLimit: 0,
 			MaxSlots:   4096,
+			NoGossip:   false,
+		},
+		GasPriceOracle: &GasPriceOracle{
+			Blocks:     jsonrpc.DefaultGasPriceBlocks,
+			Percentile: jsonrpc.DefaultGasPricePercentile,
+		},
+		JSONRPCLimits: &JSONRPCLimits{
+			BatchLength: jsonrpc.DefaultBatchLengthLimit,
+			BodySize:    uint64(jsonrpc.DefaultBodySizeLimit),
+			BlockRange:  jsonrpc.DefaultBlockRangeLimit,
+			MethodTimeouts: []string{
+				"eth_call=" + jsonrpc.DefaultCallTimeout.String(),
+				"eth_estimateGas=" + jsonrpc.DefaultCallTimeout.String(),
+				"eth_createAccessList=" + jsonrpc.DefaultCallTimeout.String(),
+			},
+			MethodRateLimits: []string{},
 		},
 		LogLevel:    "INFO",
 		RestoreFile: "",
@@ -3,15 +3,23 @@ package server
 import (
 	"fmt"
 	"github.com/juanidrobo/polygon-edge/network/common"
+	"io/ioutil"
 	"math"
 	"net"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/juanidrobo/polygon-edge/chain"
 	"github.com/juanidrobo/polygon-edge/command/helper"
+	"github.com/juanidrobo/polygon-edge/helper/hex"
+	"github.com/juanidrobo/polygon-edge/helper/ipc"
+	"github.com/juanidrobo/polygon-edge/jsonrpc"
 	"github.com/juanidrobo/polygon-edge/network"
 	"github.com/juanidrobo/polygon-edge/secrets"
 	"github.com/juanidrobo/polygon-edge/server"
 	"github.com/juanidrobo/polygon-edge/types"
+	"github.com/libp2p/go-libp2p-core/peer"
 )
 
 func (p *serverParams) initConfigFromFile() error {
@@ -43,9 +51,139 @@ func (p *serverParams) initRawParams() error {
 
 	p.initPeerLimits()
 
+	if err := p.initRateLimits(); err != nil {
+		return err
+	}
+
+	if err := p.initGasPriceOracle(); err != nil {
+		return err
+	}
+
+	if err := p.initJSONRPCLimits(); err != nil {
+		return err
+	}
+
+	if err := p.initJWTSecrets(); err != nil {
+		return err
+	}
+
 	return p.initAddresses()
 }
 
+func (p *serverParams) initGasPriceOracle() error {
+	if p.rawConfig.GasPriceOracle.Percentile > 100 {
+		return fmt.Errorf("%w: %d", errInvalidPercentile, p.rawConfig.GasPriceOracle.Percentile)
+	}
+
+	return nil
+}
+
+func (p *serverParams) initRateLimits() error {
+	var parseErr error
+
+	p.rateLimits, parseErr = parseRateLimits(p.rawConfig.Network.RateLimits)
+
+	return parseErr
+}
+
+func (p *serverParams) initJSONRPCLimits() error {
+	rawLimits := p.rawConfig.JSONRPCLimits
+
+	if rawLimits.BodySize > math.MaxInt64 {
+		return fmt.Errorf("%w: %d", errInvalidBodyLimit, rawLimits.BodySize)
+	}
+
+	if rawLimits.IPRateLimit < 0 {
+		return fmt.Errorf("%w: %f", errInvalidRateLimit, rawLimits.IPRateLimit)
+	}
+
+	methodRateLimits, parseErr := parseRateLimits(rawLimits.MethodRateLimits)
+	if parseErr != nil {
+		return parseErr
+	}
+
+	methodTimeouts := make(map[string]time.Duration, len(rawLimits.MethodTimeouts))
+
+	for _, rawTimeout := range rawLimits.MethodTimeouts {
+		parts := strings.SplitN(rawTimeout, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("%w: %s", errInvalidTimeout, rawTimeout)
+		}
+
+		timeout, parseErr := time.ParseDuration(parts[1])
+		if parseErr != nil || timeout < 0 {
+			return fmt.Errorf("%w: %s", errInvalidTimeout, rawTimeout)
+		}
+
+		methodTimeouts[parts[0]] = timeout
+	}
+
+	p.jsonRPCLimits = jsonrpc.Limits{
+		BatchLength:      rawLimits.BatchLength,
+		BodySize:         int64(rawLimits.BodySize),
+		BlockRange:       rawLimits.BlockRange,
+		MethodTimeouts:   methodTimeouts,
+		IPRateLimit:      rawLimits.IPRateLimit,
+		MethodRateLimits: methodRateLimits,
+	}
+
+	return nil
+}
+
+func (p *serverParams) initJWTSecrets() error {
+	var err error
+
+	if p.httpJWTSecret, err = readJWTSecret(p.rawConfig.HTTPJWTSecretPath); err != nil {
+		return err
+	}
+
+	if p.wsJWTSecret, err = readJWTSecret(p.rawConfig.WSJWTSecretPath); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// readJWTSecret reads the hex encoded JWT secret from the file, if any
+func readJWTSecret(path string) ([]byte, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read JWT secret file, %w", err)
+	}
+
+	secret, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(string(raw)), "0x"))
+	if err != nil || len(secret) != jsonrpc.JWTSecretLength {
+		return nil, fmt.Errorf("%w: %s", errInvalidJWTSecret, path)
+	}
+
+	return secret, nil
+}
+
+// parseRateLimits parses the rate limits in the form <method>=<requests per second>
+func parseRateLimits(rawLimits []string) (map[string]float64, error) {
+	rateLimits := make(map[string]float64, len(rawLimits))
+
+	for _, rawLimit := range rawLimits {
+		parts := strings.SplitN(rawLimit, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%w: %s", errInvalidRateLimit, rawLimit)
+		}
+
+		rate, parseErr := strconv.ParseFloat(parts[1], 64)
+		if parseErr != nil || rate <= 0 {
+			return nil, fmt.Errorf("%w: %s", errInvalidRateLimit, rawLimit)
+		}
+
+		rateLimits[parts[0]] = rate
+	}
+
+	return rateLimits, nil
+}
+
 func (p *serverParams) initBlockGasTarget() error {
 	var parseErr error
 
@@ -180,10 +318,20 @@ func (p *serverParams) initAddresses() error {
 		return err
 	}
 
+	if err := p.initRelays(); err != nil {
+		return err
+	}
+
 	if err := p.initJSONRPCAddress(); err != nil {
 		return err
 	}
 
+	if err := p.initWSAddress(); err != nil {
+		return err
+	}
+
+	p.initIPCPath()
+
 	return p.initGRPCAddress()
 }
 
@@ -247,6 +395,31 @@ func (p *serverParams) initDNSAddress() error {
 	return nil
 }
 
+func (p *serverParams) initRelays() error {
+	p.relays = make([]*peer.AddrInfo, 0, len(p.rawConfig.Network.Relays))
+
+	for _, rawAddr := range p.rawConfig.Network.Relays {
+		relay, parseErr := common.StringToAddrInfo(rawAddr)
+		if parseErr != nil {
+			return fmt.Errorf("failed to parse relay %s: %w", rawAddr, parseErr)
+		}
+
+		p.relays = append(p.relays, relay)
+	}
+
+	return nil
+}
+
+func (p *serverParams) initIPCPath() {
+	if p.rawConfig.NoIPC {
+		return
+	}
+
+	if p.ipcPath = p.rawConfig.IPCPath; p.ipcPath == "" {
+		p.ipcPath = ipc.DefaultPath(p.rawConfig.DataDir)
+	}
+}
+
 func (p *serverParams) initJSONRPCAddress() error {
 	var parseErr error
 
@@ -260,6 +433,23 @@ func (p *serverParams) initJSONRPCAddress() error {
 	return nil
 }
 
+func (p *serverParams) initWSAddress() error {
+	if p.rawConfig.WSAddr == "" {
+		return nil
+	}
+
+	var parseErr error
+
+	if p.wsAddress, parseErr = helper.ResolveAddr(
+		p.rawConfig.WSAddr,
+		helper.AllInterfacesBinding,
+	); parseErr != nil {
+		return parseErr
+	}
+
+	return nil
+}
+
 func (p *serverParams) initGRPCAddress() error {
 	var parseErr error
 
@@ -3,10 +3,12 @@ package server
 import (
 	"errors"
 	"github.com/juanidrobo/polygon-edge/chain"
+	"github.com/juanidrobo/polygon-edge/jsonrpc"
 	"github.com/juanidrobo/polygon-edge/network"
 	"github.com/juanidrobo/polygon-edge/secrets"
 	"github.com/juanidrobo/polygon-edge/server"
 	"github.com/hashicorp/go-hclog"
+	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/multiformats/go-multiaddr"
 	"net"
 )
@@ -19,12 +21,20 @@ const (
 	prometheusAddressFlag = "prometheus"
 	natFlag               = "nat"
 	dnsFlag               = "dns"
+	natPortMapFlag        = "nat-port-map"
+	natServiceFlag        = "nat-service"
+	relayFlag             = "relay"
+	relayServiceFlag      = "relay-service"
+	rateLimitFlag         = "rate-limit"
 	sealFlag              = "seal"
 	maxPeersFlag          = "max-peers"
 	maxInboundPeersFlag   = "max-inbound-peers"
 	maxOutboundPeersFlag  = "max-outbound-peers"
 	priceLimitFlag        = "price-limit"
 	maxSlotsFlag          = "max-slots"
+	noTxGossipFlag        = "no-tx-gossip"
+	gpoBlocksFlag         = "gpo-blocks"
+	gpoPercentileFlag     = "gpo-percentile"
 	blockGasTargetFlag    = "block-gas-target"
 	secretsConfigFlag     = "secrets-config"
 	restoreFlag           = "restore"
@@ -32,6 +42,22 @@ const (
 	devIntervalFlag       = "dev-interval"
 	devFlag               = "dev"
 	corsOriginFlag        = "access-control-allow-origins"
+	ipcPathFlag           = "ipc-path"
+	noIPCFlag             = "no-ipc"
+	jsonRPCBatchLimitFlag = "json-rpc-batch-limit"
+	jsonRPCBodyLimitFlag  = "json-rpc-body-limit"
+	jsonRPCRangeLimitFlag = "json-rpc-block-range-limit"
+	jsonRPCTimeoutFlag    = "json-rpc-timeout"
+	jsonRPCIPRateFlag     = "json-rpc-ip-rate-limit"
+	jsonRPCRateLimitFlag  = "json-rpc-rate-limit"
+	wsAddressFlag         = "ws-address"
+	httpNamespacesFlag    = "http-namespaces"
+	wsNamespacesFlag      = "ws-namespaces"
+	httpJWTSecretFlag     = "http-jwt-secret"
+	wsJWTSecretFlag       = "ws-jwt-secret"
+	jsonRPCAdminFlag      = "json-rpc-admin"
+	parallelExecFlag      = "parallel-execution"
+	stateSnapshotFlag     = "state-snapshot-layers"
 )
 
 const (
@@ -41,9 +67,11 @@ const (
 var (
 	params = &serverParams{
 		rawConfig: &Config{
-			Telemetry: &Telemetry{},
-			Network:   &Network{},
-			TxPool:    &TxPool{},
+			Telemetry:      &Telemetry{},
+			Network:        &Network{},
+			TxPool:         &TxPool{},
+			GasPriceOracle: &GasPriceOracle{},
+			JSONRPCLimits:  &JSONRPCLimits{},
 		},
 	}
 )
@@ -51,6 +79,11 @@ var (
 var (
 	errInvalidPeerParams = errors.New("both max-peers and max-inbound/outbound flags are set")
 	errInvalidNATAddress = errors.New("could not parse NAT IP address")
+	errInvalidRateLimit  = errors.New("invalid rate limit, expected <method>=<requests per second>")
+	errInvalidPercentile = errors.New("invalid gas price oracle percentile, expected a value between 0 and 100")
+	errInvalidTimeout    = errors.New("invalid JSON-RPC timeout, expected <method>=<duration>")
+	errInvalidBodyLimit  = errors.New("invalid JSON-RPC body size limit")
+	errInvalidJWTSecret  = errors.New("invalid JWT secret, expected 32 hex encoded bytes")
 )
 
 type serverParams struct {
@@ -61,8 +94,15 @@ type serverParams struct {
 	prometheusAddress *net.TCPAddr
 	natAddress        net.IP
 	dnsAddress        multiaddr.Multiaddr
+	relays            []*peer.AddrInfo
+	rateLimits        map[string]float64
 	grpcAddress       *net.TCPAddr
 	jsonRPCAddress    *net.TCPAddr
+	wsAddress         *net.TCPAddr
+	httpJWTSecret     []byte
+	wsJWTSecret       []byte
+	ipcPath           string
+	jsonRPCLimits     jsonrpc.Limits
 
 	blockGasTarget uint64
 	devInterval    uint64
@@ -133,7 +173,17 @@ func (p *serverParams) generateConfig() *server.Config {
 		Chain: p.genesisConfig,
 		JSONRPC: &server.JSONRPC{
 			JSONRPCAddr:              p.jsonRPCAddress,
+			WSAddr:                   p.wsAddress,
+			IPCPath:                  p.ipcPath,
 			AccessControlAllowOrigin: p.corsAllowedOrigins,
+			GasPriceBlocks:           p.rawConfig.GasPriceOracle.Blocks,
+			GasPricePercentile:       p.rawConfig.GasPriceOracle.Percentile,
+			Limits:                   p.jsonRPCLimits,
+			HTTPNamespaces:           p.rawConfig.HTTPNamespaces,
+			WSNamespaces:             p.rawConfig.WSNamespaces,
+			HTTPJWTSecret:            p.httpJWTSecret,
+			WSJWTSecret:              p.wsJWTSecret,
+			EnableAdmin:              p.rawConfig.JSONRPCAdmin,
 		},
 		GRPCAddr:   p.grpcAddress,
 		LibP2PAddr: p.libp2pAddress,
@@ -145,6 +195,11 @@ func (p *serverParams) generateConfig() *server.Config {
 			Addr:             p.libp2pAddress,
 			NatAddr:          p.natAddress,
 			DNS:              p.dnsAddress,
+			NATPortMap:       p.rawConfig.Network.NATPortMap,
+			NATService:       p.rawConfig.Network.NATService,
+			Relays:           p.relays,
+			RelayService:     p.rawConfig.Network.RelayService,
+			RateLimits:       p.rateLimits,
 			DataDir:          p.rawConfig.DataDir,
 			MaxPeers:         p.rawConfig.Network.MaxPeers,
 			MaxInboundPeers:  p.rawConfig.Network.MaxInboundPeers,
@@ -155,9 +210,13 @@ func (p *serverParams) generateConfig() *server.Config {
 		Seal:           p.rawConfig.ShouldSeal,
 		PriceLimit:     p.rawConfig.TxPool.PriceLimit,
 		MaxSlots:       p.rawConfig.TxPool.MaxSlots,
+		NoTxGossip:     p.rawConfig.TxPool.NoGossip,
 		SecretsManager: p.secretsConfig,
 		RestoreFile:    p.getRestoreFilePath(),
 		BlockTime:      p.rawConfig.BlockTime,
 		LogLevel:       hclog.LevelFromString(p.rawConfig.LogLevel),
+
+		ParallelExecution:   p.rawConfig.ParallelExecution,
+		StateSnapshotLayers: p.rawConfig.StateSnapshot,
 	}
 }
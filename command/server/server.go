@@ -89,6 +89,43 @@ func setFlags(cmd *cobra.Command) {
 		"the host DNS address which can be used by a remote peer for connection",
 	)
 
+	cmd.Flags().BoolVar(
+		&params.rawConfig.Network.NATPortMap,
+		natPortMapFlag,
+		false,
+		"try to map the libp2p port on the NAT device using UPnP or NAT-PMP",
+	)
+
+	cmd.Flags().BoolVar(
+		&params.rawConfig.Network.NATService,
+		natServiceFlag,
+		false,
+		"dial back peers to let them detect whether they are publicly reachable. "+
+			"Should be enabled on publicly reachable nodes, such as the bootnodes",
+	)
+
+	cmd.Flags().StringArrayVar(
+		&params.rawConfig.Network.Relays,
+		relayFlag,
+		[]string{},
+		"the multiaddr of a relay used to stay reachable when the node is behind a NAT",
+	)
+
+	cmd.Flags().BoolVar(
+		&params.rawConfig.Network.RelayService,
+		relayServiceFlag,
+		false,
+		"relay connections for peers that are not publicly reachable, if this node is",
+	)
+
+	cmd.Flags().StringArrayVar(
+		&params.rawConfig.Network.RateLimits,
+		rateLimitFlag,
+		[]string{},
+		"the maximum number of requests per second served for a libp2p gRPC method, "+
+			"in the form <method>=<rate> (e.g. /v1.V1/GetHeaders=20)",
+	)
+
 	cmd.Flags().StringVar(
 		&params.rawConfig.BlockGasTarget,
 		blockGasTargetFlag,
@@ -169,6 +206,71 @@ func setFlags(cmd *cobra.Command) {
 		"maximum slots in the pool",
 	)
 
+	cmd.Flags().BoolVar(
+		&params.rawConfig.TxPool.NoGossip,
+		noTxGossipFlag,
+		defaultConfig.TxPool.NoGossip,
+		"stop publishing full transactions on the gossip topic, and rely only on hash announcements "+
+			"(transactions gossiped by other peers are still accepted)",
+	)
+
+	cmd.Flags().Uint64Var(
+		&params.rawConfig.GasPriceOracle.Blocks,
+		gpoBlocksFlag,
+		defaultConfig.GasPriceOracle.Blocks,
+		"the number of the latest blocks sampled by the gas price oracle",
+	)
+
+	cmd.Flags().Uint64Var(
+		&params.rawConfig.GasPriceOracle.Percentile,
+		gpoPercentileFlag,
+		defaultConfig.GasPriceOracle.Percentile,
+		"the percentile of the sampled transaction gas prices suggested by the gas price oracle",
+	)
+
+	cmd.Flags().Uint64Var(
+		&params.rawConfig.JSONRPCLimits.BatchLength,
+		jsonRPCBatchLimitFlag,
+		defaultConfig.JSONRPCLimits.BatchLength,
+		"the maximum number of requests in a JSON-RPC batch (0 for no limit)",
+	)
+
+	cmd.Flags().Uint64Var(
+		&params.rawConfig.JSONRPCLimits.BodySize,
+		jsonRPCBodyLimitFlag,
+		defaultConfig.JSONRPCLimits.BodySize,
+		"the maximum size of a JSON-RPC request in bytes (0 for no limit)",
+	)
+
+	cmd.Flags().Uint64Var(
+		&params.rawConfig.JSONRPCLimits.BlockRange,
+		jsonRPCRangeLimitFlag,
+		defaultConfig.JSONRPCLimits.BlockRange,
+		"the maximum block range queried by eth_getLogs (0 for no limit)",
+	)
+
+	cmd.Flags().StringArrayVar(
+		&params.rawConfig.JSONRPCLimits.MethodTimeouts,
+		jsonRPCTimeoutFlag,
+		defaultConfig.JSONRPCLimits.MethodTimeouts,
+		"the execution timeout of a JSON-RPC method, in the form <method>=<duration> (e.g. eth_call=5s)",
+	)
+
+	cmd.Flags().Float64Var(
+		&params.rawConfig.JSONRPCLimits.IPRateLimit,
+		jsonRPCIPRateFlag,
+		defaultConfig.JSONRPCLimits.IPRateLimit,
+		"the maximum number of JSON-RPC requests per second served to a client IP (0 for no limit)",
+	)
+
+	cmd.Flags().StringArrayVar(
+		&params.rawConfig.JSONRPCLimits.MethodRateLimits,
+		jsonRPCRateLimitFlag,
+		defaultConfig.JSONRPCLimits.MethodRateLimits,
+		"the maximum number of requests per second served for a JSON-RPC method, to all the clients, "+
+			"in the form <method>=<rate> (e.g. eth_call=100)",
+	)
+
 	cmd.Flags().Uint64Var(
 		&params.rawConfig.BlockTime,
 		blockTimeFlag,
@@ -176,6 +278,20 @@ func setFlags(cmd *cobra.Command) {
 		"minimum block time in seconds",
 	)
 
+	cmd.Flags().StringVar(
+		&params.rawConfig.IPCPath,
+		ipcPathFlag,
+		"",
+		"the path of the JSON-RPC IPC endpoint (default: <data-dir>/polygon-edge.ipc)",
+	)
+
+	cmd.Flags().BoolVar(
+		&params.rawConfig.NoIPC,
+		noIPCFlag,
+		false,
+		"disable the JSON-RPC IPC endpoint",
+	)
+
 	cmd.Flags().StringArrayVar(
 		&params.corsAllowedOrigins,
 		corsOriginFlag,
@@ -183,6 +299,68 @@ func setFlags(cmd *cobra.Command) {
 		"the CORS header indicating whether any JSON-RPC response can be shared with the specified origin",
 	)
 
+	cmd.Flags().StringVar(
+		&params.rawConfig.WSAddr,
+		wsAddressFlag,
+		"",
+		"the address and port of a separate JSON-RPC WS listener (default: served on the JSON-RPC one at /ws)",
+	)
+
+	cmd.Flags().StringArrayVar(
+		&params.rawConfig.HTTPNamespaces,
+		httpNamespacesFlag,
+		[]string{},
+		"the JSON-RPC namespaces served over HTTP (default: all except admin and debug)",
+	)
+
+	cmd.Flags().StringArrayVar(
+		&params.rawConfig.WSNamespaces,
+		wsNamespacesFlag,
+		[]string{},
+		"the JSON-RPC namespaces served over WS (default: all except admin and debug)",
+	)
+
+	cmd.Flags().StringVar(
+		&params.rawConfig.HTTPJWTSecretPath,
+		httpJWTSecretFlag,
+		"",
+		"the path of the hex encoded 32 byte secret authenticating the JSON-RPC HTTP requests "+
+			"with HS256 tokens (default: no authentication)",
+	)
+
+	cmd.Flags().StringVar(
+		&params.rawConfig.WSJWTSecretPath,
+		wsJWTSecretFlag,
+		"",
+		"the path of the hex encoded 32 byte secret authenticating the JSON-RPC WS requests "+
+			"with HS256 tokens (default: no authentication)",
+	)
+
+	cmd.Flags().BoolVar(
+		&params.rawConfig.JSONRPCAdmin,
+		jsonRPCAdminFlag,
+		false,
+		"enable the admin JSON-RPC namespace. It is served over IPC, "+
+			"and over HTTP / WS only if explicitly listed in their namespaces",
+	)
+
+	cmd.Flags().Uint64Var(
+		&params.rawConfig.ParallelExecution,
+		parallelExecFlag,
+		defaultConfig.ParallelExecution,
+		"the number of workers executing the transactions of the imported blocks optimistically in parallel "+
+			"(0 or 1 execute them sequentially). The transactions picked from the txpool while building "+
+			"a block are always executed sequentially",
+	)
+
+	cmd.Flags().Uint64Var(
+		&params.rawConfig.StateSnapshot,
+		stateSnapshotFlag,
+		defaultConfig.StateSnapshot,
+		"the number of latest block states kept in memory as diff layers of the flat state snapshot, "+
+			"which serves the state reads without walking the trie (0 disables the snapshot)",
+	)
+
 	setDevFlags(cmd)
 }
 
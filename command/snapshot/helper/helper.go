@@ -0,0 +1,54 @@
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/juanidrobo/polygon-edge/blockchain/storage/leveldb"
+	itrie "github.com/juanidrobo/polygon-edge/state/immutable-trie"
+	"github.com/juanidrobo/polygon-edge/types"
+)
+
+var (
+	errHeadNotFound = errors.New("head block not found in the blockchain storage")
+)
+
+// OpenHeadState opens the state storage of the data directory, along with
+// the head header of the blockchain. The node must be stopped
+func OpenHeadState(dataDir string) (itrie.Storage, *types.Header, error) {
+	logger := hclog.NewNullLogger()
+
+	// leveldb creates the missing storages
+	for _, dir := range []string{"blockchain", "trie"} {
+		if _, err := os.Stat(filepath.Join(dataDir, dir)); err != nil {
+			return nil, nil, fmt.Errorf("unable to find the %s storage, %w", dir, err)
+		}
+	}
+
+	blockchainStorage, err := leveldb.NewLevelDBStorage(filepath.Join(dataDir, "blockchain"), logger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to open the blockchain storage, %w", err)
+	}
+
+	defer blockchainStorage.Close()
+
+	hash, ok := blockchainStorage.ReadHeadHash()
+	if !ok {
+		return nil, nil, errHeadNotFound
+	}
+
+	header, err := blockchainStorage.ReadHeader(hash)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read the head header, %w", err)
+	}
+
+	stateStorage, err := itrie.NewLevelDBStorage(filepath.Join(dataDir, "trie"), logger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to open the state storage, %w", err)
+	}
+
+	return stateStorage, header, nil
+}
@@ -0,0 +1,59 @@
+package regenerate
+
+import (
+	"errors"
+
+	"github.com/juanidrobo/polygon-edge/command"
+	"github.com/juanidrobo/polygon-edge/command/snapshot/helper"
+	itrie "github.com/juanidrobo/polygon-edge/state/immutable-trie"
+)
+
+const (
+	dataDirFlag = "data-dir"
+)
+
+var (
+	params = &regenerateParams{}
+)
+
+var (
+	errInvalidParams = errors.New("no data directory passed in")
+)
+
+type regenerateParams struct {
+	dataDir string
+
+	number uint64
+	report *itrie.SnapshotReport
+}
+
+func (p *regenerateParams) validateFlags() error {
+	if p.dataDir == "" {
+		return errInvalidParams
+	}
+
+	return nil
+}
+
+func (p *regenerateParams) run() error {
+	storage, header, err := helper.OpenHeadState(p.dataDir)
+	if err != nil {
+		return err
+	}
+
+	defer storage.Close()
+
+	p.number = header.Number
+	p.report, err = itrie.RegenerateSnapshot(storage, header.StateRoot)
+
+	return err
+}
+
+func (p *regenerateParams) getResult() command.CommandResult {
+	return &SnapshotRegenerateResult{
+		Number:   p.number,
+		Root:     p.report.Root,
+		Accounts: p.report.Accounts,
+		Slots:    p.report.Slots,
+	}
+}
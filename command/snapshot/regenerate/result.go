@@ -0,0 +1,32 @@
+package regenerate
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/juanidrobo/polygon-edge/command/helper"
+	"github.com/juanidrobo/polygon-edge/types"
+)
+
+type SnapshotRegenerateResult struct {
+	Number   uint64     `json:"number"`
+	Root     types.Hash `json:"root"`
+	Accounts uint64     `json:"accounts"`
+	Slots    uint64     `json:"slots"`
+}
+
+func (r *SnapshotRegenerateResult) GetOutput() string {
+	var buffer bytes.Buffer
+
+	buffer.WriteString("\n[SNAPSHOT REGENERATE]\n")
+	buffer.WriteString("Regenerated the state snapshot successfully:\n")
+	buffer.WriteString(helper.FormatKV([]string{
+		fmt.Sprintf("Block|%d", r.Number),
+		fmt.Sprintf("State root|%s", r.Root),
+		fmt.Sprintf("Accounts|%d", r.Accounts),
+		fmt.Sprintf("Storage slots|%d", r.Slots),
+	}))
+	buffer.WriteString("\n")
+
+	return buffer.String()
+}
@@ -0,0 +1,46 @@
+package regenerate
+
+import (
+	"github.com/juanidrobo/polygon-edge/command"
+	"github.com/spf13/cobra"
+)
+
+func GetCommand() *cobra.Command {
+	snapshotRegenerateCmd := &cobra.Command{
+		Use: "regenerate",
+		Short: "Rebuilds the flat state snapshot from the state trie of the head block. " +
+			"The node must be stopped",
+		PreRunE: runPreRun,
+		Run:     runCommand,
+	}
+
+	setFlags(snapshotRegenerateCmd)
+
+	return snapshotRegenerateCmd
+}
+
+func setFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&params.dataDir,
+		dataDirFlag,
+		"",
+		"the data directory of the node",
+	)
+}
+
+func runPreRun(_ *cobra.Command, _ []string) error {
+	return params.validateFlags()
+}
+
+func runCommand(cmd *cobra.Command, _ []string) {
+	outputter := command.InitializeOutputter(cmd)
+	defer outputter.WriteOutput()
+
+	if err := params.run(); err != nil {
+		outputter.SetError(err)
+
+		return
+	}
+
+	outputter.SetCommandResult(params.getResult())
+}
@@ -0,0 +1,28 @@
+package snapshot
+
+import (
+	"github.com/juanidrobo/polygon-edge/command/snapshot/regenerate"
+	"github.com/juanidrobo/polygon-edge/command/snapshot/verify"
+	"github.com/spf13/cobra"
+)
+
+func GetCommand() *cobra.Command {
+	snapshotCmd := &cobra.Command{
+		Use: "snapshot",
+		Short: "Top level command for maintaining the flat state snapshot of a stopped node. " +
+			"Only accepts subcommands.",
+	}
+
+	registerSubcommands(snapshotCmd)
+
+	return snapshotCmd
+}
+
+func registerSubcommands(baseCmd *cobra.Command) {
+	baseCmd.AddCommand(
+		// snapshot regenerate
+		regenerate.GetCommand(),
+		// snapshot verify
+		verify.GetCommand(),
+	)
+}
@@ -0,0 +1,62 @@
+package verify
+
+import (
+	"errors"
+
+	"github.com/juanidrobo/polygon-edge/command"
+	"github.com/juanidrobo/polygon-edge/command/snapshot/helper"
+	itrie "github.com/juanidrobo/polygon-edge/state/immutable-trie"
+)
+
+const (
+	dataDirFlag = "data-dir"
+)
+
+var (
+	params = &verifyParams{}
+)
+
+var (
+	errInvalidParams = errors.New("no data directory passed in")
+)
+
+type verifyParams struct {
+	dataDir string
+
+	number uint64
+	report *itrie.SnapshotReport
+}
+
+func (p *verifyParams) validateFlags() error {
+	if p.dataDir == "" {
+		return errInvalidParams
+	}
+
+	return nil
+}
+
+func (p *verifyParams) run() error {
+	storage, header, err := helper.OpenHeadState(p.dataDir)
+	if err != nil {
+		return err
+	}
+
+	defer storage.Close()
+
+	p.number = header.Number
+	p.report, err = itrie.VerifySnapshot(storage, header.StateRoot)
+
+	return err
+}
+
+func (p *verifyParams) getResult() command.CommandResult {
+	return &SnapshotVerifyResult{
+		Number:   p.number,
+		Root:     p.report.Root,
+		Accounts: p.report.Accounts,
+		Slots:    p.report.Slots,
+
+		MismatchCount: p.report.MismatchCount,
+		Mismatches:    p.report.Mismatches,
+	}
+}
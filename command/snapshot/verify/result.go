@@ -0,0 +1,42 @@
+package verify
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/juanidrobo/polygon-edge/command/helper"
+	"github.com/juanidrobo/polygon-edge/types"
+)
+
+type SnapshotVerifyResult struct {
+	Number   uint64     `json:"number"`
+	Root     types.Hash `json:"root"`
+	Accounts uint64     `json:"accounts"`
+	Slots    uint64     `json:"slots"`
+
+	MismatchCount uint64   `json:"mismatch_count"`
+	Mismatches    []string `json:"mismatches"`
+}
+
+func (r *SnapshotVerifyResult) GetOutput() string {
+	var buffer bytes.Buffer
+
+	buffer.WriteString("\n[SNAPSHOT VERIFY]\n")
+	buffer.WriteString(helper.FormatKV([]string{
+		fmt.Sprintf("Block|%d", r.Number),
+		fmt.Sprintf("State root|%s", r.Root),
+		fmt.Sprintf("Accounts|%d", r.Accounts),
+		fmt.Sprintf("Storage slots|%d", r.Slots),
+		fmt.Sprintf("Consistent|%t", r.MismatchCount == 0),
+		fmt.Sprintf("Mismatches|%d", r.MismatchCount),
+	}))
+	buffer.WriteString("\n")
+
+	if len(r.Mismatches) != 0 {
+		buffer.WriteString("\n[MISMATCHES]\n")
+		buffer.WriteString(helper.FormatList(r.Mismatches))
+		buffer.WriteString("\n")
+	}
+
+	return buffer.String()
+}
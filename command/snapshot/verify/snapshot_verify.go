@@ -0,0 +1,46 @@
+package verify
+
+import (
+	"github.com/juanidrobo/polygon-edge/command"
+	"github.com/spf13/cobra"
+)
+
+func GetCommand() *cobra.Command {
+	snapshotVerifyCmd := &cobra.Command{
+		Use: "verify",
+		Short: "Checks the flat state snapshot against the state trie of the head block. " +
+			"The node must be stopped",
+		PreRunE: runPreRun,
+		Run:     runCommand,
+	}
+
+	setFlags(snapshotVerifyCmd)
+
+	return snapshotVerifyCmd
+}
+
+func setFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&params.dataDir,
+		dataDirFlag,
+		"",
+		"the data directory of the node",
+	)
+}
+
+func runPreRun(_ *cobra.Command, _ []string) error {
+	return params.validateFlags()
+}
+
+func runCommand(cmd *cobra.Command, _ []string) {
+	outputter := command.InitializeOutputter(cmd)
+	defer outputter.WriteOutput()
+
+	if err := params.run(); err != nil {
+		outputter.SetError(err)
+
+		return
+	}
+
+	outputter.SetCommandResult(params.getResult())
+}
@@ -11,6 +11,7 @@ type StatusResult struct {
 	CurrentBlockNumber int64  `json:"current_block_number"`
 	CurrentBlockHash   string `json:"current_block_hash"`
 	LibP2PAddress      string `json:"libp2p_address"`
+	Reachability       string `json:"reachability"`
 }
 
 func (r *StatusResult) GetOutput() string {
@@ -22,6 +23,7 @@ func (r *StatusResult) GetOutput() string {
 		fmt.Sprintf("Current Block Number (base 10)|%d", r.CurrentBlockNumber),
 		fmt.Sprintf("Current Block Hash|%s", r.CurrentBlockHash),
 		fmt.Sprintf("Libp2p Address|%s", r.LibP2PAddress),
+		fmt.Sprintf("Reachability|%s", r.Reachability),
 	}))
 
 	return buffer.String()
@@ -39,6 +39,7 @@ func runCommand(cmd *cobra.Command, _ []string) {
 		CurrentBlockNumber: statusResponse.Current.Number,
 		CurrentBlockHash:   statusResponse.Current.Hash,
 		LibP2PAddress:      statusResponse.P2PAddr,
+		Reachability:       statusResponse.Reachability,
 	})
 }
 
@@ -8,6 +8,7 @@ import (
 	"github.com/juanidrobo/polygon-edge/chain"
 	"github.com/juanidrobo/polygon-edge/helper/progress"
 	"github.com/juanidrobo/polygon-edge/network"
+	"github.com/juanidrobo/polygon-edge/protocol"
 	"github.com/juanidrobo/polygon-edge/secrets"
 	"github.com/juanidrobo/polygon-edge/state"
 	"github.com/juanidrobo/polygon-edge/txpool"
@@ -31,6 +32,10 @@ type Consensus interface {
 	// GetSyncProgression retrieves the current sync progression, if any
 	GetSyncProgression() *progress.Progression
 
+	// GetFinality returns the number of the latest finalized block, which can't be reverted anymore,
+	// and the one of the latest safe block, which is unlikely to be reverted
+	GetFinality() (finalized uint64, safe uint64)
+
 	// Initialize initializes the consensus (e.g. setup data)
 	Initialize() error
 
@@ -67,6 +72,7 @@ type ConsensusParams struct {
 	Grpc           *grpc.Server
 	Logger         hclog.Logger
 	Metrics        *Metrics
+	SyncerMetrics  *protocol.Metrics
 	SecretsManager secrets.SecretsManager
 	BlockTime      uint64
 }
@@ -224,6 +224,14 @@ func (d *Dev) GetSyncProgression() *progress.Progression {
 	return nil
 }
 
+// GetFinality returns the latest finalized and safe blocks.
+// The dev node is the only sealer, so every sealed block is final
+func (d *Dev) GetFinality() (uint64, uint64) {
+	head := d.blockchain.Header().Number
+
+	return head, head
+}
+
 func (d *Dev) Prepare(header *types.Header) error {
 	// TODO: Remove
 	return nil
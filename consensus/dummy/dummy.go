@@ -65,6 +65,13 @@ func (d *Dummy) GetSyncProgression() *progress.Progression {
 	return nil
 }
 
+// GetFinality returns the latest finalized and safe blocks.
+// Any block is accepted without verification, so only the genesis is final,
+// while the head is considered safe
+func (d *Dummy) GetFinality() (uint64, uint64) {
+	return 0, d.blockchain.Header().Number
+}
+
 func (d *Dummy) Close() error {
 	close(d.closeCh)
 
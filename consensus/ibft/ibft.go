@@ -172,7 +172,7 @@ func Factory(
 	// Istanbul requires a different header hash function
 	types.HeaderHash = istanbulHeaderHash
 
-	p.syncer = protocol.NewSyncer(params.Logger, params.Network, params.Blockchain)
+	p.syncer = protocol.NewSyncer(params.Logger, params.Network, params.Blockchain, params.SyncerMetrics)
 
 	return p, nil
 }
@@ -221,6 +221,14 @@ func (i *Ibft) GetSyncProgression() *progress.Progression {
 	return i.syncer.GetSyncProgression()
 }
 
+// GetFinality returns the latest finalized and safe blocks.
+// IBFT has instant finality, every committed block is final
+func (i *Ibft) GetFinality() (uint64, uint64) {
+	head := i.blockchain.Header().Number
+
+	return head, head
+}
+
 type transport interface {
 	Gossip(msg *proto.MessageReq) error
 }
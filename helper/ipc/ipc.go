@@ -0,0 +1,4 @@
+package ipc
+
+// defaultName is the name of the IPC endpoint
+const defaultName = "polygon-edge.ipc"
@@ -10,6 +10,11 @@ import (
 	"gopkg.in/natefinch/npipe.v2"
 )
 
+// DefaultPath returns the default IPC path. Named pipes can't live in the data directory
+func DefaultPath(_ string) string {
+	return `\\.\pipe\` + defaultName
+}
+
 // Dial dials an IPC path
 func Dial(path string) (net.Conn, error) {
 	return npipe.Dial(path)
@@ -0,0 +1,105 @@
+package jsonrpc
+
+import (
+	"fmt"
+
+	"github.com/juanidrobo/polygon-edge/types"
+	"github.com/juanidrobo/polygon-edge/versioning"
+)
+
+// PeerInfo is the libp2p information of a node
+type PeerInfo struct {
+	ID        string   `json:"id"`
+	Addrs     []string `json:"addrs"`
+	Protocols []string `json:"protocols,omitempty"`
+}
+
+// adminStore provides the methods needed by the admin endpoint
+type adminStore interface {
+	// Header returns the current header of the chain (genesis if empty)
+	Header() *types.Header
+
+	// GetLocalPeerInfo returns the libp2p information of the node
+	GetLocalPeerInfo() *PeerInfo
+
+	// GetPeersInfo returns the libp2p information of the connected peers
+	GetPeersInfo() ([]*PeerInfo, error)
+
+	// JoinPeer marks the peer with the given multiaddr ready for dialing
+	JoinPeer(rawPeerMultiaddr string) error
+
+	// RemovePeer disconnects from the peer with the given ID (or multiaddr),
+	// returning false if it wasn't connected
+	RemovePeer(rawPeer string) (bool, error)
+}
+
+// Admin is the admin jsonrpc endpoint, serving the node management methods.
+// It is a privileged namespace, never served over HTTP / WS unless explicitly allowed
+type Admin struct {
+	store   adminStore
+	chainID uint64
+	dataDir string
+}
+
+// nodeInfo is the information of the node returned by admin_nodeInfo
+type nodeInfo struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	P2PAddrs    []string `json:"p2pAddrs"`
+	ListenAddrs []string `json:"listenAddrs"`
+	ChainID     uint64   `json:"chainId"`
+	Head        struct {
+		Number argUint64  `json:"number"`
+		Hash   types.Hash `json:"hash"`
+	} `json:"head"`
+}
+
+// NodeInfo returns the libp2p identity and addresses of the node, along with its chain head
+func (a *Admin) NodeInfo() (interface{}, error) {
+	local := a.store.GetLocalPeerInfo()
+
+	info := &nodeInfo{
+		ID:          local.ID,
+		Name:        fmt.Sprintf("polygon-edge [%s]", versioning.Version),
+		P2PAddrs:    make([]string, 0, len(local.Addrs)),
+		ListenAddrs: local.Addrs,
+		ChainID:     a.chainID,
+	}
+
+	// the dialable addresses include the node ID
+	for _, addr := range local.Addrs {
+		info.P2PAddrs = append(info.P2PAddrs, fmt.Sprintf("%s/p2p/%s", addr, local.ID))
+	}
+
+	header := a.store.Header()
+	info.Head.Number = argUint64(header.Number)
+	info.Head.Hash = header.Hash
+
+	return info, nil
+}
+
+// Peers returns the connected peers, with their addresses and supported protocols
+func (a *Admin) Peers() (interface{}, error) {
+	return a.store.GetPeersInfo()
+}
+
+// AddPeer marks the peer with the given multiaddr (/ip4/<ip>/tcp/<port>/p2p/<id>) ready for dialing.
+// The peer is dialed asynchronously, so it is not connected yet once the method returns
+func (a *Admin) AddPeer(rawPeerMultiaddr string) (interface{}, error) {
+	if err := a.store.JoinPeer(rawPeerMultiaddr); err != nil {
+		return nil, err
+	}
+
+	return true, nil
+}
+
+// RemovePeer disconnects from the peer with the given ID or multiaddr.
+// It returns false if the peer wasn't connected
+func (a *Admin) RemovePeer(rawPeer string) (interface{}, error) {
+	return a.store.RemovePeer(rawPeer)
+}
+
+// Datadir returns the data directory of the node
+func (a *Admin) Datadir() (interface{}, error) {
+	return a.dataDir, nil
+}
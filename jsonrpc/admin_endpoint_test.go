@@ -0,0 +1,125 @@
+package jsonrpc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/juanidrobo/polygon-edge/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var errInvalidMultiaddr = errors.New("invalid multiaddr")
+
+type mockAdminStore struct {
+	local  *PeerInfo
+	peers  []*PeerInfo
+	joined []string
+}
+
+func (m *mockAdminStore) Header() *types.Header {
+	return &types.Header{Number: 10, Hash: hash1}
+}
+
+func (m *mockAdminStore) GetLocalPeerInfo() *PeerInfo {
+	return m.local
+}
+
+func (m *mockAdminStore) GetPeersInfo() ([]*PeerInfo, error) {
+	return m.peers, nil
+}
+
+func (m *mockAdminStore) JoinPeer(rawPeerMultiaddr string) error {
+	if rawPeerMultiaddr == "" {
+		return errInvalidMultiaddr
+	}
+
+	m.joined = append(m.joined, rawPeerMultiaddr)
+
+	return nil
+}
+
+func (m *mockAdminStore) RemovePeer(rawPeer string) (bool, error) {
+	for i, p := range m.peers {
+		if p.ID == rawPeer {
+			m.peers = append(m.peers[:i], m.peers[i+1:]...)
+
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func newTestAdminEndpoint() (*Admin, *mockAdminStore) {
+	store := &mockAdminStore{
+		local: &PeerInfo{
+			ID:    "16Uiu2HAm1",
+			Addrs: []string{"/ip4/127.0.0.1/tcp/1478"},
+		},
+		peers: []*PeerInfo{
+			{
+				ID:        "16Uiu2HAm2",
+				Addrs:     []string{"/ip4/127.0.0.1/tcp/2478"},
+				Protocols: []string{"/syncer/0.1"},
+			},
+		},
+	}
+
+	return &Admin{store, 100, "/data"}, store
+}
+
+func TestAdminEndpoint(t *testing.T) {
+	t.Run("nodeInfo returns the identity and chain head of the node", func(t *testing.T) {
+		admin, _ := newTestAdminEndpoint()
+
+		result, err := admin.NodeInfo()
+		assert.NoError(t, err)
+
+		// nolint:forcetypeassert
+		info := result.(*nodeInfo)
+
+		assert.Equal(t, "16Uiu2HAm1", info.ID)
+		assert.Equal(t, []string{"/ip4/127.0.0.1/tcp/1478/p2p/16Uiu2HAm1"}, info.P2PAddrs)
+		assert.Equal(t, []string{"/ip4/127.0.0.1/tcp/1478"}, info.ListenAddrs)
+		assert.Equal(t, uint64(100), info.ChainID)
+		assert.Equal(t, argUint64(10), info.Head.Number)
+		assert.Equal(t, hash1, info.Head.Hash)
+	})
+
+	t.Run("addPeer marks the peer for dialing", func(t *testing.T) {
+		admin, store := newTestAdminEndpoint()
+
+		result, err := admin.AddPeer("/ip4/127.0.0.1/tcp/3478/p2p/16Uiu2HAm3")
+		assert.NoError(t, err)
+		assert.Equal(t, true, result)
+		assert.Equal(t, []string{"/ip4/127.0.0.1/tcp/3478/p2p/16Uiu2HAm3"}, store.joined)
+
+		_, err = admin.AddPeer("")
+		assert.ErrorIs(t, err, errInvalidMultiaddr)
+	})
+
+	t.Run("removePeer disconnects only the connected peers", func(t *testing.T) {
+		admin, store := newTestAdminEndpoint()
+
+		result, err := admin.RemovePeer("16Uiu2HAm2")
+		assert.NoError(t, err)
+		assert.Equal(t, true, result)
+		assert.Empty(t, store.peers)
+
+		result, err = admin.RemovePeer("16Uiu2HAm2")
+		assert.NoError(t, err)
+		assert.Equal(t, false, result)
+	})
+
+	t.Run("peers and datadir", func(t *testing.T) {
+		admin, store := newTestAdminEndpoint()
+
+		peers, err := admin.Peers()
+		assert.NoError(t, err)
+		assert.Equal(t, store.peers, peers)
+
+		dataDir, err := admin.Datadir()
+		assert.NoError(t, err)
+		assert.Equal(t, "/data", dataDir)
+	})
+}
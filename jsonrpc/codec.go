@@ -94,9 +94,11 @@ func (e *ObjectError) Error() string {
 }
 
 const (
-	PendingBlockNumber  = BlockNumber(-3)
-	LatestBlockNumber   = BlockNumber(-2)
-	EarliestBlockNumber = BlockNumber(-1)
+	SafeBlockNumber      = BlockNumber(-5)
+	FinalizedBlockNumber = BlockNumber(-4)
+	PendingBlockNumber   = BlockNumber(-3)
+	LatestBlockNumber    = BlockNumber(-2)
+	EarliestBlockNumber  = BlockNumber(-1)
 )
 
 type BlockNumber int64
@@ -109,7 +111,8 @@ type BlockNumberOrHash struct {
 // UnmarshalJSON will try to extract the filter's data.
 // Here are the possible input formats :
 //
-// 1 - "latest", "pending" or "earliest"	- self-explaining keywords
+// 1 - "latest", "pending", "earliest",
+// "finalized" or "safe"					- self-explaining keywords
 // 2 - "0x2"								- block number #2 (EIP-1898 backward compatible)
 // 3 - {blockNumber:	"0x2"}				- EIP-1898 compliant block number #2
 // 4 - {blockHash:		"0xe0e..."}			- EIP-1898 compliant block hash 0xe0e...
@@ -154,6 +157,10 @@ func stringToBlockNumber(str string) (BlockNumber, error) {
 		return LatestBlockNumber, nil
 	case "earliest":
 		return EarliestBlockNumber, nil
+	case "finalized":
+		return FinalizedBlockNumber, nil
+	case "safe":
+		return SafeBlockNumber, nil
 	}
 
 	n, err := types.ParseUint64orHex(&str)
@@ -201,9 +208,15 @@ func NewRPCErrorResponse(id interface{}, errCode int, err string, jsonrpcver str
 // NewRPCResponse returns Success/Error response object
 func NewRPCResponse(id interface{}, jsonrpcver string, reply []byte, err Error) Response {
 	var response Response
-	switch err.(type) {
+	switch errType := err.(type) {
 	case nil:
 		response = &SuccessResponse{JSONRPC: jsonrpcver, ID: id, Result: reply}
+	case DataError:
+		response = &ErrorResponse{
+			JSONRPC: jsonrpcver,
+			ID:      id,
+			Error:   &ObjectError{errType.ErrorCode(), errType.Error(), errType.ErrorData()},
+		}
 	default:
 		response = NewRPCErrorResponse(id, err.ErrorCode(), err.Error(), jsonrpcver)
 	}
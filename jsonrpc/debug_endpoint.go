@@ -0,0 +1,112 @@
+package jsonrpc
+
+import (
+	"errors"
+
+	"github.com/juanidrobo/polygon-edge/types"
+)
+
+// maxRawBlocksPageSize is the maximum number of blocks returned by a debug_getRawBlocks page
+const maxRawBlocksPageSize uint64 = 100
+
+var (
+	errPendingBlockNotSupported = errors.New("fetching the pending block is not supported")
+	errInvalidBlockNumber       = errors.New("invalid block number")
+)
+
+// debugStore provides the methods needed by the debug endpoint
+type debugStore interface {
+	// Header returns the current header of the chain (genesis if empty)
+	Header() *types.Header
+
+	// GetBlockByNumber returns a block using the provided number
+	GetBlockByNumber(num uint64, full bool) (*types.Block, bool)
+
+	finalityStore
+}
+
+// Debug is the debug jsonrpc endpoint, serving the raw chain data for the indexers
+type Debug struct {
+	store debugStore
+}
+
+// rawBlocksPage is a page of RLP encoded blocks returned by debug_getRawBlocks
+type rawBlocksPage struct {
+	Blocks []argBytes `json:"blocks"`
+
+	// the first block of the next page, nil if the page reaches the chain head
+	Next *argUint64 `json:"next"`
+}
+
+// GetRawBlock returns the RLP encoded block, or nil if it is not found
+func (d *Debug) GetRawBlock(number BlockNumber) (interface{}, error) {
+	num, err := d.blockNumber(number)
+	if err != nil {
+		return nil, err
+	}
+
+	block, ok := d.store.GetBlockByNumber(num, true)
+	if !ok {
+		return nil, nil
+	}
+
+	return argBytes(block.MarshalRLP()), nil
+}
+
+// GetRawBlocks returns a page of RLP encoded canonical blocks, starting from the given one.
+// The page holds up to count blocks (at most maxRawBlocksPageSize), and is cut at the chain head
+func (d *Debug) GetRawBlocks(from BlockNumber, count argUint64) (interface{}, error) {
+	num, err := d.blockNumber(from)
+	if err != nil {
+		return nil, err
+	}
+
+	size := uint64(count)
+	if size == 0 || size > maxRawBlocksPageSize {
+		size = maxRawBlocksPageSize
+	}
+
+	head := d.store.Header().Number
+	page := &rawBlocksPage{
+		Blocks: make([]argBytes, 0, size),
+	}
+
+	for ; num <= head && uint64(len(page.Blocks)) < size; num++ {
+		block, ok := d.store.GetBlockByNumber(num, true)
+		if !ok {
+			break
+		}
+
+		page.Blocks = append(page.Blocks, block.MarshalRLP())
+	}
+
+	if num <= head {
+		page.Next = argUintPtr(num)
+	}
+
+	return page, nil
+}
+
+// blockNumber returns the number of the given block, pending blocks are not supported
+func (d *Debug) blockNumber(number BlockNumber) (uint64, error) {
+	switch number {
+	case LatestBlockNumber:
+		return d.store.Header().Number, nil
+
+	case EarliestBlockNumber:
+		return 0, nil
+
+	case FinalizedBlockNumber, SafeBlockNumber:
+		return getFinalityNumber(d.store, number), nil
+
+	case PendingBlockNumber:
+		return 0, errPendingBlockNotSupported
+
+	default:
+		if number < 0 {
+			return 0, errInvalidBlockNumber
+		}
+
+		return uint64(number), nil
+	}
+}
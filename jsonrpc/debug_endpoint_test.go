@@ -0,0 +1,97 @@
+package jsonrpc
+
+import (
+	"testing"
+
+	"github.com/juanidrobo/polygon-edge/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestDebugEndpoint(blocks uint64) (*Debug, *mockBlockStore) {
+	store := newMockBlockStore()
+
+	for i := uint64(0); i < blocks; i++ {
+		block := newTestBlock(i, types.BytesToHash([]byte{byte(i + 1)}))
+		block.Header.GasLimit = 5000000 + i
+
+		store.add(block)
+	}
+
+	return &Debug{store}, store
+}
+
+func TestDebug_GetRawBlock(t *testing.T) {
+	debug, store := newTestDebugEndpoint(3)
+
+	res, err := debug.GetRawBlock(BlockNumber(1))
+	assert.NoError(t, err)
+	assert.Equal(t, argBytes(store.blocks[1].MarshalRLP()), res)
+
+	res, err = debug.GetRawBlock(LatestBlockNumber)
+	assert.NoError(t, err)
+	assert.Equal(t, argBytes(store.blocks[2].MarshalRLP()), res)
+
+	res, err = debug.GetRawBlock(BlockNumber(10))
+	assert.NoError(t, err)
+	assert.Nil(t, res)
+
+	_, err = debug.GetRawBlock(PendingBlockNumber)
+	assert.ErrorIs(t, err, errPendingBlockNotSupported)
+}
+
+func TestDebug_GetRawBlocks(t *testing.T) {
+	debug, store := newTestDebugEndpoint(maxRawBlocksPageSize + 10)
+
+	t.Run("pages through the blocks up to the head", func(t *testing.T) {
+		decoded := []uint64{}
+		from := BlockNumber(0)
+
+		for {
+			res, err := debug.GetRawBlocks(from, 40)
+			assert.NoError(t, err)
+
+			// nolint:forcetypeassert
+			page := res.(*rawBlocksPage)
+			assert.LessOrEqual(t, len(page.Blocks), 40)
+
+			for _, raw := range page.Blocks {
+				block := &types.Block{}
+				assert.NoError(t, block.UnmarshalRLP(raw))
+
+				decoded = append(decoded, block.Number())
+			}
+
+			if page.Next == nil {
+				break
+			}
+
+			from = BlockNumber(*page.Next)
+		}
+
+		assert.Len(t, decoded, len(store.blocks))
+
+		for i, num := range decoded {
+			assert.Equal(t, uint64(i), num)
+		}
+	})
+
+	t.Run("caps the page size", func(t *testing.T) {
+		res, err := debug.GetRawBlocks(BlockNumber(0), 0)
+		assert.NoError(t, err)
+
+		// nolint:forcetypeassert
+		page := res.(*rawBlocksPage)
+		assert.Len(t, page.Blocks, int(maxRawBlocksPageSize))
+		assert.Equal(t, argUintPtr(maxRawBlocksPageSize), page.Next)
+	})
+
+	t.Run("returns an empty page past the head", func(t *testing.T) {
+		res, err := debug.GetRawBlocks(BlockNumber(maxRawBlocksPageSize+20), 10)
+		assert.NoError(t, err)
+
+		// nolint:forcetypeassert
+		page := res.(*rawBlocksPage)
+		assert.Empty(t, page.Blocks)
+		assert.Nil(t, page.Next)
+	})
+}
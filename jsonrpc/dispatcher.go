@@ -2,6 +2,7 @@ package jsonrpc
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,19 +14,32 @@ import (
 	"github.com/hashicorp/go-hclog"
 )
 
+var errUnknownNamespace = errors.New("unknown namespace")
+
+// privilegedNamespaces are never served over HTTP / WS, unless explicitly allowed
+var privilegedNamespaces = map[string]bool{
+	"admin": true,
+	"debug": true,
+}
+
 type serviceData struct {
 	sv      reflect.Value
 	funcMap map[string]*funcData
 }
 
 type funcData struct {
-	inNum int
-	reqt  []reflect.Type
-	fv    reflect.Value
-	isDyn bool
+	inNum  int
+	reqt   []reflect.Type
+	fv     reflect.Value
+	isDyn  bool
+	hasCtx bool // the first param is the context of the request
 }
 
 func (f *funcData) numParams() int {
+	if f.hasCtx {
+		return f.inNum - 2
+	}
+
 	return f.inNum - 1
 }
 
@@ -34,6 +48,8 @@ type endpoints struct {
 	Web3   *Web3
 	Net    *Net
 	TxPool *TxPool
+	Debug  *Debug
+	Admin  *Admin
 }
 
 // Dispatcher handles all json rpc requests by delegating
@@ -43,13 +59,31 @@ type Dispatcher struct {
 	serviceMap    map[string]*serviceData
 	filterManager *FilterManager
 	endpoints     endpoints
-	chainID       uint64
+	params        *dispatcherParams
+	rateLimiter   *rateLimiter
+}
+
+// dispatcherParams are the configuration params of the dispatcher endpoints
+type dispatcherParams struct {
+	chainID uint64
+
+	// gas price oracle params
+	gasPriceBlocks     uint64
+	gasPricePercentile uint64
+
+	// the limits enforced on the requests
+	limits Limits
+
+	// admin namespace params, it is served only if enabled
+	enableAdmin bool
+	dataDir     string
 }
 
-func newDispatcher(logger hclog.Logger, store JSONRPCStore, chainID uint64) *Dispatcher {
+func newDispatcher(logger hclog.Logger, store JSONRPCStore, params *dispatcherParams) *Dispatcher {
 	d := &Dispatcher{
-		logger:  logger.Named("dispatcher"),
-		chainID: chainID,
+		logger:      logger.Named("dispatcher"),
+		params:      params,
+		rateLimiter: newRateLimiter(params.limits.IPRateLimit, params.limits.MethodRateLimits),
 	}
 
 	if store != nil {
@@ -63,15 +97,78 @@ func newDispatcher(logger hclog.Logger, store JSONRPCStore, chainID uint64) *Dis
 }
 
 func (d *Dispatcher) registerEndpoints(store JSONRPCStore) {
-	d.endpoints.Eth = &Eth{d.logger, store, d.chainID, d.filterManager}
-	d.endpoints.Net = &Net{store, d.chainID}
+	d.endpoints.Eth = &Eth{
+		d.logger,
+		store,
+		d.params.chainID,
+		d.filterManager,
+		newGasPriceOracle(store, d.params.gasPriceBlocks, d.params.gasPricePercentile),
+		d.params.limits.BlockRange,
+	}
+	d.endpoints.Net = &Net{store, d.params.chainID}
 	d.endpoints.Web3 = &Web3{}
 	d.endpoints.TxPool = &TxPool{store}
+	d.endpoints.Debug = &Debug{store}
 
 	d.registerService("eth", d.endpoints.Eth)
 	d.registerService("net", d.endpoints.Net)
 	d.registerService("web3", d.endpoints.Web3)
 	d.registerService("txpool", d.endpoints.TxPool)
+	d.registerService("debug", d.endpoints.Debug)
+
+	if d.params.enableAdmin {
+		d.endpoints.Admin = &Admin{store, d.params.chainID, d.params.dataDir}
+		d.registerService("admin", d.endpoints.Admin)
+	}
+}
+
+// namespaceSet returns the set of the given namespaces.
+// If empty, the set includes all the registered namespaces except the privileged ones
+func (d *Dispatcher) namespaceSet(namespaces []string) (map[string]bool, error) {
+	set := make(map[string]bool, len(namespaces))
+
+	if len(namespaces) == 0 {
+		for namespace := range d.serviceMap {
+			if !privilegedNamespaces[namespace] {
+				set[namespace] = true
+			}
+		}
+
+		return set, nil
+	}
+
+	for _, namespace := range namespaces {
+		if _, ok := d.serviceMap[namespace]; !ok {
+			return nil, fmt.Errorf("%w: %s", errUnknownNamespace, namespace)
+		}
+
+		set[namespace] = true
+	}
+
+	return set, nil
+}
+
+// namespacesKey is the context key of the namespaces available to the request
+type namespacesKey struct{}
+
+// withNamespaces returns a copy of the context restricting the request to the namespaces.
+// All the namespaces are available if the set is nil
+func withNamespaces(ctx context.Context, namespaces map[string]bool) context.Context {
+	if namespaces == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, namespacesKey{}, namespaces)
+}
+
+// isMethodAvailable returns whether the namespace of the method is available to the request
+func isMethodAvailable(ctx context.Context, method string) bool {
+	namespaces, ok := ctx.Value(namespacesKey{}).(map[string]bool)
+	if !ok {
+		return true
+	}
+
+	return namespaces[strings.SplitN(method, "_", 2)[0]]
 }
 
 func (d *Dispatcher) getFnHandler(req Request) (*serviceData, *funcData, Error) {
@@ -142,6 +239,16 @@ func (d *Dispatcher) handleSubscribe(req Request, conn wsConn) (string, Error) {
 			return "", NewInternalError(err.Error())
 		}
 		filterID = d.filterManager.NewLogFilter(logFilter, conn)
+	} else if subscribeMethod == "newPendingTransactions" {
+		fullTx := false
+		if len(params) > 1 {
+			if fullTx, ok = params[1].(bool); !ok {
+				return "", NewInvalidParamsError("Invalid params")
+			}
+		}
+		filterID = d.filterManager.NewPendingTxFilter(fullTx, conn)
+	} else if subscribeMethod == "syncing" {
+		filterID = d.filterManager.NewSyncingFilter(conn)
 	} else {
 		return "", NewSubscriptionNotFoundError(subscribeMethod)
 	}
@@ -167,7 +274,7 @@ func (d *Dispatcher) handleUnsubscribe(req Request) (bool, Error) {
 	return d.filterManager.Uninstall(filterID), nil
 }
 
-func (d *Dispatcher) HandleWs(reqBody []byte, conn wsConn) ([]byte, error) {
+func (d *Dispatcher) HandleWs(ctx context.Context, reqBody []byte, conn wsConn) ([]byte, error) {
 	var req Request
 	if err := json.Unmarshal(reqBody, &req); err != nil {
 		return NewRPCResponse(req.ID, "2.0", nil, NewInvalidRequestError("Invalid json request")).Bytes()
@@ -176,10 +283,17 @@ func (d *Dispatcher) HandleWs(reqBody []byte, conn wsConn) ([]byte, error) {
 	// if the request method is eth_subscribe we need to create a
 	// new filter with ws connection
 	if req.Method == "eth_subscribe" {
+		if !isMethodAvailable(ctx, req.Method) {
+			return NewRPCResponse(req.ID, "2.0", nil, NewMethodNotFoundError(req.Method)).Bytes()
+		}
+
+		if err := d.rateLimiter.allow(clientIPFromContext(ctx), req.Method); err != nil {
+			return NewRPCResponse(req.ID, "2.0", nil, err).Bytes()
+		}
+
 		filterID, err := d.handleSubscribe(req, conn)
 		if err != nil {
-			//nolint
-			NewRPCResponse(req.ID, "2.0", nil, err).Bytes()
+			return NewRPCResponse(req.ID, "2.0", nil, err).Bytes()
 		}
 
 		resp, err := formatFilterResponse(req.ID, filterID)
@@ -192,6 +306,14 @@ func (d *Dispatcher) HandleWs(reqBody []byte, conn wsConn) ([]byte, error) {
 	}
 
 	if req.Method == "eth_unsubscribe" {
+		if !isMethodAvailable(ctx, req.Method) {
+			return NewRPCResponse(req.ID, "2.0", nil, NewMethodNotFoundError(req.Method)).Bytes()
+		}
+
+		if err := d.rateLimiter.allow(clientIPFromContext(ctx), req.Method); err != nil {
+			return NewRPCResponse(req.ID, "2.0", nil, err).Bytes()
+		}
+
 		ok, err := d.handleUnsubscribe(req)
 		if err != nil {
 			return nil, err
@@ -210,7 +332,7 @@ func (d *Dispatcher) HandleWs(reqBody []byte, conn wsConn) ([]byte, error) {
 		return []byte(resp), nil
 	}
 	// its a normal query that we handle with the dispatcher
-	resp, err := d.handleReq(req)
+	resp, err := d.handleReq(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -218,7 +340,7 @@ func (d *Dispatcher) HandleWs(reqBody []byte, conn wsConn) ([]byte, error) {
 	return NewRPCResponse(req.ID, "2.0", resp, err).Bytes()
 }
 
-func (d *Dispatcher) Handle(reqBody []byte) ([]byte, error) {
+func (d *Dispatcher) Handle(ctx context.Context, reqBody []byte) ([]byte, error) {
 	x := bytes.TrimLeft(reqBody, " \t\r\n")
 	if len(x) == 0 {
 		return NewRPCResponse(nil, "2.0", nil, NewInvalidRequestError("Invalid json request")).Bytes()
@@ -234,7 +356,7 @@ func (d *Dispatcher) Handle(reqBody []byte) ([]byte, error) {
 			return NewRPCResponse(req.ID, "2.0", nil, NewInvalidRequestError("Invalid json request")).Bytes()
 		}
 
-		resp, err := d.handleReq(req)
+		resp, err := d.handleReq(ctx, req)
 
 		return NewRPCResponse(req.ID, "2.0", resp, err).Bytes()
 	}
@@ -245,10 +367,19 @@ func (d *Dispatcher) Handle(reqBody []byte) ([]byte, error) {
 		return NewRPCResponse(nil, "2.0", nil, NewInvalidRequestError("Invalid json request")).Bytes()
 	}
 
+	if limit := d.params.limits.BatchLength; limit > 0 && uint64(len(requests)) > limit {
+		return NewRPCResponse(
+			nil,
+			"2.0",
+			nil,
+			NewLimitExceededError(fmt.Sprintf("batch too large, the limit is %d requests", limit)),
+		).Bytes()
+	}
+
 	responses := make([]Response, 0)
 
 	for _, req := range requests {
-		var response, err = d.handleReq(req)
+		var response, err = d.handleReq(ctx, req)
 		if err != nil {
 			errorResponse := NewRPCResponse(req.ID, "2.0", nil, err)
 			responses = append(responses, errorResponse)
@@ -268,23 +399,48 @@ func (d *Dispatcher) Handle(reqBody []byte) ([]byte, error) {
 	return respBytes, nil
 }
 
-func (d *Dispatcher) handleReq(req Request) ([]byte, Error) {
+func (d *Dispatcher) handleReq(ctx context.Context, req Request) ([]byte, Error) {
 	d.logger.Debug("request", "method", req.Method, "id", req.ID)
 
+	if err := d.rateLimiter.allow(clientIPFromContext(ctx), req.Method); err != nil {
+		d.logger.Debug("request throttled", "method", req.Method, "err", err)
+
+		return nil, err
+	}
+
+	// the methods of the namespaces not served by the listener don't exist for the client
+	if !isMethodAvailable(ctx, req.Method) {
+		return nil, NewMethodNotFoundError(req.Method)
+	}
+
 	service, fd, ferr := d.getFnHandler(req)
 	if ferr != nil {
 		return nil, ferr
 	}
 
+	// the methods with a timeout are canceled through the context
+	if timeout, ok := d.params.limits.MethodTimeouts[req.Method]; ok && timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
 	inArgs := make([]reflect.Value, fd.inNum)
 	inArgs[0] = service.sv
 
+	offset := 1
+	if fd.hasCtx {
+		inArgs[1] = reflect.ValueOf(ctx)
+		offset = 2
+	}
+
 	inputs := make([]interface{}, fd.numParams())
 
-	for i := 0; i < fd.inNum-1; i++ {
-		val := reflect.New(fd.reqt[i+1])
+	for i := 0; i < fd.numParams(); i++ {
+		val := reflect.New(fd.reqt[i+offset])
 		inputs[i] = val.Interface()
-		inArgs[i+1] = val.Elem()
+		inArgs[i+offset] = val.Elem()
 	}
 
 	if fd.numParams() > 0 {
@@ -295,6 +451,25 @@ func (d *Dispatcher) handleReq(req Request) ([]byte, Error) {
 
 	output := fd.fv.Call(inArgs)
 	if err := getError(output[1]); err != nil {
+		var revertErr *revertError
+		if errors.As(err, &revertErr) {
+			d.logger.Debug("execution reverted", "method", req.Method, "reason", revertErr.reason)
+
+			return nil, revertErr
+		}
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			d.logger.Debug("request timed out", "method", req.Method, "err", err)
+
+			return nil, NewLimitExceededError("request timed out")
+		}
+
+		// the errors carrying their own code are returned as they are
+		var rpcErr Error
+		if errors.As(err, &rpcErr) {
+			return nil, rpcErr
+		}
+
 		d.logInternalError(req.Method, err)
 
 		return nil, NewInvalidRequestError(err.Error())
@@ -355,9 +530,13 @@ func (d *Dispatcher) registerService(serviceName string, service interface{}) {
 		if fd.inNum, fd.reqt, err = validateFunc(funcName, fd.fv, true); err != nil {
 			panic(fmt.Sprintf("jsonrpc: %s", err))
 		}
+		// check if the first param is the context of the request
+		if fd.inNum > 1 && fd.reqt[1] == contextt {
+			fd.hasCtx = true
+		}
 		// check if last item is a pointer
 		if fd.numParams() != 0 {
-			last := fd.reqt[fd.numParams()]
+			last := fd.reqt[fd.inNum-1]
 			if last.Kind() == reflect.Ptr {
 				fd.isDyn = true
 			}
@@ -413,7 +592,10 @@ func validateFunc(funcName string, fv reflect.Value, isMethod bool) (inNum int,
 	return
 }
 
-var errt = reflect.TypeOf((*error)(nil)).Elem()
+var (
+	errt     = reflect.TypeOf((*error)(nil)).Elem()
+	contextt = reflect.TypeOf((*context.Context)(nil)).Elem()
+)
 
 func isErrorType(t reflect.Type) bool {
 	return t.Implements(errt)
@@ -1,12 +1,15 @@
 package jsonrpc
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"math/big"
 	"reflect"
 	"testing"
 	"time"
 
+	"github.com/juanidrobo/polygon-edge/state/runtime"
 	"github.com/juanidrobo/polygon-edge/types"
 	"github.com/hashicorp/go-hclog"
 	"github.com/stretchr/testify/assert"
@@ -58,7 +61,7 @@ func expectBatchJSONResult(data []byte, v interface{}) error {
 func TestDispatcher_HandleWebsocketConnection_EthSubscribe(t *testing.T) {
 	t.Run("clients should be able to receive \"newHeads\" event thru eth_subscribe", func(t *testing.T) {
 		store := newMockStore()
-		dispatcher := newDispatcher(hclog.NewNullLogger(), store, 0)
+		dispatcher := newDispatcher(hclog.NewNullLogger(), store, &dispatcherParams{})
 
 		mockConnection := &mockWsConn{
 			msgCh: make(chan []byte, 1),
@@ -68,7 +71,7 @@ func TestDispatcher_HandleWebsocketConnection_EthSubscribe(t *testing.T) {
 		"method": "eth_subscribe",
 		"params": ["newHeads"]
 	}`)
-		if _, err := dispatcher.HandleWs(req, mockConnection); err != nil {
+		if _, err := dispatcher.HandleWs(context.Background(), req, mockConnection); err != nil {
 			t.Fatal(err)
 		}
 
@@ -88,11 +91,58 @@ func TestDispatcher_HandleWebsocketConnection_EthSubscribe(t *testing.T) {
 			t.Fatal("\"newHeads\" event not received in 2 seconds")
 		}
 	})
+
+	t.Run("clients should be able to receive \"newPendingTransactions\" event thru eth_subscribe", func(t *testing.T) {
+		store := newMockStore()
+		dispatcher := newDispatcher(hclog.NewNullLogger(), store, &dispatcherParams{})
+
+		mockConnection := &mockWsConn{
+			msgCh: make(chan []byte, 1),
+		}
+
+		req := []byte(`{
+		"method": "eth_subscribe",
+		"params": ["newPendingTransactions", true]
+	}`)
+		if _, err := dispatcher.HandleWs(context.Background(), req, mockConnection); err != nil {
+			t.Fatal(err)
+		}
+
+		store.addPendingTx(&types.Transaction{
+			Hash:     hash1,
+			GasPrice: big.NewInt(0),
+			Value:    big.NewInt(0),
+			V:        big.NewInt(0),
+			R:        big.NewInt(0),
+			S:        big.NewInt(0),
+		})
+
+		select {
+		case <-mockConnection.msgCh:
+		case <-time.After(2 * time.Second):
+			t.Fatal("\"newPendingTransactions\" event not received in 2 seconds")
+		}
+	})
+
+	t.Run("\"newPendingTransactions\" full transactions flag should be a boolean", func(t *testing.T) {
+		dispatcher := newDispatcher(hclog.NewNullLogger(), newMockStore(), &dispatcherParams{})
+
+		resp, err := dispatcher.HandleWs(context.Background(), []byte(`{
+		"method": "eth_subscribe",
+		"params": ["newPendingTransactions", "full"]
+	}`), &mockWsConn{})
+		assert.NoError(t, err)
+
+		var res ErrorResponse
+
+		assert.NoError(t, json.Unmarshal(resp, &res))
+		assert.Equal(t, -32602, res.Error.Code)
+	})
 }
 
 func TestDispatcher_WebsocketConnection_RequestFormats(t *testing.T) {
 	store := newMockStore()
-	dispatcher := newDispatcher(hclog.NewNullLogger(), store, 0)
+	dispatcher := newDispatcher(hclog.NewNullLogger(), store, &dispatcherParams{})
 
 	mockConnection := &mockWsConn{
 		msgCh: make(chan []byte, 1),
@@ -143,7 +193,7 @@ func TestDispatcher_WebsocketConnection_RequestFormats(t *testing.T) {
 		},
 	}
 	for _, c := range cases {
-		data, err := dispatcher.HandleWs(c.msg, mockConnection)
+		data, err := dispatcher.HandleWs(context.Background(), c.msg, mockConnection)
 		resp := new(SuccessResponse)
 		merr := json.Unmarshal(data, resp)
 
@@ -193,14 +243,27 @@ func (m *mockService) Filter(f LogFilter) (interface{}, error) {
 	return nil, nil
 }
 
+func (m *mockService) Revert(data argBytes) (interface{}, error) {
+	return nil, fmt.Errorf("unable to apply transaction: %w", constructErrorFromRevert(&runtime.ExecutionResult{
+		ReturnValue: data,
+		Err:         runtime.ErrExecutionReverted,
+	}))
+}
+
+func (m *mockService) Wait(ctx context.Context) (interface{}, error) {
+	<-ctx.Done()
+
+	return nil, ctx.Err()
+}
+
 func TestDispatcherFuncDecode(t *testing.T) {
 	srv := &mockService{msgCh: make(chan interface{}, 10)}
 
-	dispatcher := newDispatcher(hclog.NewNullLogger(), newMockStore(), 0)
+	dispatcher := newDispatcher(hclog.NewNullLogger(), newMockStore(), &dispatcherParams{})
 	dispatcher.registerService("mock", srv)
 
 	handleReq := func(typ string, msg string) interface{} {
-		_, err := dispatcher.handleReq(Request{
+		_, err := dispatcher.handleReq(context.Background(), Request{
 			Method: "mock_" + typ,
 			Params: []byte(msg),
 		})
@@ -261,11 +324,11 @@ func TestDispatcherFuncDecode(t *testing.T) {
 }
 
 func TestDispatcherBatchRequest(t *testing.T) {
-	dispatcher := newDispatcher(hclog.NewNullLogger(), newMockStore(), 0)
+	dispatcher := newDispatcher(hclog.NewNullLogger(), newMockStore(), &dispatcherParams{})
 
 	// test with leading whitespace ("  \t\n\n\r")
 	leftBytes := []byte{0x20, 0x20, 0x09, 0x0A, 0x0A, 0x0D}
-	resp, err := dispatcher.Handle(append(leftBytes, []byte(`[
+	resp, err := dispatcher.Handle(context.Background(), append(leftBytes, []byte(`[
     {"id":1,"jsonrpc":"2.0","method":"eth_getBalance","params":["0x1", true]},
     {"id":2,"jsonrpc":"2.0","method":"eth_getBlockByNumber","params":["0x2", true]},
     {"id":3,"jsonrpc":"2.0","method":"eth_getBlockByNumber","params":["0x3", true]},
@@ -283,3 +346,204 @@ func TestDispatcherBatchRequest(t *testing.T) {
 	assert.Equal(t, res[0].Error, jsonerr)
 	assert.Nil(t, res[3].Error)
 }
+
+func TestDispatcherRevertError(t *testing.T) {
+	dispatcher := newDispatcher(hclog.NewNullLogger(), newMockStore(), &dispatcherParams{})
+	dispatcher.registerService("mock", &mockService{})
+
+	// Error(string) with "revert reason" as the reason
+	revertData := "0x08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"000000000000000000000000000000000000000000000000000000000000000d" +
+		"72657665727420726561736f6e00000000000000000000000000000000000000"
+
+	t.Run("revert data is returned with code 3", func(t *testing.T) {
+		resp, err := dispatcher.Handle(
+			context.Background(),
+			[]byte(`{"id":1,"jsonrpc":"2.0","method":"mock_revert","params":["`+revertData+`"]}`),
+		)
+		assert.NoError(t, err)
+
+		var res ErrorResponse
+
+		assert.NoError(t, json.Unmarshal(resp, &res))
+		assert.Equal(t, &ObjectError{
+			Code:    3,
+			Message: "execution was reverted: revert reason",
+			Data:    revertData,
+		}, res.Error)
+	})
+
+	t.Run("revert without data is an invalid request", func(t *testing.T) {
+		resp, err := dispatcher.Handle(
+			context.Background(),
+			[]byte(`{"id":1,"jsonrpc":"2.0","method":"mock_revert","params":["0x"]}`),
+		)
+		assert.NoError(t, err)
+
+		var res ErrorResponse
+
+		assert.NoError(t, json.Unmarshal(resp, &res))
+		assert.Equal(t, -32600, res.Error.Code)
+		assert.Nil(t, res.Error.Data)
+	})
+}
+
+func TestDispatcherLimits(t *testing.T) {
+	expectLimitError := func(t *testing.T, resp []byte, message string) {
+		t.Helper()
+
+		var res ErrorResponse
+
+		assert.NoError(t, json.Unmarshal(resp, &res))
+		assert.Equal(t, &ObjectError{Code: -32005, Message: message}, res.Error)
+	}
+
+	t.Run("batch length", func(t *testing.T) {
+		dispatcher := newDispatcher(hclog.NewNullLogger(), newMockStore(), &dispatcherParams{
+			limits: Limits{BatchLength: 1},
+		})
+
+		resp, err := dispatcher.Handle(context.Background(), []byte(`[
+	{"id":1,"jsonrpc":"2.0","method":"web3_clientVersion","params":[]},
+	{"id":2,"jsonrpc":"2.0","method":"web3_clientVersion","params":[]}
+]`))
+		assert.NoError(t, err)
+
+		expectLimitError(t, resp, "batch too large, the limit is 1 requests")
+	})
+
+	t.Run("method timeout", func(t *testing.T) {
+		dispatcher := newDispatcher(hclog.NewNullLogger(), newMockStore(), &dispatcherParams{
+			limits: Limits{MethodTimeouts: map[string]time.Duration{"mock_wait": 10 * time.Millisecond}},
+		})
+		dispatcher.registerService("mock", &mockService{})
+
+		resp, err := dispatcher.Handle(
+			context.Background(),
+			[]byte(`{"id":1,"jsonrpc":"2.0","method":"mock_wait","params":[]}`),
+		)
+		assert.NoError(t, err)
+
+		expectLimitError(t, resp, "request timed out")
+	})
+
+	t.Run("ip rate limit", func(t *testing.T) {
+		dispatcher := newDispatcher(hclog.NewNullLogger(), newMockStore(), &dispatcherParams{
+			limits: Limits{IPRateLimit: 1},
+		})
+
+		req := []byte(`{"id":1,"jsonrpc":"2.0","method":"web3_clientVersion","params":[]}`)
+
+		handle := func(ip string) []byte {
+			t.Helper()
+
+			resp, err := dispatcher.Handle(withClientIP(context.Background(), ip), req)
+			assert.NoError(t, err)
+
+			return resp
+		}
+
+		var version string
+
+		assert.NoError(t, expectJSONResult(handle("10.0.0.1"), &version))
+		expectLimitError(t, handle("10.0.0.1"), "rate limit exceeded for 10.0.0.1")
+
+		// the other clients have their own buckets
+		assert.NoError(t, expectJSONResult(handle("10.0.0.2"), &version))
+
+		// local clients are not limited
+		assert.NoError(t, expectJSONResult(handle(""), &version))
+		assert.NoError(t, expectJSONResult(handle(""), &version))
+	})
+
+	t.Run("method rate limit", func(t *testing.T) {
+		dispatcher := newDispatcher(hclog.NewNullLogger(), newMockStore(), &dispatcherParams{
+			limits: Limits{MethodRateLimits: map[string]float64{"web3_clientVersion": 1}},
+		})
+
+		resp, err := dispatcher.Handle(withClientIP(context.Background(), "10.0.0.1"), []byte(`[
+	{"id":1,"jsonrpc":"2.0","method":"web3_clientVersion","params":[]},
+	{"id":2,"jsonrpc":"2.0","method":"web3_sha3","params":["0x00"]},
+	{"id":3,"jsonrpc":"2.0","method":"web3_clientVersion","params":[]}
+]`))
+		assert.NoError(t, err)
+
+		var res []SuccessResponse
+
+		assert.NoError(t, expectBatchJSONResult(resp, &res))
+		assert.Len(t, res, 3)
+
+		assert.Nil(t, res[0].Error)
+		assert.Nil(t, res[1].Error)
+		assert.Equal(t, &ObjectError{Code: -32005, Message: "rate limit exceeded for web3_clientVersion"}, res[2].Error)
+	})
+}
+
+func TestDispatcherNamespaces(t *testing.T) {
+	dispatcher := newDispatcher(hclog.NewNullLogger(), newMockStore(), &dispatcherParams{chainID: 100})
+
+	namespaces, err := dispatcher.namespaceSet([]string{"eth"})
+	assert.NoError(t, err)
+
+	ctx := withNamespaces(context.Background(), namespaces)
+
+	t.Run("methods of the allowed namespaces are served", func(t *testing.T) {
+		resp, err := dispatcher.Handle(ctx, []byte(`{"id":1,"jsonrpc":"2.0","method":"eth_chainId","params":[]}`))
+		assert.NoError(t, err)
+
+		var chainID string
+
+		assert.NoError(t, expectJSONResult(resp, &chainID))
+		assert.Equal(t, "0x64", chainID)
+	})
+
+	t.Run("methods of the other namespaces don't exist", func(t *testing.T) {
+		resp, err := dispatcher.Handle(ctx, []byte(`{"id":1,"jsonrpc":"2.0","method":"txpool_status","params":[]}`))
+		assert.NoError(t, err)
+
+		var res ErrorResponse
+
+		assert.NoError(t, json.Unmarshal(resp, &res))
+		assert.Equal(t, -32601, res.Error.Code)
+	})
+
+	t.Run("subscriptions belong to the eth namespace", func(t *testing.T) {
+		txPoolOnly, err := dispatcher.namespaceSet([]string{"txpool"})
+		assert.NoError(t, err)
+
+		resp, err := dispatcher.HandleWs(
+			withNamespaces(context.Background(), txPoolOnly),
+			[]byte(`{"id":1,"jsonrpc":"2.0","method":"eth_subscribe","params":["newHeads"]}`),
+			&mockWsConn{msgCh: make(chan []byte, 1)},
+		)
+		assert.NoError(t, err)
+
+		var res ErrorResponse
+
+		assert.NoError(t, json.Unmarshal(resp, &res))
+		assert.Equal(t, -32601, res.Error.Code)
+	})
+
+	t.Run("privileged namespaces must be explicitly allowed", func(t *testing.T) {
+		dispatcher := newDispatcher(hclog.NewNullLogger(), newMockStore(), &dispatcherParams{enableAdmin: true})
+
+		all, err := dispatcher.namespaceSet(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]bool{"eth": true, "net": true, "web3": true, "txpool": true}, all)
+
+		allowed, err := dispatcher.namespaceSet([]string{"eth", "admin"})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]bool{"eth": true, "admin": true}, allowed)
+	})
+
+	t.Run("the admin namespace is disabled by default", func(t *testing.T) {
+		_, err := dispatcher.namespaceSet([]string{"admin"})
+		assert.ErrorIs(t, err, errUnknownNamespace)
+	})
+
+	t.Run("unknown namespaces are rejected", func(t *testing.T) {
+		_, err := dispatcher.namespaceSet([]string{"eth", "foo"})
+		assert.ErrorIs(t, err, errUnknownNamespace)
+	})
+}
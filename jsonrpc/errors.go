@@ -3,6 +3,8 @@ package jsonrpc
 import (
 	"errors"
 	"fmt"
+
+	"github.com/juanidrobo/polygon-edge/helper/hex"
 	"github.com/juanidrobo/polygon-edge/state/runtime"
 	"github.com/umbracle/go-web3/abi"
 )
@@ -15,6 +17,13 @@ type Error interface {
 	Error() string
 	ErrorCode() int
 }
+
+// DataError is an Error which carries additional data, returned in the data field of the error object
+type DataError interface {
+	Error
+	ErrorData() interface{}
+}
+
 type invalidParamsError struct {
 	err string
 }
@@ -75,6 +84,20 @@ func (e *methodNotFoundError) ErrorCode() int {
 	return -32601
 }
 
+// limitExceededError is returned when a request exceeds the limits of the server,
+// like the rate limits, the batch length or the execution timeout
+type limitExceededError struct {
+	err string
+}
+
+func (e *limitExceededError) Error() string {
+	return e.err
+}
+
+func (e *limitExceededError) ErrorCode() int {
+	return -32005
+}
+
 func NewMethodNotFoundError(method string) *methodNotFoundError {
 	return &methodNotFoundError{fmt.Sprintf("the method %s does not exist/is not available", method)}
 }
@@ -89,15 +112,53 @@ func NewInternalError(msg string) *internalError {
 	return &internalError{msg}
 }
 
+func NewLimitExceededError(msg string) *limitExceededError {
+	return &limitExceededError{msg}
+}
+
 func NewSubscriptionNotFoundError(method string) *subscriptionNotFoundError {
 	return &subscriptionNotFoundError{fmt.Sprintf("subscribe method %s not found", method)}
 }
 
+// revertError is returned when the EVM execution is reverted.
+// It carries the ABI encoded revert data, so clients can decode Error(string) and custom errors
+type revertError struct {
+	err    error
+	reason string
+	data   []byte
+}
+
+func (e *revertError) Error() string {
+	if e.reason == "" {
+		return e.err.Error()
+	}
+
+	return fmt.Sprintf("%s: %s", e.err.Error(), e.reason)
+}
+
+func (e *revertError) ErrorCode() int {
+	return 3
+}
+
+func (e *revertError) ErrorData() interface{} {
+	return hex.EncodeToHex(e.data)
+}
+
+func (e *revertError) Unwrap() error {
+	return e.err
+}
+
 func constructErrorFromRevert(result *runtime.ExecutionResult) error {
-	revertErrMsg, unpackErr := abi.UnpackRevertError(result.ReturnValue)
-	if unpackErr != nil {
+	if len(result.ReturnValue) == 0 {
 		return result.Err
 	}
 
-	return fmt.Errorf("%w: %s", result.Err, revertErrMsg)
+	// the reason is only used for logging, custom errors can't be decoded without the ABI
+	revertErrMsg, _ := abi.UnpackRevertError(result.ReturnValue)
+
+	return &revertError{
+		err:    result.Err,
+		reason: revertErrMsg,
+		data:   result.ReturnValue,
+	}
 }
@@ -1,6 +1,8 @@
 package jsonrpc
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
@@ -14,7 +16,7 @@ import (
 )
 
 func TestEth_Block_GetBlockByNumber(t *testing.T) {
-	store := &mockBlockStore{}
+	store := &mockBlockStore{finalized: 5, safe: 7}
 	for i := 0; i < 10; i++ {
 		store.add(newTestBlock(uint64(i), hash1))
 	}
@@ -29,6 +31,8 @@ func TestEth_Block_GetBlockByNumber(t *testing.T) {
 	}{
 		{"should be able to get the latest block number", LatestBlockNumber, true, false},
 		{"should be able to get the earliest block number", EarliestBlockNumber, true, false},
+		{"should be able to get the finalized block number", FinalizedBlockNumber, true, false},
+		{"should be able to get the safe block number", SafeBlockNumber, true, false},
 		{"should not be able to get block with negative number", BlockNumber(-50), false, true},
 		{"should be able to get block with number 0", BlockNumber(0), true, false},
 		{"should be able to get block with number 2", BlockNumber(2), true, false},
@@ -51,6 +55,32 @@ func TestEth_Block_GetBlockByNumber(t *testing.T) {
 	}
 }
 
+func TestEth_Block_FinalityTags(t *testing.T) {
+	store := &mockBlockStore{finalized: 5, safe: 7}
+	for i := 0; i < 10; i++ {
+		store.add(newTestBlock(uint64(i), types.BytesToHash([]byte{byte(i + 1)})))
+	}
+
+	eth := newTestEthEndpoint(store)
+
+	res, err := eth.GetBlockByNumber(FinalizedBlockNumber, false)
+	assert.NoError(t, err)
+
+	// nolint:forcetypeassert
+	assert.Equal(t, argUint64(5), res.(*block).Number)
+
+	header, err := eth.getBlockHeader(SafeBlockNumber)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), header.Number)
+
+	// the tags follow the finality of the consensus
+	store.finalized = 9
+
+	num, err := GetNumericBlockNumber(FinalizedBlockNumber, eth)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(9), num)
+}
+
 func TestEth_Block_GetBlockByHash(t *testing.T) {
 	store := &mockBlockStore{}
 	store.add(newTestBlock(1, hash1))
@@ -190,6 +220,26 @@ func TestEth_Block_GetLogs(t *testing.T) {
 	}
 }
 
+func TestEth_Block_GetLogs_BlockRangeLimit(t *testing.T) {
+	store := &mockBlockStore{}
+	for i := 0; i < 5; i++ {
+		store.add(newTestBlock(uint64(i), types.StringToHash(strconv.Itoa(i))))
+	}
+
+	eth := newTestEthEndpoint(store)
+	eth.blockRangeLimit = 2
+
+	_, err := eth.GetLogs(&LogFilter{fromBlock: 1, toBlock: 2})
+	assert.NoError(t, err)
+
+	_, err = eth.GetLogs(&LogFilter{fromBlock: 1, toBlock: 3})
+
+	var limitErr *limitExceededError
+
+	assert.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, -32005, limitErr.ErrorCode())
+}
+
 func TestEth_GetTransactionByHash(t *testing.T) {
 	t.Run("returns correct transaction data if transaction is found in a sealed block", func(t *testing.T) {
 		store := &mockBlockStore{}
@@ -293,6 +343,86 @@ func TestEth_GetTransactionReceipt(t *testing.T) {
 	})
 }
 
+func TestEth_GetBlockReceipts(t *testing.T) {
+	store := newMockBlockStore()
+	eth := newTestEthEndpoint(store)
+
+	block := newTestBlock(1, hash4)
+	store.add(newTestBlock(0, hash1), block)
+
+	receipts := make([]*types.Receipt, 3)
+	for i := range receipts {
+		txn := newTestTransaction(uint64(i), addr0)
+		block.Transactions = append(block.Transactions, txn)
+
+		receipts[i] = &types.Receipt{
+			CumulativeGasUsed: uint64(i+1) * 21000,
+			GasUsed:           21000,
+			TxHash:            txn.Hash,
+		}
+		receipts[i].SetStatus(types.ReceiptSuccess)
+
+		// the i-th receipt emits i logs
+		for j := 0; j < i; j++ {
+			receipts[i].Logs = append(receipts[i].Logs, &types.Log{Address: addr1})
+		}
+	}
+
+	store.receipts[hash4] = receipts
+
+	t.Run("returns the receipts with the log indexes within the block", func(t *testing.T) {
+		num := BlockNumber(1)
+
+		res, err := eth.GetBlockReceipts(BlockNumberOrHash{BlockNumber: &num})
+		assert.NoError(t, err)
+
+		// nolint:forcetypeassert
+		response := res.([]*receipt)
+		assert.Len(t, response, 3)
+
+		logIndexes := []argUint64{}
+
+		for i, rec := range response {
+			assert.Equal(t, block.Transactions[i].Hash, rec.TxHash)
+			assert.Equal(t, argUint64(i), rec.TxIndex)
+			assert.Equal(t, addr0, rec.FromAddr)
+			assert.Equal(t, hash4, rec.BlockHash)
+			assert.Equal(t, argUint64(1), rec.BlockNumber)
+
+			for _, log := range rec.Logs {
+				assert.Equal(t, argUint64(i), log.TxIndex)
+				logIndexes = append(logIndexes, log.LogIndex)
+			}
+		}
+
+		assert.Equal(t, []argUint64{0, 1, 2}, logIndexes)
+	})
+
+	t.Run("matches the receipt of the transaction", func(t *testing.T) {
+		res, err := eth.GetBlockReceipts(BlockNumberOrHash{BlockHash: &hash4})
+		assert.NoError(t, err)
+
+		txReceipt, err := eth.GetTransactionReceipt(block.Transactions[2].Hash)
+		assert.NoError(t, err)
+
+		// nolint:forcetypeassert
+		assert.Equal(t, res.([]*receipt)[2], txReceipt)
+	})
+
+	t.Run("returns an empty list for a block without transactions", func(t *testing.T) {
+		res, err := eth.GetBlockReceipts(BlockNumberOrHash{BlockHash: &hash1})
+		assert.NoError(t, err)
+		assert.Equal(t, []*receipt{}, res)
+	})
+
+	t.Run("returns an error for an unknown block", func(t *testing.T) {
+		num := BlockNumber(5)
+
+		_, err := eth.GetBlockReceipts(BlockNumberOrHash{BlockNumber: &num})
+		assert.Error(t, err)
+	})
+}
+
 func TestEth_Syncing(t *testing.T) {
 	store := newMockBlockStore()
 	eth := newTestEthEndpoint(store)
@@ -326,9 +456,11 @@ func TestEth_Syncing(t *testing.T) {
 
 func TestEth_GasPrice(t *testing.T) {
 	store := newMockBlockStore()
+	store.add(newTestBlock(0, hash1))
 	store.averageGasPrice = 9999
 	eth := newTestEthEndpoint(store)
 
+	// there are no transactions to sample, the average gas price is used
 	res, err := eth.GasPrice()
 	assert.NoError(t, err)
 	assert.NotNil(t, res)
@@ -354,7 +486,7 @@ func TestEth_Call(t *testing.T) {
 			Nonce:    argUintPtr(0),
 		}
 
-		res, err := eth.Call(contractCall, BlockNumberOrHash{})
+		res, err := eth.Call(context.Background(), contractCall, BlockNumberOrHash{}, nil, nil)
 
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), store.ethCallError.Error())
@@ -376,11 +508,144 @@ func TestEth_Call(t *testing.T) {
 			Nonce:    argUintPtr(0),
 		}
 
-		res, err := eth.Call(contractCall, BlockNumberOrHash{})
+		res, err := eth.Call(context.Background(), contractCall, BlockNumberOrHash{}, nil, nil)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, res)
 	})
+
+	t.Run("passes the state and block overrides to the execution", func(t *testing.T) {
+		store := newMockBlockStore()
+		store.add(newTestBlock(100, hash1))
+
+		eth := newTestEthEndpoint(store)
+
+		var (
+			apiOverride      *stateOverride
+			apiBlockOverride *blockOverride
+		)
+
+		assert.NoError(t, json.Unmarshal([]byte(`{
+			"`+addr1.String()+`": {
+				"nonce": "0x5",
+				"balance": "0x64",
+				"code": "0x6001",
+				"stateDiff": {"0x01": "0x02"}
+			},
+			"`+addr2.String()+`": {"state": {}}
+		}`), &apiOverride))
+		assert.NoError(t, json.Unmarshal([]byte(
+			`{"number": "0x1000", "time": "0x10", "coinbase": "`+addr2.String()+`"}`,
+		), &apiBlockOverride))
+
+		_, err := eth.Call(
+			context.Background(),
+			&txnArgs{From: &addr0, To: &addr1, Nonce: argUintPtr(0)},
+			BlockNumberOrHash{},
+			apiOverride,
+			apiBlockOverride,
+		)
+		assert.NoError(t, err)
+
+		nonce, number, timestamp := uint64(5), uint64(0x1000), uint64(0x10)
+
+		assert.Equal(t, types.StateOverride{
+			addr1: {
+				Nonce:     &nonce,
+				Balance:   big.NewInt(100),
+				Code:      []byte{0x60, 0x01},
+				StateDiff: map[types.Hash]types.Hash{types.StringToHash("0x01"): types.StringToHash("0x02")},
+			},
+			addr2: {
+				State: map[types.Hash]types.Hash{},
+			},
+		}, store.stateOverride)
+		assert.Equal(t, &types.BlockOverride{
+			Number:    &number,
+			Timestamp: &timestamp,
+			Coinbase:  &addr2,
+		}, store.blockOverride)
+	})
+}
+
+func TestEth_CreateAccessList(t *testing.T) {
+	call := func() *txnArgs {
+		return &txnArgs{
+			From:  &addr0,
+			To:    &addr1,
+			Gas:   argUintPtr(100000),
+			Data:  argBytesPtr([]byte{0x1}),
+			Nonce: argUintPtr(0),
+		}
+	}
+
+	first := types.AccessList{
+		{Address: addr2, StorageKeys: []types.Hash{hash1}},
+	}
+	second := types.AccessList{
+		{Address: addr2, StorageKeys: []types.Hash{hash1, hash2}},
+		{Address: addr0, StorageKeys: []types.Hash{}},
+	}
+
+	t.Run("executes the call until the access list is stable", func(t *testing.T) {
+		store := newMockBlockStore()
+		store.add(newTestBlock(100, hash1))
+		store.accessLists = []types.AccessList{first, second}
+
+		eth := newTestEthEndpoint(store)
+
+		res, err := eth.CreateAccessList(context.Background(), call(), BlockNumberOrHash{})
+		assert.NoError(t, err)
+
+		// the executions are started with the list of the previous one
+		assert.Equal(t, []types.AccessList{nil, first, second}, store.accessListsIn)
+		assert.Equal(t, &accessListResult{AccessList: second, GasUsed: 21003}, res)
+	})
+
+	t.Run("starts with the given access list", func(t *testing.T) {
+		store := newMockBlockStore()
+		store.add(newTestBlock(100, hash1))
+		store.accessLists = []types.AccessList{second}
+
+		eth := newTestEthEndpoint(store)
+
+		arg := call()
+		arg.AccessList = &second
+
+		res, err := eth.CreateAccessList(context.Background(), arg, BlockNumberOrHash{})
+		assert.NoError(t, err)
+
+		assert.Equal(t, []types.AccessList{second}, store.accessListsIn)
+		assert.Equal(t, &accessListResult{AccessList: second, GasUsed: 21001}, res)
+	})
+
+	t.Run("gives up if the access list doesn't stabilize", func(t *testing.T) {
+		store := newMockBlockStore()
+		store.add(newTestBlock(100, hash1))
+
+		for i := 0; i < maxAccessListIterations; i++ {
+			store.accessLists = append(store.accessLists, first, second)
+		}
+
+		eth := newTestEthEndpoint(store)
+
+		res, err := eth.CreateAccessList(context.Background(), call(), BlockNumberOrHash{})
+		assert.ErrorIs(t, err, ErrAccessListUnstable)
+		assert.Nil(t, res)
+		assert.Len(t, store.accessListsIn, maxAccessListIterations)
+	})
+
+	t.Run("returns the error of the execution", func(t *testing.T) {
+		store := newMockBlockStore()
+		store.add(newTestBlock(100, hash1))
+		store.ethCallError = errors.New("an arbitrary error")
+
+		eth := newTestEthEndpoint(store)
+
+		res, err := eth.CreateAccessList(context.Background(), call(), BlockNumberOrHash{})
+		assert.ErrorIs(t, err, store.ethCallError)
+		assert.Nil(t, res)
+	})
 }
 
 type mockBlockStore struct {
@@ -390,8 +655,17 @@ type mockBlockStore struct {
 	pendingTxns     []*types.Transaction
 	receipts        map[types.Hash][]*types.Receipt
 	isSyncing       bool
+	finalized       uint64
+	safe            uint64
 	averageGasPrice int64
 	ethCallError    error
+	stateOverride   types.StateOverride
+	blockOverride   *types.BlockOverride
+
+	// accessLists are returned by the successive executions, the last one being repeated
+	accessLists []types.AccessList
+	// accessListsIn are the access lists the executions are started with
+	accessListsIn []types.AccessList
 }
 
 func newMockBlockStore() *mockBlockStore {
@@ -552,14 +826,47 @@ func (m *mockBlockStore) GetSyncProgression() *progress.Progression {
 	}
 }
 
+func (m *mockBlockStore) GetFinality() (uint64, uint64) {
+	return m.finalized, m.safe
+}
+
 func (m *mockBlockStore) GetAvgGasPrice() *big.Int {
 	return big.NewInt(m.averageGasPrice)
 }
 
-func (m *mockBlockStore) ApplyTxn(header *types.Header, txn *types.Transaction) (*runtime.ExecutionResult, error) {
+func (m *mockBlockStore) ApplyTxn(
+	_ context.Context,
+	header *types.Header,
+	txn *types.Transaction,
+	stateOverride types.StateOverride,
+	blockOverride *types.BlockOverride,
+) (*runtime.ExecutionResult, error) {
+	m.stateOverride = stateOverride
+	m.blockOverride = blockOverride
+
 	return &runtime.ExecutionResult{Err: m.ethCallError}, nil
 }
 
+func (m *mockBlockStore) ApplyTxnWithAccessList(
+	_ context.Context,
+	_ *types.Header,
+	_ *types.Transaction,
+	accessList types.AccessList,
+) (types.AccessList, *runtime.ExecutionResult, error) {
+	m.accessListsIn = append(m.accessListsIn, accessList)
+
+	if m.ethCallError != nil {
+		return nil, nil, m.ethCallError
+	}
+
+	list := m.accessLists[len(m.accessLists)-1]
+	if n := len(m.accessListsIn); n <= len(m.accessLists) {
+		list = m.accessLists[n-1]
+	}
+
+	return list, &runtime.ExecutionResult{GasUsed: 21000 + uint64(len(m.accessListsIn))}, nil
+}
+
 func newTestBlock(number uint64, hash types.Hash) *types.Block {
 	return &types.Block{
 		Header: &types.Header{
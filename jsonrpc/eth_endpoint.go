@@ -1,6 +1,7 @@
 package jsonrpc
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math/big"
@@ -56,10 +57,46 @@ type ethBlockchainStore interface {
 	GetAvgGasPrice() *big.Int
 
 	// ApplyTxn applies a transaction object to the blockchain
-	ApplyTxn(header *types.Header, txn *types.Transaction) (*runtime.ExecutionResult, error)
+	// with the given state and block context overrides.
+	// The execution is aborted once the context is done
+	ApplyTxn(
+		ctx context.Context,
+		header *types.Header,
+		txn *types.Transaction,
+		stateOverride types.StateOverride,
+		blockOverride *types.BlockOverride,
+	) (*runtime.ExecutionResult, error)
+
+	// ApplyTxnWithAccessList applies a transaction object to the blockchain, recording the accounts
+	// and the storage slots accessed by the execution along with the ones of the access list.
+	// The execution is aborted once the context is done
+	ApplyTxnWithAccessList(
+		ctx context.Context,
+		header *types.Header,
+		txn *types.Transaction,
+		accessList types.AccessList,
+	) (types.AccessList, *runtime.ExecutionResult, error)
 
 	// GetSyncProgression retrieves the current sync progression, if any
 	GetSyncProgression() *progress.Progression
+
+	finalityStore
+}
+
+// finalityStore provides the finality of the chain, as defined by the consensus
+type finalityStore interface {
+	// GetFinality returns the number of the latest finalized and safe blocks
+	GetFinality() (finalized uint64, safe uint64)
+}
+
+// getFinalityNumber returns the number of the latest finalized or safe block
+func getFinalityNumber(store finalityStore, number BlockNumber) uint64 {
+	finalized, safe := store.GetFinality()
+	if number == FinalizedBlockNumber {
+		return finalized
+	}
+
+	return safe
 }
 
 // ethStore provides access to the methods needed by eth endpoint
@@ -75,11 +112,19 @@ type Eth struct {
 	store         ethStore
 	chainID       uint64
 	filterManager *FilterManager
+	priceOracle   *gasPriceOracle
+
+	// the maximum block range queried by eth_getLogs, unlimited if zero
+	blockRangeLimit uint64
 }
 
+// maxAccessListIterations is the maximum number of executions of eth_createAccessList
+const maxAccessListIterations = 10
+
 var (
-	ErrInsufficientFunds = errors.New("insufficient funds for execution")
-	ErrGasCapOverflow    = errors.New("unable to apply transaction for the highest gas limit")
+	ErrInsufficientFunds  = errors.New("insufficient funds for execution")
+	ErrGasCapOverflow     = errors.New("unable to apply transaction for the highest gas limit")
+	ErrAccessListUnstable = errors.New("access list didn't stabilize")
 )
 
 // ChainId returns the chain id of the client
@@ -134,6 +179,9 @@ func GetNumericBlockNumber(number BlockNumber, e *Eth) (uint64, error) {
 	case EarliestBlockNumber:
 		return 0, nil
 
+	case FinalizedBlockNumber, SafeBlockNumber:
+		return getFinalityNumber(e.store, number), nil
+
 	case PendingBlockNumber:
 		return 0, fmt.Errorf("fetching the pending header is not supported")
 
@@ -339,41 +387,55 @@ func (e *Eth) GetTransactionReceipt(hash types.Hash) (interface{}, error) {
 		return nil, nil
 	}
 
-	txn := block.Transactions[indx]
-	raw := receipts[indx]
+	// the logs are indexed within the block
+	logIndex := uint64(0)
+	for _, raw := range receipts[:indx] {
+		logIndex += uint64(len(raw.Logs))
+	}
 
-	logs := make([]*Log, len(raw.Logs))
-	for indx, elem := range raw.Logs {
-		logs[indx] = &Log{
-			Address:     elem.Address,
-			Topics:      elem.Topics,
-			Data:        argBytes(elem.Data),
-			BlockHash:   block.Hash(),
-			BlockNumber: argUint64(block.Number()),
-			TxHash:      txn.Hash,
-			TxIndex:     argUint64(indx),
-			LogIndex:    argUint64(indx),
-			Removed:     false,
-		}
+	return toReceipt(receipts[indx], block.Transactions[indx], uint64(indx), block.Header, logIndex), nil
+}
+
+// GetBlockReceipts returns the receipts of all the transactions of a block
+func (e *Eth) GetBlockReceipts(filter BlockNumberOrHash) (interface{}, error) {
+	header, err := e.getHeaderFromBlockNumberOrHash(&filter)
+	if err != nil {
+		return nil, err
+	}
+
+	block, ok := e.store.GetBlockByHash(header.Hash, true)
+	if !ok {
+		// block not found
+		return nil, nil
+	}
+
+	result := make([]*receipt, 0, len(block.Transactions))
+	if len(block.Transactions) == 0 {
+		return result, nil
+	}
+
+	receipts, err := e.store.GetReceiptsByHash(header.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(receipts) != len(block.Transactions) {
+		// Receipts not written yet on the db
+		e.logger.Warn(
+			fmt.Sprintf("No receipts found for block with hash [%s]", header.Hash.String()),
+		)
+
+		return nil, nil
 	}
 
-	res := &receipt{
-		Root:              raw.Root,
-		CumulativeGasUsed: argUint64(raw.CumulativeGasUsed),
-		LogsBloom:         raw.LogsBloom,
-		Status:            argUint64(*raw.Status),
-		TxHash:            txn.Hash,
-		TxIndex:           argUint64(indx),
-		BlockHash:         block.Hash(),
-		BlockNumber:       argUint64(block.Number()),
-		GasUsed:           argUint64(raw.GasUsed),
-		ContractAddress:   raw.ContractAddress,
-		FromAddr:          txn.From,
-		ToAddr:            txn.To,
-		Logs:              logs,
+	logIndex := uint64(0)
+
+	for indx, raw := range receipts {
+		result = append(result, toReceipt(raw, block.Transactions[indx], uint64(indx), block.Header, logIndex))
+		logIndex += uint64(len(raw.Logs))
 	}
 
-	return res, nil
+	return result, nil
 }
 
 // GetStorageAt returns the contract storage at the index position
@@ -423,16 +485,70 @@ func (e *Eth) GetStorageAt(
 	return argBytesPtr(data), nil
 }
 
-// GasPrice returns the average gas price based on the last x blocks
+// GasPrice returns the gas price suggested by the oracle, based on the prices paid in the latest blocks
 func (e *Eth) GasPrice() (interface{}, error) {
-	// Grab the average gas price and convert it to a hex value
-	avgGasPrice := hex.EncodeBig(e.store.GetAvgGasPrice())
+	return hex.EncodeBig(e.priceOracle.SuggestGasPrice()), nil
+}
 
-	return avgGasPrice, nil
+// MaxPriorityFeePerGas returns the priority fee suggested by the oracle.
+// There is no base fee, so the whole gas price is the priority fee
+func (e *Eth) MaxPriorityFeePerGas() (interface{}, error) {
+	return hex.EncodeBig(e.priceOracle.SuggestGasPrice()), nil
 }
 
-// Call executes a smart contract call using the transaction object data
-func (e *Eth) Call(arg *txnArgs, filter BlockNumberOrHash) (interface{}, error) {
+// FeeHistory returns the gas used ratio, and the gas prices paid at the given percentiles of the gas used,
+// for blockCount blocks up to the newest block
+func (e *Eth) FeeHistory(
+	blockCount argUint64,
+	newestBlock BlockNumber,
+	rewardPercentiles []float64,
+) (interface{}, error) {
+	newest, err := GetNumericBlockNumber(newestBlock, e)
+	if err != nil {
+		return nil, err
+	}
+
+	if head := e.store.Header().Number; newest > head {
+		newest = head
+	}
+
+	history, err := e.priceOracle.FeeHistory(uint64(blockCount), newest, rewardPercentiles)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &feeHistory{
+		OldestBlock:  argUint64(history.oldestBlock),
+		GasUsedRatio: history.gasUsedRatio,
+	}
+
+	// there is no base fee, but the field is expected, including the one of the next block
+	result.BaseFeePerGas = make([]argBig, len(history.gasUsedRatio)+1)
+
+	if history.reward != nil {
+		result.Reward = make([][]argBig, len(history.reward))
+
+		for i, blockReward := range history.reward {
+			result.Reward[i] = make([]argBig, len(blockReward))
+
+			for j, reward := range blockReward {
+				result.Reward[i][j] = argBig(*reward)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// Call executes a smart contract call using the transaction object data.
+// The state and block context it runs on can be overridden using the optional parameters
+func (e *Eth) Call(
+	ctx context.Context,
+	arg *txnArgs,
+	filter BlockNumberOrHash,
+	apiOverride *stateOverride,
+	apiBlockOverride *blockOverride,
+) (interface{}, error) {
 	var (
 		header *types.Header
 		err    error
@@ -459,7 +575,13 @@ func (e *Eth) Call(arg *txnArgs, filter BlockNumberOrHash) (interface{}, error)
 	}
 
 	// The return value of the execution is saved in the transition (returnValue field)
-	result, err := e.store.ApplyTxn(header, transaction)
+	result, err := e.store.ApplyTxn(
+		ctx,
+		header,
+		transaction,
+		apiOverride.toStateOverride(),
+		apiBlockOverride.toBlockOverride(),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -476,8 +598,69 @@ func (e *Eth) Call(arg *txnArgs, filter BlockNumberOrHash) (interface{}, error)
 	return argBytesPtr(result.ReturnValue), nil
 }
 
-// EstimateGas estimates the gas needed to execute a transaction
-func (e *Eth) EstimateGas(arg *txnArgs, rawNum *BlockNumber) (interface{}, error) {
+// CreateAccessList returns the accounts and the storage slots accessed by the transaction object,
+// along with the gas used by the execution. The execution is repeated with the list of the previous
+// one until the list doesn't change, since the accessed accounts and slots can depend on the list.
+// It fails if the list doesn't stabilize within maxAccessListIterations executions
+func (e *Eth) CreateAccessList(ctx context.Context, arg *txnArgs, filter BlockNumberOrHash) (interface{}, error) {
+	// The filter is empty, use the latest block by default
+	if filter.BlockNumber == nil && filter.BlockHash == nil {
+		filter.BlockNumber, _ = createBlockNumberPointer("latest")
+	}
+
+	header, err := e.getHeaderFromBlockNumberOrHash(&filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get header from block hash or block number")
+	}
+
+	transaction, err := e.decodeTxn(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	if transaction.Gas == 0 {
+		transaction.Gas = header.GasLimit
+	}
+
+	var list types.AccessList
+	if arg.AccessList != nil {
+		list = *arg.AccessList
+	}
+
+	for i := 0; i < maxAccessListIterations; i++ {
+		accessList, result, err := e.store.ApplyTxnWithAccessList(ctx, header, transaction.Copy(), list)
+		if err != nil {
+			return nil, err
+		}
+
+		if accessList.Equal(list) {
+			res := &accessListResult{
+				AccessList: accessList,
+				GasUsed:    argUint64(result.GasUsed),
+			}
+
+			if result.Failed() {
+				res.Error = constructErrorFromRevert(result).Error()
+			}
+
+			return res, nil
+		}
+
+		list = accessList
+	}
+
+	return nil, fmt.Errorf("%w after %d executions", ErrAccessListUnstable, maxAccessListIterations)
+}
+
+// EstimateGas estimates the gas needed to execute a transaction.
+// The state and block context it runs on can be overridden using the optional parameters
+func (e *Eth) EstimateGas(
+	ctx context.Context,
+	arg *txnArgs,
+	rawNum *BlockNumber,
+	apiOverride *stateOverride,
+	apiBlockOverride *blockOverride,
+) (interface{}, error) {
 	transaction, err := e.decodeTxn(arg)
 	if err != nil {
 		return nil, err
@@ -496,6 +679,9 @@ func (e *Eth) EstimateGas(arg *txnArgs, rawNum *BlockNumber) (interface{}, error
 
 	forksInTime := e.store.GetForksInTime(uint64(number))
 
+	override := apiOverride.toStateOverride()
+	blockOverride := apiBlockOverride.toBlockOverride()
+
 	var standardGas uint64
 	if transaction.IsContractCreation() && forksInTime.Homestead {
 		standardGas = state.TxGasContractCreation
@@ -539,6 +725,11 @@ func (e *Eth) EstimateGas(arg *txnArgs, rawNum *BlockNumber) (interface{}, error
 			accountBalance = acc.Balance
 		}
 
+		// The balance of the sender can be overridden as well
+		if account, ok := override[transaction.From]; ok && account.Balance != nil {
+			accountBalance = account.Balance
+		}
+
 		availableBalance = new(big.Int).Set(accountBalance)
 
 		if transaction.Value != nil {
@@ -593,7 +784,7 @@ func (e *Eth) EstimateGas(arg *txnArgs, rawNum *BlockNumber) (interface{}, error
 		txn := transaction.Copy()
 		txn.Gas = gas
 
-		result, applyErr := e.store.ApplyTxn(header, txn)
+		result, applyErr := e.store.ApplyTxn(ctx, header, txn, override, blockOverride)
 
 		if applyErr != nil {
 			// Check the application error.
@@ -726,6 +917,10 @@ func (e *Eth) GetLogs(filterOptions *LogFilter) (interface{}, error) {
 			return head
 		}
 
+		if num == FinalizedBlockNumber || num == SafeBlockNumber {
+			return getFinalityNumber(e.store, num)
+		}
+
 		return uint64(num)
 	}
 
@@ -736,6 +931,12 @@ func (e *Eth) GetLogs(filterOptions *LogFilter) (interface{}, error) {
 		return nil, fmt.Errorf("incorrect range")
 	}
 
+	if e.blockRangeLimit > 0 && to-from >= e.blockRangeLimit {
+		return nil, NewLimitExceededError(
+			fmt.Sprintf("block range too large, the limit is %d blocks", e.blockRangeLimit),
+		)
+	}
+
 	for i := from; i <= to; i++ {
 		block, ok := e.store.GetBlockByNumber(i, true)
 		if !ok {
@@ -867,6 +1068,11 @@ func (e *Eth) NewBlockFilter() (interface{}, error) {
 	return e.filterManager.NewBlockFilter(nil), nil
 }
 
+// NewPendingTransactionFilter creates a filter in the node, to notify when new pending transactions arrive
+func (e *Eth) NewPendingTransactionFilter() (interface{}, error) {
+	return e.filterManager.NewPendingTxFilter(false, nil), nil
+}
+
 // GetFilterChanges is a polling method for a filter, which returns an array of logs which occurred since last poll.
 func (e *Eth) GetFilterChanges(id string) (interface{}, error) {
 	return e.filterManager.GetFilterChanges(id)
@@ -899,6 +1105,16 @@ func (e *Eth) getBlockHeader(number BlockNumber) (*types.Header, error) {
 
 		return header, nil
 
+	case FinalizedBlockNumber, SafeBlockNumber:
+		num := getFinalityNumber(e.store, number)
+
+		header, ok := e.store.GetHeaderByNumber(num)
+		if !ok {
+			return nil, fmt.Errorf("error fetching block number %d header", num)
+		}
+
+		return header, nil
+
 	case PendingBlockNumber:
 		return nil, fmt.Errorf("fetching the pending header is not supported")
 
@@ -2,6 +2,7 @@ package jsonrpc
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"math/big"
@@ -681,7 +682,7 @@ func TestEth_EstimateGas_GasLimit(t *testing.T) {
 			}
 
 			// Run the estimation
-			estimate, estimateErr := ethEndpoint.EstimateGas(testCase.transaction, nil)
+			estimate, estimateErr := ethEndpoint.EstimateGas(context.Background(), testCase.transaction, nil, nil, nil)
 
 			if testCase.expectedError != nil {
 				if estimateErr == nil {
@@ -730,8 +731,11 @@ func TestEth_EstimateGas_Reverts(t *testing.T) {
 
 	// Run the estimation
 	estimate, estimateErr := ethEndpoint.EstimateGas(
+		context.Background(),
 		constructMockTx(nil, nil),
 		nil,
+		nil,
+		nil,
 	)
 
 	assert.Equal(t, 0, estimate)
@@ -741,6 +745,13 @@ func TestEth_EstimateGas_Reverts(t *testing.T) {
 
 	// Make sure the EVM revert reason is contained
 	assert.ErrorAs(t, estimateErr, &revertReason)
+
+	// Make sure the revert data is returned to the client
+	var revertErr *revertError
+	if assert.ErrorAs(t, estimateErr, &revertErr) {
+		assert.Equal(t, 3, revertErr.ErrorCode())
+		assert.Equal(t, "0x"+exampleReturnData, revertErr.ErrorData())
+	}
 }
 
 func TestEth_EstimateGas_Errors(t *testing.T) {
@@ -756,8 +767,11 @@ func TestEth_EstimateGas_Errors(t *testing.T) {
 
 	// Run the estimation
 	estimate, estimateErr := ethEndpoint.EstimateGas(
+		context.Background(),
 		mockTx,
 		nil,
+		nil,
+		nil,
 	)
 
 	assert.Equal(t, 0, estimate)
@@ -833,7 +847,13 @@ func (m *mockSpecialStore) GetForksInTime(blockNumber uint64) chain.ForksInTime
 	return chain.ForksInTime{}
 }
 
-func (m *mockSpecialStore) ApplyTxn(header *types.Header, txn *types.Transaction) (*runtime.ExecutionResult, error) {
+func (m *mockSpecialStore) ApplyTxn(
+	_ context.Context,
+	header *types.Header,
+	txn *types.Transaction,
+	_ types.StateOverride,
+	_ *types.BlockOverride,
+) (*runtime.ExecutionResult, error) {
 	if m.applyTxnHook != nil {
 		return m.applyTxnHook(header, txn)
 	}
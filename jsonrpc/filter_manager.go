@@ -9,6 +9,9 @@ import (
 	"time"
 
 	"github.com/juanidrobo/polygon-edge/blockchain"
+	"github.com/juanidrobo/polygon-edge/helper/hex"
+	"github.com/juanidrobo/polygon-edge/helper/progress"
+	"github.com/juanidrobo/polygon-edge/txpool/proto"
 	"github.com/juanidrobo/polygon-edge/types"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
@@ -27,6 +30,12 @@ type Filter struct {
 	// log filter
 	logFilter *LogFilter
 
+	// pending transaction filter
+	pendingTx *pendingTxFilter
+
+	// syncing subscription
+	syncing *syncingFilter
+
 	// index of the filter in the timer array
 	index int
 
@@ -37,7 +46,35 @@ type Filter struct {
 	ws wsConn
 }
 
+// pendingTxFilter keeps the transactions promoted in the pool since the last poll
+type pendingTxFilter struct {
+	// fullTx is set if the full transaction objects are sent instead of the hashes
+	fullTx bool
+
+	// hashes of the pending transactions
+	hashes []types.Hash
+
+	// pending transactions, only kept if fullTx is set
+	txs []*types.Transaction
+}
+
+// syncingFilter keeps the last sync status sent to the subscriber
+type syncingFilter struct {
+	status string
+}
+
 func (f *Filter) getFilterUpdates() (string, error) {
+	if f.isPendingTxFilter() {
+		res, err := json.Marshal(f.pendingTx.hashes)
+		if err != nil {
+			return "", err
+		}
+
+		f.pendingTx.hashes = []types.Hash{}
+
+		return string(res), nil
+	}
+
 	if f.isBlockFilter() {
 		// block filter
 		headers, newHead := f.block.getUpdates()
@@ -84,6 +121,30 @@ func (f *Filter) sendMessage(msg string) error {
 }
 
 func (f *Filter) flush() error {
+	if f.isPendingTxFilter() {
+		// send each transaction independently
+		for i, hash := range f.pendingTx.hashes {
+			var update interface{} = hash
+			if f.pendingTx.fullTx {
+				update = toPendingTransaction(f.pendingTx.txs[i])
+			}
+
+			raw, err := json.Marshal(update)
+			if err != nil {
+				return err
+			}
+
+			if err := f.sendMessage(string(raw)); err != nil {
+				return err
+			}
+		}
+
+		f.pendingTx.hashes = []types.Hash{}
+		f.pendingTx.txs = []*types.Transaction{}
+
+		return nil
+	}
+
 	if f.isBlockFilter() {
 		// send each block independently
 		updates, newHead := f.block.getUpdates()
@@ -124,7 +185,20 @@ func (f *Filter) isBlockFilter() bool {
 	return f.block != nil
 }
 
-var defaultTimeout = 1 * time.Minute
+func (f *Filter) isPendingTxFilter() bool {
+	return f.pendingTx != nil
+}
+
+func (f *Filter) isSyncingFilter() bool {
+	return f.syncing != nil
+}
+
+var (
+	defaultTimeout = 1 * time.Minute
+
+	// syncingInterval is the interval in which the sync progression is checked for the syncing subscriptions
+	syncingInterval = 1 * time.Second
+)
 
 // filterManagerStore provides methods required by FilterManager
 type filterManagerStore interface {
@@ -136,6 +210,16 @@ type filterManagerStore interface {
 
 	// GetReceiptsByHash returns the receipts for a block hash
 	GetReceiptsByHash(hash types.Hash) ([]*types.Receipt, error)
+
+	// TxPoolSubscribe subscribes for the events of the transaction pool.
+	// The returned function cancels the subscription
+	TxPoolSubscribe(request *proto.SubscribeRequest) (<-chan *proto.TxPoolEvent, func())
+
+	// GetPendingTx gets the pending transaction from the transaction pool, if it's present
+	GetPendingTx(txHash types.Hash) (*types.Transaction, bool)
+
+	// GetSyncProgression retrieves the current sync progression, if any
+	GetSyncProgression() *progress.Progression
 }
 
 type FilterManager struct {
@@ -146,6 +230,10 @@ type FilterManager struct {
 
 	subscription blockchain.Subscription
 
+	// pending transactions watcher
+	txPoolEventCh     <-chan *proto.TxPoolEvent
+	cancelTxPoolWatch func()
+
 	filters map[string]*Filter
 	lock    sync.Mutex
 
@@ -175,6 +263,11 @@ func NewFilterManager(logger hclog.Logger, store filterManagerStore) *FilterMana
 	// start the head watcher
 	m.subscription = store.SubscribeEvents()
 
+	// start the pending transactions watcher
+	m.txPoolEventCh, m.cancelTxPoolWatch = store.TxPoolSubscribe(&proto.SubscribeRequest{
+		Types: []proto.EventType{proto.EventType_PROMOTED},
+	})
+
 	return m
 }
 
@@ -194,6 +287,9 @@ func (f *FilterManager) Run() {
 
 	var timeoutCh <-chan time.Time
 
+	syncingTicker := time.NewTicker(syncingInterval)
+	defer syncingTicker.Stop()
+
 	for {
 		// check for the next filter to be removed
 		filter := f.nextTimeoutFilter()
@@ -210,6 +306,21 @@ func (f *FilterManager) Run() {
 				f.logger.Error("failed to dispatch event", "err", err)
 			}
 
+		case evnt, ok := <-f.txPoolEventCh:
+			if !ok {
+				// the pool subscription is closed, stop watching it
+				f.txPoolEventCh = nil
+
+				continue
+			}
+
+			// new pending transaction
+			f.dispatchPendingTx(types.StringToHash(evnt.TxHash))
+
+		case <-syncingTicker.C:
+			// check for sync status changes
+			f.dispatchSyncing()
+
 		case <-timeoutCh:
 			// timeout for filter
 			if !f.Uninstall(filter.id) {
@@ -308,6 +419,93 @@ func (f *FilterManager) dispatchEvent(evnt *blockchain.Event) error {
 	return nil
 }
 
+// dispatchPendingTx adds the pending transaction to the pending transaction filters
+func (f *FilterManager) dispatchPendingTx(hash types.Hash) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	var tx *types.Transaction
+
+	for _, filter := range f.filters {
+		if !filter.isPendingTxFilter() {
+			continue
+		}
+
+		if filter.pendingTx.fullTx {
+			if tx == nil {
+				pendingTx, ok := f.store.GetPendingTx(hash)
+				if !ok {
+					// the transaction already left the pool
+					continue
+				}
+
+				tx = pendingTx
+			}
+
+			filter.pendingTx.txs = append(filter.pendingTx.txs, tx)
+		}
+
+		filter.pendingTx.hashes = append(filter.pendingTx.hashes, hash)
+
+		if filter.isWS() {
+			if flushErr := filter.flush(); flushErr != nil {
+				f.logger.Error(fmt.Sprintf("Unable to process flush, %v", flushErr))
+			}
+		}
+	}
+}
+
+// dispatchSyncing notifies the syncing subscriptions if the sync status changed
+func (f *FilterManager) dispatchSyncing() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	var status string
+
+	for _, filter := range f.filters {
+		if !filter.isSyncingFilter() {
+			continue
+		}
+
+		if status == "" {
+			status = f.getSyncStatus()
+		}
+
+		if filter.syncing.status == status {
+			continue
+		}
+
+		filter.syncing.status = status
+
+		if sendErr := filter.sendMessage(status); sendErr != nil {
+			f.logger.Error(fmt.Sprintf("Unable to send sync status, %v", sendErr))
+		}
+	}
+}
+
+// getSyncStatus returns the encoded sync status, in the eth_subscribe("syncing") format
+func (f *FilterManager) getSyncStatus() string {
+	syncProgression := f.store.GetSyncProgression()
+	if syncProgression == nil {
+		return "false"
+	}
+
+	res, err := json.Marshal(syncStatus{
+		Syncing: true,
+		Status: progression{
+			Type:          string(syncProgression.SyncType),
+			StartingBlock: hex.EncodeUint64(syncProgression.StartingBlock),
+			CurrentBlock:  hex.EncodeUint64(syncProgression.CurrentBlock),
+			HighestBlock:  hex.EncodeUint64(syncProgression.HighestBlock),
+		},
+	})
+	if err != nil {
+		return "false"
+	}
+
+	return string(res)
+}
+
 func (f *FilterManager) Exists(id string) bool {
 	f.lock.Lock()
 	_, ok := f.filters[id]
@@ -357,29 +555,36 @@ func (f *FilterManager) Uninstall(id string) bool {
 }
 
 func (f *FilterManager) NewBlockFilter(ws wsConn) string {
-	return f.addFilter(nil, ws)
+	// take the reference from the stream
+	return f.addFilter(&Filter{block: f.blockStream.Head(), ws: ws})
 }
 
 func (f *FilterManager) NewLogFilter(logFilter *LogFilter, ws wsConn) string {
-	return f.addFilter(logFilter, ws)
+	return f.addFilter(&Filter{logFilter: logFilter, ws: ws})
 }
 
-func (f *FilterManager) addFilter(logFilter *LogFilter, ws wsConn) string {
-	f.lock.Lock()
-
-	filter := &Filter{
-		id: uuid.New().String(),
+// NewPendingTxFilter adds a filter for the transactions promoted in the pool.
+// Full transaction objects are sent to the WS subscribers if fullTx is set
+func (f *FilterManager) NewPendingTxFilter(fullTx bool, ws wsConn) string {
+	return f.addFilter(&Filter{
+		pendingTx: &pendingTxFilter{
+			fullTx: fullTx && ws != nil,
+			hashes: []types.Hash{},
+			txs:    []*types.Transaction{},
+		},
 		ws: ws,
-	}
+	})
+}
 
-	if logFilter == nil {
-		// block filter
-		// take the reference from the stream
-		filter.block = f.blockStream.Head()
-	} else {
-		// log filter
-		filter.logFilter = logFilter
-	}
+// NewSyncingFilter adds a WS subscription for the sync status changes
+func (f *FilterManager) NewSyncingFilter(ws wsConn) string {
+	return f.addFilter(&Filter{syncing: &syncingFilter{}, ws: ws})
+}
+
+func (f *FilterManager) addFilter(filter *Filter) string {
+	f.lock.Lock()
+
+	filter.id = uuid.New().String()
 
 	f.filters[filter.id] = filter
 	filter.timestamp = time.Now().Add(f.timeout)
@@ -396,6 +601,7 @@ func (f *FilterManager) addFilter(logFilter *LogFilter, ws wsConn) string {
 }
 
 func (f *FilterManager) Close() {
+	f.cancelTxPoolWatch()
 	close(f.closeCh)
 }
 
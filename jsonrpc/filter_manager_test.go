@@ -1,9 +1,12 @@
 package jsonrpc
 
 import (
+	"encoding/json"
+	"math/big"
 	"testing"
 	"time"
 
+	"github.com/juanidrobo/polygon-edge/helper/progress"
 	"github.com/juanidrobo/polygon-edge/types"
 	"github.com/hashicorp/go-hclog"
 	"github.com/stretchr/testify/assert"
@@ -15,7 +18,7 @@ func TestFilterLog(t *testing.T) {
 	m := NewFilterManager(hclog.NewNullLogger(), store)
 	go m.Run()
 
-	id := m.addFilter(&LogFilter{
+	id := m.NewLogFilter(&LogFilter{
 		Topics: [][]types.Hash{
 			{hash1},
 		},
@@ -74,7 +77,7 @@ func TestFilterBlock(t *testing.T) {
 	go m.Run()
 
 	// add block filter
-	id := m.addFilter(nil, nil)
+	id := m.NewBlockFilter(nil)
 
 	// emit two events
 	store.emitEvent(&mockEvent{
@@ -137,7 +140,7 @@ func TestFilterTimeout(t *testing.T) {
 	go m.Run()
 
 	// add block filter
-	id := m.addFilter(nil, nil)
+	id := m.NewBlockFilter(nil)
 
 	assert.True(t, m.Exists(id))
 	time.Sleep(3 * time.Second)
@@ -178,6 +181,147 @@ func TestFilterWebsocket(t *testing.T) {
 	}
 }
 
+func TestFilterPendingTx(t *testing.T) {
+	store := newMockStore()
+
+	m := NewFilterManager(hclog.NewNullLogger(), store)
+	go m.Run()
+
+	id := m.NewPendingTxFilter(false, nil)
+
+	store.addPendingTx(&types.Transaction{Hash: hash1})
+	store.addPendingTx(&types.Transaction{Hash: hash2})
+
+	// we need to wait for the manager to process the data
+	time.Sleep(500 * time.Millisecond)
+
+	changes, err := m.GetFilterChanges(id)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `["`+hash1.String()+`","`+hash2.String()+`"]`, changes)
+
+	// the transactions are returned only once
+	changes, err = m.GetFilterChanges(id)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[]`, changes)
+}
+
+func TestFilterPendingTxWebsocket(t *testing.T) {
+	store := newMockStore()
+
+	m := NewFilterManager(hclog.NewNullLogger(), store)
+	go m.Run()
+
+	hashConn := &mockWsConn{msgCh: make(chan []byte, 1)}
+	fullTxConn := &mockWsConn{msgCh: make(chan []byte, 1)}
+
+	m.NewPendingTxFilter(false, hashConn)
+	m.NewPendingTxFilter(true, fullTxConn)
+
+	tx := &types.Transaction{
+		Hash:     hash1,
+		Nonce:    1,
+		GasPrice: big.NewInt(10),
+		Value:    big.NewInt(0),
+		V:        big.NewInt(0),
+		R:        big.NewInt(0),
+		S:        big.NewInt(0),
+	}
+	store.addPendingTx(tx)
+
+	expectResult := func(conn *mockWsConn, result interface{}) {
+		t.Helper()
+
+		select {
+		case msg := <-conn.msgCh:
+			var notification struct {
+				Params struct {
+					Result json.RawMessage `json:"result"`
+				} `json:"params"`
+			}
+
+			assert.NoError(t, json.Unmarshal(msg, &notification))
+
+			expected, err := json.Marshal(result)
+			assert.NoError(t, err)
+			assert.JSONEq(t, string(expected), string(notification.Params.Result))
+		case <-time.After(2 * time.Second):
+			t.Fatal("no pending transaction notification")
+		}
+	}
+
+	expectResult(hashConn, hash1)
+	expectResult(fullTxConn, toPendingTransaction(tx))
+}
+
+func TestFilterSyncingWebsocket(t *testing.T) {
+	defaultInterval := syncingInterval
+	syncingInterval = 100 * time.Millisecond
+
+	t.Cleanup(func() {
+		syncingInterval = defaultInterval
+	})
+
+	store := newMockStore()
+
+	mock := &mockWsConn{
+		msgCh: make(chan []byte, 1),
+	}
+
+	m := NewFilterManager(hclog.NewNullLogger(), store)
+	go m.Run()
+
+	m.NewSyncingFilter(mock)
+
+	expectResult := func(result string) {
+		t.Helper()
+
+		select {
+		case msg := <-mock.msgCh:
+			var notification struct {
+				Params struct {
+					Result json.RawMessage `json:"result"`
+				} `json:"params"`
+			}
+
+			assert.NoError(t, json.Unmarshal(msg, &notification))
+			assert.JSONEq(t, result, string(notification.Params.Result))
+		case <-time.After(2 * time.Second):
+			t.Fatal("no sync status notification")
+		}
+	}
+
+	// the current status is sent first
+	expectResult(`false`)
+
+	store.setSyncProgression(&progress.Progression{
+		SyncType:      progress.ChainSyncBulk,
+		StartingBlock: 1,
+		CurrentBlock:  5,
+		HighestBlock:  10,
+	})
+
+	expectResult(`{
+		"syncing": true,
+		"status": {
+			"type": "bulk-sync",
+			"startingBlock": "0x1",
+			"currentBlock": "0x5",
+			"highestBlock": "0xa"
+		}
+	}`)
+
+	// the status is only sent when it changes
+	select {
+	case <-mock.msgCh:
+		t.Fatal("unchanged sync status sent")
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	store.setSyncProgression(nil)
+
+	expectResult(`false`)
+}
+
 type mockWsConn struct {
 	msgCh chan []byte
 }
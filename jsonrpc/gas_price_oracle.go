@@ -0,0 +1,270 @@
+package jsonrpc
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/juanidrobo/polygon-edge/types"
+)
+
+const (
+	// DefaultGasPriceBlocks is the default number of the latest blocks sampled by the gas price oracle
+	DefaultGasPriceBlocks uint64 = 20
+
+	// DefaultGasPricePercentile is the default percentile of the sampled gas prices suggested by the oracle
+	DefaultGasPricePercentile uint64 = 60
+
+	// gasPriceSampleNumber is the number of the cheapest transactions sampled from each block
+	gasPriceSampleNumber = 3
+
+	// maxFeeHistory is the maximum number of blocks returned by eth_feeHistory
+	maxFeeHistory = 1024
+)
+
+var (
+	errInvalidPercentile = errors.New("invalid reward percentile")
+	errFeeHistoryBlock   = errors.New("unable to fetch the fee history block")
+)
+
+// gasPriceOracleStore provides the methods required by the gas price oracle
+type gasPriceOracleStore interface {
+	// Header returns the current header of the chain (genesis if empty)
+	Header() *types.Header
+
+	// GetBlockByNumber returns a block using the provided number
+	GetBlockByNumber(num uint64, full bool) (*types.Block, bool)
+
+	// GetReceiptsByHash returns the receipts for a block hash
+	GetReceiptsByHash(hash types.Hash) ([]*types.Receipt, error)
+
+	// GetAvgGasPrice returns the average gas price
+	GetAvgGasPrice() *big.Int
+}
+
+// gasPriceOracle suggests gas prices based on the prices of the transactions in the latest blocks.
+// The cheapest transactions of each block are sampled, and the configured percentile of the samples is suggested
+type gasPriceOracle struct {
+	store      gasPriceOracleStore
+	blocks     uint64
+	percentile uint64
+
+	// the last suggested price is cached until the head changes
+	lock      sync.Mutex
+	lastHead  types.Hash
+	lastPrice *big.Int
+}
+
+func newGasPriceOracle(store gasPriceOracleStore, blocks, percentile uint64) *gasPriceOracle {
+	if blocks == 0 {
+		blocks = DefaultGasPriceBlocks
+	}
+
+	if percentile > 100 {
+		percentile = 100
+	}
+
+	return &gasPriceOracle{
+		store:      store,
+		blocks:     blocks,
+		percentile: percentile,
+	}
+}
+
+// SuggestGasPrice returns the gas price suggested for new transactions
+func (o *gasPriceOracle) SuggestGasPrice() *big.Int {
+	head := o.store.Header()
+
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	if o.lastPrice != nil && o.lastHead == head.Hash {
+		return new(big.Int).Set(o.lastPrice)
+	}
+
+	// empty blocks don't lower the price, the last suggested price is sampled instead.
+	// Until there are transactions, the price falls back to the average of all the seen transactions
+	lastPrice := o.lastPrice
+	if lastPrice == nil {
+		lastPrice = o.store.GetAvgGasPrice()
+	}
+
+	prices := make([]*big.Int, 0, o.blocks*gasPriceSampleNumber)
+
+	for i := uint64(0); i < o.blocks && i <= head.Number; i++ {
+		block, ok := o.store.GetBlockByNumber(head.Number-i, true)
+		if !ok {
+			break
+		}
+
+		blockPrices := sampleGasPrices(block, gasPriceSampleNumber)
+		if len(blockPrices) == 0 {
+			blockPrices = []*big.Int{lastPrice}
+		}
+
+		prices = append(prices, blockPrices...)
+	}
+
+	price := lastPrice
+
+	if len(prices) > 0 {
+		sort.Slice(prices, func(i, j int) bool {
+			return prices[i].Cmp(prices[j]) < 0
+		})
+
+		price = prices[(uint64(len(prices))-1)*o.percentile/100]
+	}
+
+	o.lastHead = head.Hash
+	o.lastPrice = price
+
+	return new(big.Int).Set(price)
+}
+
+// sampleGasPrices returns the lowest gas prices of the block transactions, ignoring the ones sent by the miner
+func sampleGasPrices(block *types.Block, limit int) []*big.Int {
+	prices := make([]*big.Int, 0, len(block.Transactions))
+
+	for _, tx := range block.Transactions {
+		if tx.From == block.Header.Miner {
+			continue
+		}
+
+		prices = append(prices, tx.GasPrice)
+	}
+
+	sort.Slice(prices, func(i, j int) bool {
+		return prices[i].Cmp(prices[j]) < 0
+	})
+
+	if len(prices) > limit {
+		prices = prices[:limit]
+	}
+
+	return prices
+}
+
+// feeHistoryResult is the fee history of a range of blocks
+type feeHistoryResult struct {
+	oldestBlock  uint64
+	reward       [][]*big.Int
+	gasUsedRatio []float64
+}
+
+// FeeHistory returns the gas used ratio and the reward percentiles of blockCount blocks, up to the newest block
+func (o *gasPriceOracle) FeeHistory(
+	blockCount uint64,
+	newestBlock uint64,
+	rewardPercentiles []float64,
+) (*feeHistoryResult, error) {
+	for i, p := range rewardPercentiles {
+		if p < 0 || p > 100 || (i > 0 && p < rewardPercentiles[i-1]) {
+			return nil, fmt.Errorf("%w: %f", errInvalidPercentile, p)
+		}
+	}
+
+	if blockCount > maxFeeHistory {
+		blockCount = maxFeeHistory
+	}
+
+	if blockCount > newestBlock+1 {
+		blockCount = newestBlock + 1
+	}
+
+	result := &feeHistoryResult{
+		oldestBlock:  newestBlock + 1 - blockCount,
+		gasUsedRatio: make([]float64, 0, blockCount),
+	}
+
+	for number := result.oldestBlock; number <= newestBlock; number++ {
+		block, ok := o.store.GetBlockByNumber(number, true)
+		if !ok {
+			return nil, fmt.Errorf("%w: %d", errFeeHistoryBlock, number)
+		}
+
+		if block.Header.GasLimit > 0 {
+			result.gasUsedRatio = append(
+				result.gasUsedRatio,
+				float64(block.Header.GasUsed)/float64(block.Header.GasLimit),
+			)
+		} else {
+			result.gasUsedRatio = append(result.gasUsedRatio, 0)
+		}
+
+		if len(rewardPercentiles) == 0 {
+			continue
+		}
+
+		reward, err := o.blockRewards(block, rewardPercentiles)
+		if err != nil {
+			return nil, err
+		}
+
+		result.reward = append(result.reward, reward)
+	}
+
+	return result, nil
+}
+
+// blockRewards returns the gas prices paid at the given percentiles of the block gas used
+func (o *gasPriceOracle) blockRewards(block *types.Block, percentiles []float64) ([]*big.Int, error) {
+	reward := make([]*big.Int, len(percentiles))
+
+	if len(block.Transactions) == 0 {
+		for i := range reward {
+			reward[i] = big.NewInt(0)
+		}
+
+		return reward, nil
+	}
+
+	receipts, err := o.store.GetReceiptsByHash(block.Hash())
+	if err != nil {
+		return nil, err
+	}
+
+	if len(receipts) != len(block.Transactions) {
+		return nil, fmt.Errorf("%w: %d, receipts not found", errFeeHistoryBlock, block.Number())
+	}
+
+	type txGas struct {
+		gasUsed  uint64
+		gasPrice *big.Int
+	}
+
+	sorted := make([]txGas, len(block.Transactions))
+
+	for i, tx := range block.Transactions {
+		gasUsed := receipts[i].CumulativeGasUsed
+		if i > 0 {
+			gasUsed -= receipts[i-1].CumulativeGasUsed
+		}
+
+		sorted[i] = txGas{gasUsed: gasUsed, gasPrice: tx.GasPrice}
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].gasPrice.Cmp(sorted[j].gasPrice) < 0
+	})
+
+	var (
+		txIndex     = 0
+		sumGasUsed  = sorted[0].gasUsed
+		blockGasUse = block.Header.GasUsed
+	)
+
+	for i, p := range percentiles {
+		threshold := uint64(float64(blockGasUse) * p / 100)
+
+		for sumGasUsed < threshold && txIndex < len(sorted)-1 {
+			txIndex++
+			sumGasUsed += sorted[txIndex].gasUsed
+		}
+
+		reward[i] = new(big.Int).Set(sorted[txIndex].gasPrice)
+	}
+
+	return reward, nil
+}
@@ -0,0 +1,138 @@
+package jsonrpc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/juanidrobo/polygon-edge/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// newGasPriceTestBlock creates a block with transactions paying the given gas prices,
+// each transaction using the given gas
+func newGasPriceTestBlock(
+	store *mockBlockStore,
+	number uint64,
+	gasLimit uint64,
+	gasUsed uint64,
+	gasPrices ...int64,
+) *types.Block {
+	block := &types.Block{
+		Header: &types.Header{
+			Number:   number,
+			Hash:     types.BytesToHash(big.NewInt(int64(number + 1)).Bytes()),
+			GasLimit: gasLimit,
+			Miner:    addr0,
+		},
+	}
+
+	receipts := make([]*types.Receipt, len(gasPrices))
+
+	for i, gasPrice := range gasPrices {
+		block.Transactions = append(block.Transactions, &types.Transaction{
+			From:     addr1,
+			GasPrice: big.NewInt(gasPrice),
+		})
+
+		block.Header.GasUsed += gasUsed
+		receipts[i] = &types.Receipt{CumulativeGasUsed: block.Header.GasUsed}
+	}
+
+	store.receipts[block.Header.Hash] = receipts
+	store.add(block)
+
+	return block
+}
+
+func TestGasPriceOracle_SuggestGasPrice(t *testing.T) {
+	t.Run("suggests the percentile of the cheapest transactions", func(t *testing.T) {
+		store := newMockBlockStore()
+		newGasPriceTestBlock(store, 0, 0, 0)
+		newGasPriceTestBlock(store, 1, 100, 10, 10, 20, 30, 1000)
+		newGasPriceTestBlock(store, 2, 100, 10, 40, 50)
+
+		// samples: 10, 20, 30 (the most expensive one is ignored), 40, 50 and the fallback price for genesis
+		store.averageGasPrice = 1
+
+		assert.Equal(t, big.NewInt(30), newGasPriceOracle(store, 10, 60).SuggestGasPrice())
+		assert.Equal(t, big.NewInt(1), newGasPriceOracle(store, 10, 0).SuggestGasPrice())
+		assert.Equal(t, big.NewInt(50), newGasPriceOracle(store, 10, 100).SuggestGasPrice())
+
+		// only the latest block is sampled
+		assert.Equal(t, big.NewInt(40), newGasPriceOracle(store, 1, 0).SuggestGasPrice())
+	})
+
+	t.Run("ignores the transactions sent by the miner", func(t *testing.T) {
+		store := newMockBlockStore()
+		block := newGasPriceTestBlock(store, 0, 100, 10, 1, 100)
+		block.Transactions[0].From = block.Header.Miner
+
+		assert.Equal(t, big.NewInt(100), newGasPriceOracle(store, 10, 0).SuggestGasPrice())
+	})
+
+	t.Run("caches the price until the head changes", func(t *testing.T) {
+		store := newMockBlockStore()
+		newGasPriceTestBlock(store, 0, 100, 10, 100)
+
+		oracle := newGasPriceOracle(store, 10, 60)
+		assert.Equal(t, big.NewInt(100), oracle.SuggestGasPrice())
+
+		// the cached price is returned for the same head
+		store.blocks[0].Transactions[0].GasPrice = big.NewInt(200)
+		assert.Equal(t, big.NewInt(100), oracle.SuggestGasPrice())
+
+		// empty blocks sample the last suggested price
+		newGasPriceTestBlock(store, 1, 100, 0)
+		assert.Equal(t, big.NewInt(100), oracle.SuggestGasPrice())
+
+		// the price comes down after a spike
+		newGasPriceTestBlock(store, 2, 100, 10, 5)
+		newGasPriceTestBlock(store, 3, 100, 10, 5)
+		assert.Equal(t, big.NewInt(5), oracle.SuggestGasPrice())
+	})
+}
+
+func TestGasPriceOracle_FeeHistory(t *testing.T) {
+	store := newMockBlockStore()
+	newGasPriceTestBlock(store, 0, 100, 0)
+	newGasPriceTestBlock(store, 1, 100, 10, 30, 10, 20)
+	newGasPriceTestBlock(store, 2, 100, 50, 40)
+
+	oracle := newGasPriceOracle(store, 0, 0)
+
+	t.Run("returns the gas used ratio and the rewards", func(t *testing.T) {
+		history, err := oracle.FeeHistory(2, 2, []float64{0, 50, 100})
+		assert.NoError(t, err)
+
+		assert.Equal(t, uint64(1), history.oldestBlock)
+		assert.Equal(t, []float64{0.3, 0.5}, history.gasUsedRatio)
+		assert.Equal(t, [][]*big.Int{
+			{big.NewInt(10), big.NewInt(20), big.NewInt(30)},
+			{big.NewInt(40), big.NewInt(40), big.NewInt(40)},
+		}, history.reward)
+	})
+
+	t.Run("caps the block count at genesis", func(t *testing.T) {
+		history, err := oracle.FeeHistory(10, 1, []float64{50})
+		assert.NoError(t, err)
+
+		assert.Equal(t, uint64(0), history.oldestBlock)
+		assert.Equal(t, []float64{0, 0.3}, history.gasUsedRatio)
+		assert.Equal(t, [][]*big.Int{{big.NewInt(0)}, {big.NewInt(20)}}, history.reward)
+	})
+
+	t.Run("omits the rewards without percentiles", func(t *testing.T) {
+		history, err := oracle.FeeHistory(1, 2, nil)
+		assert.NoError(t, err)
+
+		assert.Nil(t, history.reward)
+	})
+
+	t.Run("rejects invalid percentiles", func(t *testing.T) {
+		_, err := oracle.FeeHistory(1, 2, []float64{50, 10})
+		assert.ErrorIs(t, err, errInvalidPercentile)
+
+		_, err = oracle.FeeHistory(1, 2, []float64{101})
+		assert.ErrorIs(t, err, errInvalidPercentile)
+	})
+}
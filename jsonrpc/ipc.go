@@ -0,0 +1,140 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/juanidrobo/polygon-edge/helper/ipc"
+)
+
+// ipcWrapper is a wrapping object for the IPC connection and logger
+type ipcWrapper struct {
+	conn      net.Conn     // the actual IPC connection
+	logger    hclog.Logger // module logger
+	writeLock sync.Mutex   // writer lock
+}
+
+// WriteMessage writes out the message to the IPC peer, as a newline delimited JSON value.
+// The message type is ignored, as IPC connections are plain streams
+func (w *ipcWrapper) WriteMessage(_ int, data []byte) error {
+	w.writeLock.Lock()
+	defer w.writeLock.Unlock()
+
+	_, writeErr := w.conn.Write(append(data, '\n'))
+	if writeErr != nil {
+		w.logger.Error(
+			fmt.Sprintf("Unable to write IPC message, %s", writeErr.Error()),
+		)
+	}
+
+	return writeErr
+}
+
+// setupIPC starts serving JSON-RPC requests on the Unix domain socket (named pipe on Windows)
+func (j *JSONRPC) setupIPC() error {
+	lis, err := ipc.Listen(j.config.IPCPath)
+	if err != nil {
+		return err
+	}
+
+	j.logger.Info("ipc server started", "path", j.config.IPCPath)
+
+	j.ipcListener = lis
+
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				if !errors.Is(err, net.ErrClosed) {
+					j.logger.Error("closed ipc listener", "err", err)
+				}
+
+				return
+			}
+
+			go j.handleIPC(conn)
+		}
+	}()
+
+	return nil
+}
+
+// handleIPC serves the requests of an IPC connection, until it is closed.
+// Requests and responses are consecutive JSON values on the stream
+func (j *JSONRPC) handleIPC(conn net.Conn) {
+	defer func() {
+		if err := conn.Close(); err != nil {
+			j.logger.Error(
+				fmt.Sprintf("Unable to gracefully close IPC connection, %s", err.Error()),
+			)
+		}
+	}()
+
+	wrapConn := &ipcWrapper{conn: conn, logger: j.logger}
+	decoder := json.NewDecoder(conn)
+
+	j.logger.Debug("IPC connection established")
+
+	for {
+		var message json.RawMessage
+		if err := decoder.Decode(&message); err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				j.logger.Error(fmt.Sprintf("Unable to read IPC message, %s", err.Error()))
+
+				// the stream can't be recovered after malformed input
+				if resp, respErr := NewRPCResponse(
+					nil, "2.0", nil, NewInvalidRequestError("Invalid json request"),
+				).Bytes(); respErr == nil {
+					_ = wrapConn.WriteMessage(0, resp)
+				}
+			}
+
+			return
+		}
+
+		if limit := j.config.Limits.BodySize; limit > 0 && int64(len(message)) > limit {
+			if resp, respErr := NewRPCResponse(
+				nil,
+				"2.0",
+				nil,
+				NewLimitExceededError(fmt.Sprintf("request too large, the limit is %d bytes", limit)),
+			).Bytes(); respErr == nil {
+				_ = wrapConn.WriteMessage(0, resp)
+			}
+
+			continue
+		}
+
+		go func() {
+			resp, handleErr := j.handleIPCMessage(message, wrapConn)
+			if handleErr != nil {
+				j.logger.Error(fmt.Sprintf("Unable to handle IPC request, %s", handleErr.Error()))
+
+				return
+			}
+
+			_ = wrapConn.WriteMessage(0, resp)
+		}()
+	}
+}
+
+// handleIPCMessage dispatches a single IPC message. Subscriptions are bound to the connection,
+// like the WS ones, while the other requests (and batches) are handled like the HTTP ones.
+// IPC clients are local, so they are not subject to the per-IP rate limits
+func (j *JSONRPC) handleIPCMessage(message []byte, conn wsConn) ([]byte, error) {
+	ctx := context.Background()
+
+	var req Request
+	if err := json.Unmarshal(message, &req); err == nil &&
+		(req.Method == "eth_subscribe" || req.Method == "eth_unsubscribe") {
+		return j.dispatcher.HandleWs(ctx, message, conn)
+	}
+
+	return j.dispatcher.Handle(ctx, message)
+}
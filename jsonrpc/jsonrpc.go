@@ -1,7 +1,9 @@
 package jsonrpc
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net"
 	"net/http"
@@ -34,14 +36,19 @@ func (s serverType) String() string {
 
 // JSONRPC is an API backend
 type JSONRPC struct {
-	logger     hclog.Logger
-	config     *Config
-	dispatcher dispatcher
+	logger      hclog.Logger
+	config      *Config
+	dispatcher  dispatcher
+	ipcListener net.Listener
+
+	// the namespaces served by the listeners
+	httpNamespaces map[string]bool
+	wsNamespaces   map[string]bool
 }
 
 type dispatcher interface {
-	HandleWs(reqBody []byte, conn wsConn) ([]byte, error)
-	Handle(reqBody []byte) ([]byte, error)
+	HandleWs(ctx context.Context, reqBody []byte, conn wsConn) ([]byte, error)
+	Handle(ctx context.Context, reqBody []byte) ([]byte, error)
 }
 
 // JSONRPCStore defines all the methods required
@@ -51,21 +58,70 @@ type JSONRPCStore interface {
 	networkStore
 	txPoolStore
 	filterManagerStore
+	adminStore
 }
 
 type Config struct {
 	Store                    JSONRPCStore
 	Addr                     *net.TCPAddr
+	WSAddr                   *net.TCPAddr // the WS listener address, WS is served on the HTTP one (/ws) if nil
+	IPCPath                  string       // the IPC endpoint path, IPC is disabled if empty
 	ChainID                  uint64
 	AccessControlAllowOrigin []string
+	GasPriceBlocks           uint64 // the number of the latest blocks sampled by the gas price oracle
+	GasPricePercentile       uint64 // the percentile of the sampled gas prices suggested by the oracle
+	Limits                   Limits // the limits enforced on the requests
+
+	// the namespaces served over HTTP and WS, all the non privileged ones if empty.
+	// IPC always serves all of them
+	HTTPNamespaces []string
+	WSNamespaces   []string
+
+	// the admin namespace is served only if enabled
+	EnableAdmin bool
+	DataDir     string
+
+	// the HS256 shared secrets of the tokens authenticating the HTTP and WS requests, next to their
+	// namespaces. The authentication of each listener is disabled if its secret is empty
+	HTTPJWTSecret []byte
+	WSJWTSecret   []byte
 }
 
 // NewJSONRPC returns the JSONRPC http server
 func NewJSONRPC(logger hclog.Logger, config *Config) (*JSONRPC, error) {
+	d := newDispatcher(logger, config.Store, &dispatcherParams{
+		chainID:            config.ChainID,
+		gasPriceBlocks:     config.GasPriceBlocks,
+		gasPricePercentile: config.GasPricePercentile,
+		limits:             config.Limits,
+		enableAdmin:        config.EnableAdmin,
+		dataDir:            config.DataDir,
+	})
+
+	httpNamespaces, err := d.namespaceSet(config.HTTPNamespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	wsNamespaces, err := d.namespaceSet(config.WSNamespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(config.HTTPJWTSecret) == 0 && httpNamespaces["admin"] {
+		logger.Warn("the admin JSON-RPC namespace is served over HTTP without authentication")
+	}
+
+	if len(config.WSJWTSecret) == 0 && wsNamespaces["admin"] {
+		logger.Warn("the admin JSON-RPC namespace is served over WS without authentication")
+	}
+
 	srv := &JSONRPC{
-		logger:     logger.Named("jsonrpc"),
-		config:     config,
-		dispatcher: newDispatcher(logger, config.Store, config.ChainID),
+		logger:         logger.Named("jsonrpc"),
+		config:         config,
+		dispatcher:     d,
+		httpNamespaces: httpNamespaces,
+		wsNamespaces:   wsNamespaces,
 	}
 
 	// start http server
@@ -73,9 +129,32 @@ func NewJSONRPC(logger hclog.Logger, config *Config) (*JSONRPC, error) {
 		return nil, err
 	}
 
+	// start ws server, if it has its own listener
+	if config.WSAddr != nil {
+		if err := srv.setupWS(); err != nil {
+			return nil, err
+		}
+	}
+
+	// start ipc server
+	if config.IPCPath != "" {
+		if err := srv.setupIPC(); err != nil {
+			return nil, err
+		}
+	}
+
 	return srv, nil
 }
 
+// Close stops serving the IPC endpoint, if enabled
+func (j *JSONRPC) Close() error {
+	if j.ipcListener == nil {
+		return nil
+	}
+
+	return j.ipcListener.Close()
+}
+
 func (j *JSONRPC) setupHTTP() error {
 	j.logger.Info("http server started", "addr", j.config.Addr.String())
 
@@ -84,25 +163,58 @@ func (j *JSONRPC) setupHTTP() error {
 		return err
 	}
 
-	mux := http.DefaultServeMux
+	j.serve(lis, j.httpMux(), "http")
+
+	return nil
+}
+
+// httpMux returns the handlers of the HTTP listener, along with the WS one unless it has its own listener
+func (j *JSONRPC) httpMux() *http.ServeMux {
+	mux := http.NewServeMux()
 
 	// The middleware factory returns a handler, so we need to wrap the handler function properly.
-	jsonRPCHandler := http.HandlerFunc(j.handle)
+	jsonRPCHandler := jwtMiddleware(j.config.HTTPJWTSecret)(http.HandlerFunc(j.handle))
 	mux.Handle("/", middlewareFactory(j.config)(jsonRPCHandler))
 
-	mux.HandleFunc("/ws", j.handleWs)
+	if j.config.WSAddr == nil {
+		mux.Handle("/ws", jwtMiddleware(j.config.WSJWTSecret)(http.HandlerFunc(j.handleWs)))
+	}
+
+	return mux
+}
+
+// setupWS starts serving the WS connections on their own listener, on any path
+func (j *JSONRPC) setupWS() error {
+	j.logger.Info("ws server started", "addr", j.config.WSAddr.String())
 
+	lis, err := net.Listen("tcp", j.config.WSAddr.String())
+	if err != nil {
+		return err
+	}
+
+	j.serve(lis, j.wsMux(), "ws")
+
+	return nil
+}
+
+// wsMux returns the handler of the WS listener
+func (j *JSONRPC) wsMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("/", jwtMiddleware(j.config.WSJWTSecret)(http.HandlerFunc(j.handleWs)))
+
+	return mux
+}
+
+func (j *JSONRPC) serve(lis net.Listener, handler http.Handler, name string) {
 	srv := http.Server{
-		Handler: mux,
+		Handler: handler,
 	}
 
 	go func() {
 		if err := srv.Serve(lis); err != nil {
-			j.logger.Error("closed http connection", "err", err)
+			j.logger.Error(fmt.Sprintf("closed %s connection", name), "err", err)
 		}
 	}()
-
-	return nil
 }
 
 // The middlewareFactory builds a middleware which enables CORS using the provided config.
@@ -189,6 +301,11 @@ func (j *JSONRPC) handleWs(w http.ResponseWriter, req *http.Request) {
 		}
 	}(ws)
 
+	if limit := j.config.Limits.BodySize; limit > 0 {
+		ws.SetReadLimit(limit)
+	}
+
+	ctx := withNamespaces(withClientIP(req.Context(), remoteIP(req)), j.wsNamespaces)
 	wrapConn := &wsWrapper{ws: ws, logger: j.logger}
 
 	j.logger.Info("Websocket connection established")
@@ -214,7 +331,7 @@ func (j *JSONRPC) handleWs(w http.ResponseWriter, req *http.Request) {
 
 		if isSupportedWSType(msgType) {
 			go func() {
-				resp, handleErr := j.dispatcher.HandleWs(message, wrapConn)
+				resp, handleErr := j.dispatcher.HandleWs(ctx, message, wrapConn)
 				if handleErr != nil {
 					j.logger.Error(fmt.Sprintf("Unable to handle WS request, %s", handleErr.Error()))
 
@@ -256,7 +373,13 @@ func (j *JSONRPC) handle(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	data, err := ioutil.ReadAll(req.Body)
+	body := io.Reader(req.Body)
+	if limit := j.config.Limits.BodySize; limit > 0 {
+		// read one more byte to find out if the limit is exceeded
+		body = io.LimitReader(req.Body, limit+1)
+	}
+
+	data, err := ioutil.ReadAll(body)
 
 	if err != nil {
 		//nolint
@@ -265,10 +388,27 @@ func (j *JSONRPC) handle(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if limit := j.config.Limits.BodySize; limit > 0 && int64(len(data)) > limit {
+		resp, _ := NewRPCResponse(
+			nil,
+			"2.0",
+			nil,
+			NewLimitExceededError(fmt.Sprintf("request body too large, the limit is %d bytes", limit)),
+		).Bytes()
+
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		//nolint
+		w.Write(resp)
+
+		return
+	}
+
 	// log request
 	j.logger.Debug("handle", "request", string(data))
 
-	resp, err := j.dispatcher.Handle(data)
+	ctx := withNamespaces(withClientIP(req.Context(), remoteIP(req)), j.httpNamespaces)
+
+	resp, err := j.dispatcher.Handle(ctx, data)
 
 	if err != nil {
 		//nolint
@@ -280,3 +420,13 @@ func (j *JSONRPC) handle(w http.ResponseWriter, req *http.Request) {
 
 	j.logger.Debug("handle", "response", string(resp))
 }
+
+// remoteIP returns the IP of the client which sent the request
+func remoteIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+
+	return host
+}
@@ -1,11 +1,20 @@
 package jsonrpc
 
 import (
+	"encoding/json"
+	"github.com/juanidrobo/polygon-edge/helper/ipc"
 	"github.com/juanidrobo/polygon-edge/helper/tests"
+	"github.com/juanidrobo/polygon-edge/types"
 	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestHTTPServer(t *testing.T) {
@@ -26,3 +35,123 @@ func TestHTTPServer(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestIPCServer(t *testing.T) {
+	store := newMockStore()
+
+	// NewJSONRPC can't be used, as the HTTP handlers can only be registered once
+	srv := &JSONRPC{
+		logger:     hclog.NewNullLogger(),
+		config:     &Config{IPCPath: filepath.Join(t.TempDir(), "test.ipc")},
+		dispatcher: newDispatcher(hclog.NewNullLogger(), store, &dispatcherParams{chainID: 100}),
+	}
+
+	assert.NoError(t, srv.setupIPC())
+
+	t.Cleanup(func() {
+		assert.NoError(t, srv.Close())
+	})
+
+	conn, err := ipc.Dial(srv.config.IPCPath)
+	assert.NoError(t, err)
+
+	defer conn.Close()
+
+	decoder := json.NewDecoder(conn)
+
+	readResponse := func() json.RawMessage {
+		t.Helper()
+
+		var resp json.RawMessage
+
+		assert.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+		assert.NoError(t, decoder.Decode(&resp))
+
+		return resp
+	}
+
+	t.Run("single request", func(t *testing.T) {
+		_, err := conn.Write([]byte(`{"jsonrpc":"2.0","id":1,"method":"eth_chainId","params":[]}`))
+		assert.NoError(t, err)
+
+		var chainID string
+
+		assert.NoError(t, expectJSONResult(readResponse(), &chainID))
+		assert.Equal(t, "0x64", chainID)
+	})
+
+	t.Run("batch request", func(t *testing.T) {
+		_, err := conn.Write([]byte(`[
+			{"jsonrpc":"2.0","id":1,"method":"eth_chainId","params":[]},
+			{"jsonrpc":"2.0","id":2,"method":"eth_chainId","params":[]}
+		]`))
+		assert.NoError(t, err)
+
+		var responses []SuccessResponse
+
+		assert.NoError(t, expectBatchJSONResult(readResponse(), &responses))
+		assert.Len(t, responses, 2)
+	})
+
+	t.Run("subscription", func(t *testing.T) {
+		_, err := conn.Write([]byte(`{"jsonrpc":"2.0","id":1,"method":"eth_subscribe","params":["newHeads"]}`))
+		assert.NoError(t, err)
+
+		var subscriptionID string
+
+		assert.NoError(t, expectJSONResult(readResponse(), &subscriptionID))
+
+		store.emitEvent(&mockEvent{
+			NewChain: []*mockHeader{
+				{
+					header: &types.Header{
+						Hash: types.StringToHash("1"),
+					},
+				},
+			},
+		})
+
+		var notification struct {
+			Method string `json:"method"`
+			Params struct {
+				Subscription string `json:"subscription"`
+			} `json:"params"`
+		}
+
+		assert.NoError(t, json.Unmarshal(readResponse(), &notification))
+		assert.Equal(t, "eth_subscription", notification.Method)
+		assert.Equal(t, subscriptionID, notification.Params.Subscription)
+	})
+}
+
+func TestHTTPBodySizeLimit(t *testing.T) {
+	srv := &JSONRPC{
+		logger:     hclog.NewNullLogger(),
+		config:     &Config{Limits: Limits{BodySize: 80}},
+		dispatcher: newDispatcher(hclog.NewNullLogger(), newMockStore(), &dispatcherParams{chainID: 100}),
+	}
+
+	post := func(body string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		recorder := httptest.NewRecorder()
+		srv.handle(recorder, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+
+		return recorder
+	}
+
+	resp := post(`{"jsonrpc":"2.0","id":1,"method":"eth_chainId","params":[]}`)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var chainID string
+
+	assert.NoError(t, expectJSONResult(resp.Body.Bytes(), &chainID))
+
+	resp = post(`{"jsonrpc":"2.0","id":1,"method":"eth_chainId","params":[],"padding":"` + strings.Repeat("0", 64) + `"}`)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.Code)
+
+	var res ErrorResponse
+
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &res))
+	assert.Equal(t, -32005, res.Error.Code)
+}
@@ -0,0 +1,131 @@
+package jsonrpc
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// JWTSecretLength is the required length of the JWT shared secret, in bytes
+	JWTSecretLength = 32
+
+	// jwtIssuedAtWindow is the maximum drift of the token issued at time from the local time
+	jwtIssuedAtWindow = 60 * time.Second
+)
+
+var (
+	errMissingJWT         = errors.New("missing token")
+	errInvalidJWT         = errors.New("invalid token")
+	errJWTAlgorithm       = errors.New("unsupported token algorithm, expected HS256")
+	errJWTSignature       = errors.New("invalid token signature")
+	errJWTIssuedAt        = errors.New("token issued at time is too far from the local time")
+	errJWTExpired         = errors.New("token is expired")
+	errJWTMissingIssuedAt = errors.New("token is missing the issued at claim")
+)
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+}
+
+type jwtClaims struct {
+	IssuedAt  *int64 `json:"iat"`
+	ExpiresAt *int64 `json:"exp"`
+}
+
+// verifyJWT verifies the HS256 bearer token of the Authorization header.
+// The token must be signed with the shared secret, and issued within a minute of the local time
+func verifyJWT(secret []byte, authHeader string, now time.Time) error {
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+	if token == "" || token == authHeader {
+		return errMissingJWT
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return errInvalidJWT
+	}
+
+	var header jwtHeader
+	if err := decodeJWTSegment(parts[0], &header); err != nil {
+		return err
+	}
+
+	if header.Alg != "HS256" {
+		return errJWTAlgorithm
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return errInvalidJWT
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return errJWTSignature
+	}
+
+	var claims jwtClaims
+	if err := decodeJWTSegment(parts[1], &claims); err != nil {
+		return err
+	}
+
+	if claims.IssuedAt == nil {
+		return errJWTMissingIssuedAt
+	}
+
+	issuedAt := time.Unix(*claims.IssuedAt, 0)
+	if issuedAt.Before(now.Add(-jwtIssuedAtWindow)) || issuedAt.After(now.Add(jwtIssuedAtWindow)) {
+		return errJWTIssuedAt
+	}
+
+	if claims.ExpiresAt != nil && !now.Before(time.Unix(*claims.ExpiresAt, 0)) {
+		return errJWTExpired
+	}
+
+	return nil
+}
+
+// decodeJWTSegment decodes a base64url encoded JSON segment of a token
+func decodeJWTSegment(segment string, v interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return errInvalidJWT
+	}
+
+	if err := json.Unmarshal(raw, v); err != nil {
+		return errInvalidJWT
+	}
+
+	return nil
+}
+
+// jwtMiddleware rejects the requests without a valid token, if a secret is configured.
+// CORS preflight requests are never authenticated, as browsers don't send credentials with them
+func jwtMiddleware(secret []byte) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if len(secret) == 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodOptions {
+				if err := verifyJWT(secret, r.Header.Get("Authorization"), time.Now()); err != nil {
+					http.Error(w, err.Error(), http.StatusUnauthorized)
+
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
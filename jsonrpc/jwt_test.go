@@ -0,0 +1,215 @@
+package jsonrpc
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+var testJWTSecret = []byte("0123456789abcdef0123456789abcdef")
+
+// newTestJWT builds a token with the given header and claims, signed by the secret
+func newTestJWT(secret []byte, header, claims string) string {
+	encode := base64.RawURLEncoding.EncodeToString
+
+	unsigned := encode([]byte(header)) + "." + encode([]byte(claims))
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(unsigned))
+
+	return unsigned + "." + encode(mac.Sum(nil))
+}
+
+func TestVerifyJWT(t *testing.T) {
+	now := time.Unix(1_000_000, 0)
+	header := `{"alg":"HS256","typ":"JWT"}`
+
+	iat := func(offset time.Duration) string {
+		return `{"iat":` + strconv.FormatInt(now.Add(offset).Unix(), 10) + `}`
+	}
+
+	cases := []struct {
+		name string
+		auth string
+		err  error
+	}{
+		{
+			"valid token",
+			"Bearer " + newTestJWT(testJWTSecret, header, iat(-10*time.Second)),
+			nil,
+		},
+		{
+			"valid token with expiration",
+			"Bearer " + newTestJWT(
+				testJWTSecret,
+				header,
+				`{"iat":`+strconv.FormatInt(now.Unix(), 10)+`,"exp":`+strconv.FormatInt(now.Unix()+10, 10)+`}`,
+			),
+			nil,
+		},
+		{
+			"missing token",
+			"",
+			errMissingJWT,
+		},
+		{
+			"not a bearer token",
+			newTestJWT(testJWTSecret, header, iat(0)),
+			errMissingJWT,
+		},
+		{
+			"malformed token",
+			"Bearer abc.def",
+			errInvalidJWT,
+		},
+		{
+			"unsupported algorithm",
+			"Bearer " + newTestJWT(testJWTSecret, `{"alg":"none"}`, iat(0)),
+			errJWTAlgorithm,
+		},
+		{
+			"wrong secret",
+			"Bearer " + newTestJWT([]byte("secret"), header, iat(0)),
+			errJWTSignature,
+		},
+		{
+			"missing issued at",
+			"Bearer " + newTestJWT(testJWTSecret, header, `{}`),
+			errJWTMissingIssuedAt,
+		},
+		{
+			"stale token",
+			"Bearer " + newTestJWT(testJWTSecret, header, iat(-2*time.Minute)),
+			errJWTIssuedAt,
+		},
+		{
+			"token from the future",
+			"Bearer " + newTestJWT(testJWTSecret, header, iat(2*time.Minute)),
+			errJWTIssuedAt,
+		},
+		{
+			"expired token",
+			"Bearer " + newTestJWT(
+				testJWTSecret,
+				header,
+				`{"iat":`+strconv.FormatInt(now.Unix(), 10)+`,"exp":`+strconv.FormatInt(now.Unix(), 10)+`}`,
+			),
+			errJWTExpired,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.ErrorIs(t, verifyJWT(testJWTSecret, c.auth, now), c.err)
+		})
+	}
+}
+
+func TestJWTMiddleware(t *testing.T) {
+	handler := jwtMiddleware(testJWTSecret)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func(method, auth string) int {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "/", nil)
+
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+
+		handler.ServeHTTP(recorder, req)
+
+		return recorder.Code
+	}
+
+	token := newTestJWT(
+		testJWTSecret,
+		`{"alg":"HS256","typ":"JWT"}`,
+		`{"iat":`+strconv.FormatInt(time.Now().Unix(), 10)+`}`,
+	)
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "Bearer "+token))
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodPost, ""))
+
+	// CORS preflight requests are not authenticated
+	assert.Equal(t, http.StatusOK, serve(http.MethodOptions, ""))
+}
+
+func TestJWTPerListener(t *testing.T) {
+	dispatcher := newDispatcher(hclog.NewNullLogger(), newMockStore(), &dispatcherParams{chainID: 100})
+
+	// the public HTTP listener serves eth without authentication, the WS one requires tokens
+	srv := &JSONRPC{
+		logger: hclog.NewNullLogger(),
+		config: &Config{
+			WSAddr:      &net.TCPAddr{},
+			WSJWTSecret: testJWTSecret,
+		},
+		dispatcher: dispatcher,
+	}
+
+	httpServer := httptest.NewServer(srv.httpMux())
+	defer httpServer.Close()
+
+	wsServer := httptest.NewServer(srv.wsMux())
+	defer wsServer.Close()
+
+	t.Run("the HTTP listener answers unauthenticated requests", func(t *testing.T) {
+		resp, err := http.Post(
+			httpServer.URL,
+			"application/json",
+			strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"eth_chainId","params":[]}`),
+		)
+		assert.NoError(t, err)
+
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	wsURL := "ws" + strings.TrimPrefix(wsServer.URL, "http")
+
+	t.Run("the WS listener rejects unauthenticated connections", func(t *testing.T) {
+		_, resp, err := websocket.DefaultDialer.Dial(wsURL, nil)
+		assert.ErrorIs(t, err, websocket.ErrBadHandshake)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("the WS listener accepts authenticated connections", func(t *testing.T) {
+		token := newTestJWT(
+			testJWTSecret,
+			`{"alg":"HS256","typ":"JWT"}`,
+			`{"iat":`+strconv.FormatInt(time.Now().Unix(), 10)+`}`,
+		)
+
+		conn, _, err := websocket.DefaultDialer.Dial(wsURL, http.Header{"Authorization": {"Bearer " + token}})
+		assert.NoError(t, err)
+
+		defer conn.Close()
+
+		assert.NoError(t, conn.WriteMessage(
+			websocket.TextMessage,
+			[]byte(`{"jsonrpc":"2.0","id":1,"method":"eth_chainId","params":[]}`),
+		))
+
+		_, msg, err := conn.ReadMessage()
+		assert.NoError(t, err)
+
+		var chainID string
+
+		assert.NoError(t, expectJSONResult(msg, &chainID))
+		assert.Equal(t, "0x64", chainID)
+	})
+}
@@ -0,0 +1,150 @@
+package jsonrpc
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// DefaultBatchLengthLimit is the default maximum number of requests in a batch
+	DefaultBatchLengthLimit uint64 = 100
+
+	// DefaultBodySizeLimit is the default maximum size of a request, in bytes
+	DefaultBodySizeLimit int64 = 5 * 1024 * 1024
+
+	// DefaultBlockRangeLimit is the default maximum block range queried by eth_getLogs
+	DefaultBlockRangeLimit uint64 = 1000
+
+	// DefaultCallTimeout is the default execution timeout of eth_call, eth_estimateGas and eth_createAccessList
+	DefaultCallTimeout = 5 * time.Second
+
+	// minClientLimiterTTL is the minimum time the rate limiter of an idle client is kept for
+	minClientLimiterTTL = time.Minute
+)
+
+// Limits is the policy of the limits enforced on the JSON-RPC requests.
+// The zero value of a limit disables it
+type Limits struct {
+	BatchLength      uint64                   // the maximum number of requests in a batch
+	BodySize         int64                    // the maximum size of a request (a WS / IPC message), in bytes
+	BlockRange       uint64                   // the maximum block range queried by eth_getLogs
+	MethodTimeouts   map[string]time.Duration // the execution timeout of the methods (i.e. eth_call)
+	IPRateLimit      float64                  // the maximum number of requests per second served to a client IP
+	MethodRateLimits map[string]float64       // the maximum number of requests per second served for a method
+}
+
+// clientIPKey is the context key of the IP of the client which sent the request
+type clientIPKey struct{}
+
+// withClientIP returns a copy of the context carrying the IP of the client
+func withClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// clientIPFromContext returns the IP of the client, empty for the local (IPC) clients
+func clientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+
+	return ip
+}
+
+// clientLimiter is the rate limiter of a client IP
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// rateLimiter throttles the requests using token buckets, per client IP and per method.
+// The method limits are shared by all the clients, while the local (IPC) clients are
+// subject only to the method limits
+type rateLimiter struct {
+	ipLimit rate.Limit
+	ipBurst int
+
+	// the limiters of the idle clients are dropped after the ttl,
+	// once their buckets would have been refilled anyway
+	ttl       time.Duration
+	lastSweep time.Time
+
+	lock    sync.Mutex
+	clients map[string]*clientLimiter // client IP -> limiter
+
+	methods map[string]*rate.Limiter // method -> limiter
+}
+
+func newRateLimiter(ipLimit float64, methodLimits map[string]float64) *rateLimiter {
+	r := &rateLimiter{
+		clients: make(map[string]*clientLimiter),
+		methods: make(map[string]*rate.Limiter, len(methodLimits)),
+	}
+
+	if ipLimit > 0 {
+		r.ipLimit = rate.Limit(ipLimit)
+		r.ipBurst = rateLimitBurst(ipLimit)
+
+		r.ttl = time.Duration(float64(r.ipBurst) / ipLimit * float64(time.Second))
+		if r.ttl < minClientLimiterTTL {
+			r.ttl = minClientLimiterTTL
+		}
+	}
+
+	for method, limit := range methodLimits {
+		if limit > 0 {
+			r.methods[method] = rate.NewLimiter(rate.Limit(limit), rateLimitBurst(limit))
+		}
+	}
+
+	return r
+}
+
+// rateLimitBurst allows bursts of up to one second worth of requests
+func rateLimitBurst(limit float64) int {
+	return int(math.Max(1, math.Ceil(limit)))
+}
+
+// allow consumes a token for the request of the client, returning an error if it is throttled
+func (r *rateLimiter) allow(ip, method string) Error {
+	if ip != "" && r.ipLimit > 0 && !r.clientLimiter(ip).Allow() {
+		return NewLimitExceededError("rate limit exceeded for " + ip)
+	}
+
+	if limiter, ok := r.methods[method]; ok && !limiter.Allow() {
+		return NewLimitExceededError("rate limit exceeded for " + method)
+	}
+
+	return nil
+}
+
+// clientLimiter returns the limiter of the client IP, dropping the ones of the idle clients
+func (r *rateLimiter) clientLimiter(ip string) *rate.Limiter {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := time.Now()
+
+	if now.Sub(r.lastSweep) > r.ttl {
+		for clientIP, client := range r.clients {
+			if now.Sub(client.lastSeen) > r.ttl {
+				delete(r.clients, clientIP)
+			}
+		}
+
+		r.lastSweep = now
+	}
+
+	client, ok := r.clients[ip]
+	if !ok {
+		client = &clientLimiter{
+			limiter: rate.NewLimiter(r.ipLimit, r.ipBurst),
+		}
+		r.clients[ip] = client
+	}
+
+	client.lastSeen = now
+
+	return client.limiter
+}
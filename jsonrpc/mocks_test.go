@@ -3,7 +3,9 @@ package jsonrpc
 import (
 	"errors"
 	"github.com/juanidrobo/polygon-edge/blockchain"
+	"github.com/juanidrobo/polygon-edge/helper/progress"
 	"github.com/juanidrobo/polygon-edge/state"
+	"github.com/juanidrobo/polygon-edge/txpool/proto"
 	"github.com/juanidrobo/polygon-edge/types"
 	"math/big"
 	"sync"
@@ -52,13 +54,21 @@ type mockStore struct {
 	receiptsLock sync.Mutex
 	receipts     map[types.Hash][]*types.Receipt
 	accounts     map[types.Address]*state.Account
+
+	txPoolEventCh   chan *proto.TxPoolEvent
+	pendingTxsLock  sync.Mutex
+	pendingTxs      map[types.Hash]*types.Transaction
+	progressionLock sync.Mutex
+	progression     *progress.Progression
 }
 
 func newMockStore() *mockStore {
 	return &mockStore{
-		header:       &types.Header{Number: 0},
-		subscription: blockchain.NewMockSubscription(),
-		accounts:     map[types.Address]*state.Account{},
+		header:        &types.Header{Number: 0},
+		subscription:  blockchain.NewMockSubscription(),
+		accounts:      map[types.Address]*state.Account{},
+		txPoolEventCh: make(chan *proto.TxPoolEvent),
+		pendingTxs:    map[types.Hash]*types.Transaction{},
 	}
 }
 
@@ -114,6 +124,44 @@ func (m *mockStore) SubscribeEvents() blockchain.Subscription {
 	return m.subscription
 }
 
+func (m *mockStore) TxPoolSubscribe(request *proto.SubscribeRequest) (<-chan *proto.TxPoolEvent, func()) {
+	return m.txPoolEventCh, func() {}
+}
+
+func (m *mockStore) GetPendingTx(txHash types.Hash) (*types.Transaction, bool) {
+	m.pendingTxsLock.Lock()
+	defer m.pendingTxsLock.Unlock()
+
+	tx, ok := m.pendingTxs[txHash]
+
+	return tx, ok
+}
+
+func (m *mockStore) addPendingTx(tx *types.Transaction) {
+	m.pendingTxsLock.Lock()
+	m.pendingTxs[tx.Hash] = tx
+	m.pendingTxsLock.Unlock()
+
+	m.txPoolEventCh <- &proto.TxPoolEvent{
+		Type:   proto.EventType_PROMOTED,
+		TxHash: tx.Hash.String(),
+	}
+}
+
+func (m *mockStore) GetSyncProgression() *progress.Progression {
+	m.progressionLock.Lock()
+	defer m.progressionLock.Unlock()
+
+	return m.progression
+}
+
+func (m *mockStore) setSyncProgression(progression *progress.Progression) {
+	m.progressionLock.Lock()
+	defer m.progressionLock.Unlock()
+
+	m.progression = progression
+}
+
 func (m *mockStore) GetBlockByNumber(num uint64, full bool) (*types.Block, bool) {
 	return nil, false
 }
@@ -171,6 +171,46 @@ type receipt struct {
 	ToAddr            *types.Address `json:"to"`
 }
 
+// toReceipt returns the receipt of the txIndex-th transaction of the block.
+// The index of its first log within the block is given by logIndex
+func toReceipt(raw *types.Receipt, txn *types.Transaction, txIndex uint64, header *types.Header, logIndex uint64) *receipt {
+	logs := make([]*Log, len(raw.Logs))
+	for indx, elem := range raw.Logs {
+		logs[indx] = &Log{
+			Address:     elem.Address,
+			Topics:      elem.Topics,
+			Data:        argBytes(elem.Data),
+			BlockHash:   header.Hash,
+			BlockNumber: argUint64(header.Number),
+			TxHash:      txn.Hash,
+			TxIndex:     argUint64(txIndex),
+			LogIndex:    argUint64(logIndex + uint64(indx)),
+			Removed:     false,
+		}
+	}
+
+	res := &receipt{
+		Root:              raw.Root,
+		CumulativeGasUsed: argUint64(raw.CumulativeGasUsed),
+		LogsBloom:         raw.LogsBloom,
+		TxHash:            txn.Hash,
+		TxIndex:           argUint64(txIndex),
+		BlockHash:         header.Hash,
+		BlockNumber:       argUint64(header.Number),
+		GasUsed:           argUint64(raw.GasUsed),
+		ContractAddress:   raw.ContractAddress,
+		FromAddr:          txn.From,
+		ToAddr:            txn.To,
+		Logs:              logs,
+	}
+
+	if raw.Status != nil {
+		res.Status = argUint64(*raw.Status)
+	}
+
+	return res
+}
+
 type Log struct {
 	Address     types.Address `json:"address"`
 	Topics      []types.Hash  `json:"topics"`
@@ -302,6 +342,9 @@ type txnArgs struct {
 	Data     *argBytes
 	Input    *argBytes
 	Nonce    *argUint64
+
+	// AccessList is the initial access list of eth_createAccessList
+	AccessList *types.AccessList
 }
 
 type progression struct {
@@ -310,3 +353,101 @@ type progression struct {
 	CurrentBlock  string `json:"currentBlock"`
 	HighestBlock  string `json:"highestBlock"`
 }
+
+// feeHistory is the result of eth_feeHistory
+type feeHistory struct {
+	OldestBlock   argUint64  `json:"oldestBlock"`
+	BaseFeePerGas []argBig   `json:"baseFeePerGas"`
+	GasUsedRatio  []float64  `json:"gasUsedRatio"`
+	Reward        [][]argBig `json:"reward,omitempty"`
+}
+
+// accessListResult is the result of eth_createAccessList
+type accessListResult struct {
+	AccessList types.AccessList `json:"accessList"`
+	GasUsed    argUint64        `json:"gasUsed"`
+	Error      string           `json:"error,omitempty"`
+}
+
+// syncStatus is the notification of the syncing subscription, while the node is syncing
+type syncStatus struct {
+	Syncing bool        `json:"syncing"`
+	Status  progression `json:"status"`
+}
+
+// overrideAccount is the set of account fields overridden for a call
+type overrideAccount struct {
+	Nonce     *argUint64                 `json:"nonce"`
+	Code      *argBytes                  `json:"code"`
+	Balance   *argBig                    `json:"balance"`
+	State     *map[types.Hash]types.Hash `json:"state"`
+	StateDiff *map[types.Hash]types.Hash `json:"stateDiff"`
+}
+
+// stateOverride is the optional state override parameter of eth_call and eth_estimateGas
+type stateOverride map[types.Address]overrideAccount
+
+func (s *stateOverride) toStateOverride() types.StateOverride {
+	if s == nil {
+		return nil
+	}
+
+	override := types.StateOverride{}
+
+	for addr, account := range *s {
+		overrideAccount := types.OverrideAccount{}
+
+		if account.Nonce != nil {
+			nonce := uint64(*account.Nonce)
+			overrideAccount.Nonce = &nonce
+		}
+
+		if account.Code != nil {
+			overrideAccount.Code = *account.Code
+		}
+
+		if account.Balance != nil {
+			overrideAccount.Balance = new(big.Int).Set((*big.Int)(account.Balance))
+		}
+
+		if account.State != nil {
+			overrideAccount.State = *account.State
+		}
+
+		if account.StateDiff != nil {
+			overrideAccount.StateDiff = *account.StateDiff
+		}
+
+		override[addr] = overrideAccount
+	}
+
+	return override
+}
+
+// blockOverride is the optional block context override parameter of eth_call and eth_estimateGas
+type blockOverride struct {
+	Number     *argUint64     `json:"number"`
+	Time       *argUint64     `json:"time"`
+	GasLimit   *argUint64     `json:"gasLimit"`
+	Coinbase   *types.Address `json:"coinbase"`
+	Difficulty *argBig        `json:"difficulty"`
+}
+
+func (b *blockOverride) toBlockOverride() *types.BlockOverride {
+	if b == nil {
+		return nil
+	}
+
+	override := &types.BlockOverride{
+		Number:    (*uint64)(b.Number),
+		Timestamp: (*uint64)(b.Time),
+		GasLimit:  (*uint64)(b.GasLimit),
+		Coinbase:  b.Coinbase,
+	}
+
+	if b.Difficulty != nil {
+		override.Difficulty = new(big.Int).Set((*big.Int)(b.Difficulty))
+	}
+
+	return override
+}
@@ -1,6 +1,7 @@
 package jsonrpc
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -11,9 +12,9 @@ import (
 )
 
 func TestWeb3EndpointSha3(t *testing.T) {
-	dispatcher := newDispatcher(hclog.NewNullLogger(), newMockStore(), 0)
+	dispatcher := newDispatcher(hclog.NewNullLogger(), newMockStore(), &dispatcherParams{})
 
-	resp, err := dispatcher.Handle([]byte(`{
+	resp, err := dispatcher.Handle(context.Background(), []byte(`{
 		"method": "web3_sha3",
 		"params": ["0x68656c6c6f20776f726c64"]
 	}`))
@@ -26,9 +27,9 @@ func TestWeb3EndpointSha3(t *testing.T) {
 }
 
 func TestWeb3EndpointClientVersion(t *testing.T) {
-	dispatcher := newDispatcher(hclog.NewNullLogger(), newMockStore(), 0)
+	dispatcher := newDispatcher(hclog.NewNullLogger(), newMockStore(), &dispatcherParams{})
 
-	resp, err := dispatcher.Handle([]byte(`{
+	resp, err := dispatcher.Handle(context.Background(), []byte(`{
 		"method": "web3_clientVersion",
 		"params": []
 	}`))
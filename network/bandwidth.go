@@ -0,0 +1,83 @@
+package network
+
+import (
+	"sync"
+
+	libp2pMetrics "github.com/libp2p/go-libp2p-core/metrics"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+)
+
+const (
+	directionIn  = "in"
+	directionOut = "out"
+)
+
+// bandwidthReporter keeps track of the libp2p bandwidth usage, and reports the stream traffic
+// per protocol and per connected peer to the network metrics. The series of a peer are deleted
+// once it disconnects, as they would otherwise grow with every peer ever connected
+type bandwidthReporter struct {
+	*libp2pMetrics.BandwidthCounter
+
+	metrics *Metrics
+
+	// the connected peers, whose traffic is reported
+	peers     map[peer.ID]struct{}
+	peersLock sync.RWMutex
+}
+
+func newBandwidthReporter(metrics *Metrics) *bandwidthReporter {
+	return &bandwidthReporter{
+		BandwidthCounter: libp2pMetrics.NewBandwidthCounter(),
+		metrics:          metrics,
+		peers:            make(map[peer.ID]struct{}),
+	}
+}
+
+// LogSentMessageStream implements the libp2p metrics.Reporter interface
+func (r *bandwidthReporter) LogSentMessageStream(size int64, proto protocol.ID, p peer.ID) {
+	r.BandwidthCounter.LogSentMessageStream(size, proto, p)
+	r.report(size, proto, p, directionOut)
+}
+
+// LogRecvMessageStream implements the libp2p metrics.Reporter interface
+func (r *bandwidthReporter) LogRecvMessageStream(size int64, proto protocol.ID, p peer.ID) {
+	r.BandwidthCounter.LogRecvMessageStream(size, proto, p)
+	r.report(size, proto, p, directionIn)
+}
+
+func (r *bandwidthReporter) report(size int64, proto protocol.ID, p peer.ID, direction string) {
+	r.metrics.ProtocolBytes.With("protocol", string(proto), "direction", direction).Add(float64(size))
+
+	r.peersLock.RLock()
+	defer r.peersLock.RUnlock()
+
+	if _, ok := r.peers[p]; ok {
+		r.metrics.PeerBytes.With("peer", p.String(), "direction", direction).Add(float64(size))
+	}
+}
+
+// peerConnected starts reporting the traffic of the peer
+func (r *bandwidthReporter) peerConnected(p peer.ID) {
+	r.peersLock.Lock()
+	defer r.peersLock.Unlock()
+
+	r.peers[p] = struct{}{}
+}
+
+// peerDisconnected stops reporting the traffic of the peer, and deletes its series
+func (r *bandwidthReporter) peerDisconnected(p peer.ID) {
+	r.peersLock.Lock()
+	defer r.peersLock.Unlock()
+
+	delete(r.peers, p)
+
+	for _, direction := range []string{directionIn, directionOut} {
+		r.metrics.PeerBytes.Delete("peer", p.String(), "direction", direction)
+	}
+}
+
+// GetPeerBandwidth returns the traffic of the streams with the peer
+func (s *Server) GetPeerBandwidth(peerID peer.ID) libp2pMetrics.Stats {
+	return s.bandwidth.GetBandwidthForPeer(peerID)
+}
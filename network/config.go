@@ -3,6 +3,7 @@ package network
 import (
 	"github.com/juanidrobo/polygon-edge/chain"
 	"github.com/juanidrobo/polygon-edge/secrets"
+	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/multiformats/go-multiaddr"
 	"net"
 )
@@ -13,6 +14,10 @@ type Config struct {
 	Addr             *net.TCPAddr           // the base address
 	NatAddr          net.IP                 // the NAT address
 	DNS              multiaddr.Multiaddr    // the DNS address
+	NATPortMap       bool                   // flag indicating if the port should be mapped on the NAT device (UPnP / NAT-PMP)
+	NATService       bool                   // flag indicating if the node should help peers detect their reachability (AutoNAT)
+	Relays           []*peer.AddrInfo       // the relays used to stay reachable when behind a NAT
+	RelayService     bool                   // flag indicating if the node should relay for unreachable peers
 	DataDir          string                 // the base data directory for the client
 	MaxPeers         int64                  // the maximum number of peer connections
 	MaxInboundPeers  int64                  // the maximum number of inbound peer connections
@@ -20,6 +25,7 @@ type Config struct {
 	Chain            *chain.Chain           // the reference to the chain configuration
 	SecretsManager   secrets.SecretsManager // the secrets manager used for key storage
 	Metrics          *Metrics               // the metrics reporting reference
+	RateLimits       map[string]float64     // the maximum number of requests per second served, per gRPC method
 }
 
 func DefaultConfig() *Config {
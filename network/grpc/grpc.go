@@ -3,16 +3,21 @@ package grpc
 import (
 	"context"
 	"errors"
+	"fmt"
 	"google.golang.org/grpc/credentials/insecure"
 	"io"
+	"math"
 	"net"
 
 	manet "github.com/multiformats/go-multiaddr/net"
 
 	"github.com/libp2p/go-libp2p-core/network"
 	"github.com/libp2p/go-libp2p-core/peer"
+	"golang.org/x/time/rate"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	grpcPeer "google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 )
 
 type GrpcStream struct {
@@ -20,13 +25,41 @@ type GrpcStream struct {
 	streamCh chan network.Stream
 
 	grpcServer *grpc.Server
+
+	rateLimits map[string]*rate.Limiter // full method name -> limiter
 }
 
 func NewGrpcStream() *GrpcStream {
-	return &GrpcStream{
-		ctx:        context.Background(),
-		streamCh:   make(chan network.Stream),
-		grpcServer: grpc.NewServer(grpc.UnaryInterceptor(interceptor)),
+	g := &GrpcStream{
+		ctx:      context.Background(),
+		streamCh: make(chan network.Stream),
+	}
+
+	g.grpcServer = grpc.NewServer(grpc.UnaryInterceptor(g.interceptor))
+
+	return g
+}
+
+// SetRateLimits sets the maximum number of requests per second served for the methods
+// of the registered services, keyed by the full method name (e.g. /v1.V1/GetHeaders).
+// Methods of other services are ignored. It must be called before the stream is registered
+func (g *GrpcStream) SetRateLimits(limits map[string]float64) {
+	g.rateLimits = make(map[string]*rate.Limiter)
+
+	for service, info := range g.grpcServer.GetServiceInfo() {
+		for _, method := range info.Methods {
+			fullMethod := fmt.Sprintf("/%s/%s", service, method.Name)
+
+			limit, ok := limits[fullMethod]
+			if !ok {
+				continue
+			}
+
+			// allow bursts of up to one second worth of requests
+			burst := int(math.Max(1, math.Ceil(limit)))
+
+			g.rateLimits[fullMethod] = rate.NewLimiter(rate.Limit(limit), burst)
+		}
 	}
 }
 
@@ -35,14 +68,21 @@ type Context struct {
 	PeerID peer.ID
 }
 
-// interceptor is the middleware function that wraps
-// gRPC peer data to custom Polygon Edge structures
-func interceptor(
+// interceptor is the middleware function that throttles the rate limited methods,
+// and wraps gRPC peer data to custom Polygon Edge structures
+func (g *GrpcStream) interceptor(
 	ctx context.Context,
 	req interface{},
-	_ *grpc.UnaryServerInfo,
+	info *grpc.UnaryServerInfo,
 	handler grpc.UnaryHandler,
 ) (interface{}, error) {
+	if limiter, ok := g.rateLimits[info.FullMethod]; ok {
+		// wait for the request turn, unless it would exceed the request deadline
+		if err := limiter.Wait(ctx); err != nil {
+			return nil, status.Errorf(codes.ResourceExhausted, "rate limit exceeded for %s: %v", info.FullMethod, err)
+		}
+	}
+
 	// Grab the peer info from the connection
 	contextPeer, ok := grpcPeer.FromContext(ctx)
 	if !ok {
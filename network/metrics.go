@@ -23,8 +23,54 @@ type Metrics struct {
 
 	// Number of pending inbound connections
 	PendingInboundConnectionsCount metrics.Gauge
+
+	// Reachability of the node detected by AutoNAT
+	Reachability metrics.Gauge
+
+	// Number of bytes sent and received per protocol
+	ProtocolBytes metrics.Counter
+
+	// Number of bytes sent and received per connected peer
+	PeerBytes PeerGauge
+}
+
+// PeerGauge is a gauge with series per connected peer. The series of a peer
+// are deleted once it disconnects, so that their number stays bounded
+type PeerGauge interface {
+	With(labelValues ...string) metrics.Gauge
+	Delete(labelValues ...string)
 }
 
+// prometheusPeerGauge is a PeerGauge backed by a prometheus gauge vector
+type prometheusPeerGauge struct {
+	gv               *stdprometheus.GaugeVec
+	labelsWithValues []string
+}
+
+func (g *prometheusPeerGauge) With(labelValues ...string) metrics.Gauge {
+	return prometheus.NewGauge(g.gv).With(append(append([]string{}, g.labelsWithValues...), labelValues...)...)
+}
+
+func (g *prometheusPeerGauge) Delete(labelValues ...string) {
+	labelsWithValues := append(append([]string{}, g.labelsWithValues...), labelValues...)
+	labels := stdprometheus.Labels{}
+
+	for i := 0; i+1 < len(labelsWithValues); i += 2 {
+		labels[labelsWithValues[i]] = labelsWithValues[i+1]
+	}
+
+	g.gv.Delete(labels)
+}
+
+// discardPeerGauge is a PeerGauge which discards the values
+type discardPeerGauge struct{}
+
+func (discardPeerGauge) With(...string) metrics.Gauge {
+	return discard.NewGauge()
+}
+
+func (discardPeerGauge) Delete(...string) {}
+
 // GetPrometheusMetrics return the network metrics instance
 func GetPrometheusMetrics(namespace string, labelsWithValues ...string) *Metrics {
 	labels := []string{}
@@ -68,6 +114,44 @@ func GetPrometheusMetrics(namespace string, labelsWithValues ...string) *Metrics
 			Name:      "pending_inbound_connections_count",
 			Help:      "Number of pending inbound connections",
 		}, labels).With(labelsWithValues...),
+
+		Reachability: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "network",
+			Name:      "reachability",
+			Help:      "Reachability of the node detected by AutoNAT (0 = unknown, 1 = public, 2 = private)",
+		}, labels).With(labelsWithValues...),
+
+		ProtocolBytes: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "network",
+			Name:      "protocol_bytes",
+			Help:      "Number of bytes sent and received per protocol",
+		}, append(append([]string{}, labels...), "protocol", "direction")).With(labelsWithValues...),
+
+		PeerBytes: newPrometheusPeerGauge(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "network",
+			Name:      "peer_bytes",
+			Help:      "Number of bytes sent and received per connected peer, since it connected",
+		}, labelsWithValues...),
+	}
+}
+
+// newPrometheusPeerGauge registers a gauge vector with the given labels, and the peer and direction ones
+func newPrometheusPeerGauge(opts stdprometheus.GaugeOpts, labelsWithValues ...string) *prometheusPeerGauge {
+	labels := []string{}
+
+	for i := 0; i < len(labelsWithValues); i += 2 {
+		labels = append(labels, labelsWithValues[i])
+	}
+
+	gv := stdprometheus.NewGaugeVec(opts, append(labels, "peer", "direction"))
+	stdprometheus.MustRegister(gv)
+
+	return &prometheusPeerGauge{
+		gv:               gv,
+		labelsWithValues: labelsWithValues,
 	}
 }
 
@@ -79,5 +163,8 @@ func NilMetrics() *Metrics {
 		InboundConnectionsCount:         discard.NewGauge(),
 		PendingOutboundConnectionsCount: discard.NewGauge(),
 		PendingInboundConnectionsCount:  discard.NewGauge(),
+		Reachability:                    discard.NewGauge(),
+		ProtocolBytes:                   discard.NewCounter(),
+		PeerBytes:                       discardPeerGauge{},
 	}
 }
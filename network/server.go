@@ -7,6 +7,7 @@ import (
 	"github.com/juanidrobo/polygon-edge/network/common"
 	"github.com/juanidrobo/polygon-edge/network/dial"
 	"github.com/juanidrobo/polygon-edge/network/discovery"
+	"github.com/juanidrobo/polygon-edge/network/grpc"
 	"github.com/libp2p/go-libp2p"
 	noise "github.com/libp2p/go-libp2p-noise"
 	rawGrpc "google.golang.org/grpc"
@@ -68,6 +69,8 @@ type Server struct {
 
 	metrics *Metrics // reference for metrics tracking
 
+	bandwidth *bandwidthReporter // the libp2p bandwidth usage, per protocol and peer
+
 	dialQueue *dial.DialQueue // queue used to asynchronously connect to peers
 
 	discovery *discovery.DiscoveryService // service used for discovering other peers
@@ -86,6 +89,8 @@ type Server struct {
 	temporaryDials sync.Map // map of temporary connections; peerID -> bool
 
 	bootnodes *bootnodesWrapper // reference of all bootnodes for the node
+
+	reachability int32 // the node reachability detected by AutoNAT (network.Reachability)
 }
 
 // NewServer returns a new instance of the networking server
@@ -116,13 +121,18 @@ func NewServer(logger hclog.Logger, config *Config) (*Server, error) {
 		return addrs
 	}
 
-	host, err := libp2p.New(
+	bandwidth := newBandwidthReporter(config.Metrics)
+
+	opts := []libp2p.Option{
 		// Use noise as the encryption protocol
 		libp2p.Security(noise.ID, noise.New),
 		libp2p.ListenAddrs(listenAddr),
 		libp2p.AddrsFactory(addrsFactory),
 		libp2p.Identity(key),
-	)
+		libp2p.BandwidthReporter(bandwidth),
+	}
+
+	host, err := libp2p.New(append(opts, natOptions(config)...)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create libp2p stack: %w", err)
 	}
@@ -139,6 +149,7 @@ func NewServer(logger hclog.Logger, config *Config) (*Server, error) {
 		addrs:            host.Addrs(),
 		peers:            make(map[peer.ID]*PeerConnInfo),
 		metrics:          config.Metrics,
+		bandwidth:        bandwidth,
 		dialQueue:        dial.NewDialQueue(),
 		closeCh:          make(chan struct{}),
 		emitterPeerEvent: emitter,
@@ -260,6 +271,10 @@ func (s *Server) Start() error {
 		}
 	}
 
+	if setupErr := s.trackReachability(); setupErr != nil {
+		return fmt.Errorf("unable to track reachability, %w", setupErr)
+	}
+
 	go s.runDial()
 	go s.checkPeerConnections()
 
@@ -469,6 +484,8 @@ func (s *Server) removePeer(peerID peer.ID) {
 		return
 	}
 
+	s.bandwidth.peerDisconnected(peerID)
+
 	// Emit the event alerting listeners
 	s.emitEvent(peerID, peerEvent.PeerDisconnected)
 }
@@ -615,6 +632,10 @@ func (s *Server) RegisterProtocol(id string, p Protocol) {
 	s.protocolsLock.Lock()
 	defer s.protocolsLock.Unlock()
 
+	if stream, ok := p.(*grpc.GrpcStream); ok && len(s.config.RateLimits) != 0 {
+		stream.SetRateLimits(s.config.RateLimits)
+	}
+
 	s.protocols[id] = p
 	s.wrapStream(id, p.Handler())
 }
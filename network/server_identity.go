@@ -35,6 +35,8 @@ func (s *Server) AddPeer(id peer.ID, direction network.Direction) {
 		return
 	}
 
+	s.bandwidth.peerConnected(id)
+
 	// Emit the event alerting listeners
 	// WARNING: THIS CALL IS POTENTIALLY BLOCKING
 	// UNDER HEAVY LOAD. IT SHOULD BE SUBSTITUTED
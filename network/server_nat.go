@@ -0,0 +1,82 @@
+package network
+
+import (
+	"sync/atomic"
+
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p-core/event"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p/p2p/host/autorelay"
+)
+
+// natOptions returns the libp2p options for NAT traversal, based on the networking configuration.
+// The AutoNAT client is always running, and detects the node reachability
+// by asking the peers that run the AutoNAT service to dial back
+func natOptions(config *Config) []libp2p.Option {
+	opts := []libp2p.Option{}
+
+	if config.NATService {
+		// Help other peers determine their reachability
+		opts = append(opts, libp2p.EnableNATService())
+	}
+
+	if config.NATPortMap {
+		// Try to open a port on the NAT device using UPnP or NAT-PMP
+		opts = append(opts, libp2p.NATPortMap())
+	}
+
+	if len(config.Relays) != 0 {
+		relays := make([]peer.AddrInfo, 0, len(config.Relays))
+		for _, relay := range config.Relays {
+			relays = append(relays, *relay)
+		}
+
+		// Advertise relayed addresses when the node is not publicly reachable
+		opts = append(opts, libp2p.EnableAutoRelay(autorelay.WithStaticRelays(relays)))
+	}
+
+	if config.RelayService {
+		// The relay service only runs if the node is publicly reachable
+		opts = append(opts, libp2p.EnableRelayService())
+	}
+
+	return opts
+}
+
+// Reachability returns the reachability of the node, as detected by AutoNAT
+func (s *Server) Reachability() network.Reachability {
+	return network.Reachability(atomic.LoadInt32(&s.reachability))
+}
+
+// trackReachability keeps the node reachability up to date with the AutoNAT events
+func (s *Server) trackReachability() error {
+	sub, err := s.host.EventBus().Subscribe(new(event.EvtLocalReachabilityChanged))
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		defer sub.Close()
+
+		for {
+			select {
+			case evnt, ok := <-sub.Out():
+				if !ok {
+					return
+				}
+
+				reachability := evnt.(event.EvtLocalReachabilityChanged).Reachability //nolint:forcetypeassert
+
+				s.logger.Info("Reachability changed", "reachability", reachability.String())
+
+				atomic.StoreInt32(&s.reachability, int32(reachability))
+				s.metrics.Reachability.Set(float64(reachability))
+			case <-s.closeCh:
+				return
+			}
+		}
+	}()
+
+	return nil
+}
@@ -5,18 +5,25 @@ import (
 	"fmt"
 	"github.com/juanidrobo/polygon-edge/network/common"
 	peerEvent "github.com/juanidrobo/polygon-edge/network/event"
+	"github.com/juanidrobo/polygon-edge/network/grpc"
+	"github.com/juanidrobo/polygon-edge/network/proto"
 	"net"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/go-kit/kit/metrics"
 	"github.com/juanidrobo/polygon-edge/helper/tests"
 
 	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/event"
 	"github.com/libp2p/go-libp2p-core/network"
 	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/multiformats/go-multiaddr"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestConnLimit_Inbound(t *testing.T) {
@@ -1022,3 +1029,190 @@ func generateRandomPeers(t *testing.T, count int) ([]*randomPeer, error) {
 
 	return randomPeers, nil
 }
+
+func TestReachability_TrackAutoNATEvents(t *testing.T) {
+	server, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+		c.NoDiscover = true
+	}})
+	if createErr != nil {
+		t.Fatalf("Unable to create server, %v", createErr)
+	}
+
+	t.Cleanup(func() {
+		assert.NoError(t, server.Close())
+	})
+
+	assert.Equal(t, network.ReachabilityUnknown, server.Reachability())
+
+	emitter, err := server.host.EventBus().Emitter(new(event.EvtLocalReachabilityChanged))
+	assert.NoError(t, err)
+
+	defer emitter.Close()
+
+	for _, reachability := range []network.Reachability{
+		network.ReachabilityPrivate,
+		network.ReachabilityPublic,
+	} {
+		assert.NoError(t, emitter.Emit(event.EvtLocalReachabilityChanged{Reachability: reachability}))
+
+		assert.Eventually(t, func() bool {
+			return server.Reachability() == reachability
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+}
+
+type helloService struct {
+	proto.UnimplementedTestServiceServer
+}
+
+func (s *helloService) SayHello(_ context.Context, req *proto.GenericMessage) (*proto.GenericMessage, error) {
+	return req, nil
+}
+
+func TestRateLimits(t *testing.T) {
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {
+			ConfigCallback: func(c *Config) {
+				c.NoDiscover = true
+			},
+		},
+		1: {
+			ConfigCallback: func(c *Config) {
+				c.NoDiscover = true
+				c.RateLimits = map[string]float64{
+					"/v1.TestService/SayHello": 0.01,
+				}
+			},
+		},
+	})
+	if createErr != nil {
+		t.Fatalf("Unable to create servers, %v", createErr)
+	}
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	testProto := "/test/0.1"
+
+	stream := grpc.NewGrpcStream()
+	proto.RegisterTestServiceServer(stream.GrpcServer(), &helloService{})
+	stream.Serve()
+	servers[1].RegisterProtocol(testProto, stream)
+
+	if joinErr := JoinAndWait(servers[0], servers[1], DefaultBufferTimeout, DefaultJoinTimeout); joinErr != nil {
+		t.Fatalf("Unable to join servers, %v", joinErr)
+	}
+
+	rawStream, err := servers[0].NewStream(testProto, servers[1].host.ID())
+	assert.NoError(t, err)
+
+	clt := proto.NewTestServiceClient(grpc.WrapClient(rawStream))
+
+	sayHello := func(timeout time.Duration) error {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		_, err := clt.SayHello(ctx, &proto.GenericMessage{Message: "hello"})
+
+		return err
+	}
+
+	// the first request uses the burst, the second one
+	// would have to wait past its deadline for the next token
+	assert.NoError(t, sayHello(DefaultJoinTimeout))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(sayHello(10*time.Second)))
+}
+
+// testCounter is a metrics.Counter that accumulates the values of all the labels
+type testCounter struct {
+	values map[string]float64
+	lvs    []string
+}
+
+func (c *testCounter) With(labelValues ...string) metrics.Counter {
+	return &testCounter{
+		values: c.values,
+		lvs:    append(append([]string{}, c.lvs...), labelValues...),
+	}
+}
+
+func (c *testCounter) Add(delta float64) {
+	c.values[strings.Join(c.lvs, ",")] += delta
+}
+
+// testPeerGauge is a PeerGauge that accumulates the values of all the labels
+type testPeerGauge struct {
+	values map[string]float64
+	lvs    []string
+}
+
+func (g *testPeerGauge) With(labelValues ...string) metrics.Gauge {
+	return &testPeerGauge{
+		values: g.values,
+		lvs:    append(append([]string{}, g.lvs...), labelValues...),
+	}
+}
+
+func (g *testPeerGauge) Set(value float64) {
+	g.values[strings.Join(g.lvs, ",")] = value
+}
+
+func (g *testPeerGauge) Add(delta float64) {
+	g.values[strings.Join(g.lvs, ",")] += delta
+}
+
+func (g *testPeerGauge) Delete(labelValues ...string) {
+	delete(g.values, strings.Join(labelValues, ","))
+}
+
+func TestBandwidthReporter(t *testing.T) {
+	protocolBytes := &testCounter{values: map[string]float64{}}
+	peerBytes := &testPeerGauge{values: map[string]float64{}}
+
+	metrics := NilMetrics()
+	metrics.ProtocolBytes = protocolBytes
+	metrics.PeerBytes = peerBytes
+
+	reporter := newBandwidthReporter(metrics)
+
+	peerID := peer.ID("peer")
+
+	reporter.LogSentMessageStream(10, "/syncer/0.1", peerID)
+	reporter.LogSentMessageStream(5, "/syncer/0.1", peerID)
+	reporter.LogRecvMessageStream(7, "/meshsub/1.1.0", peerID)
+
+	assert.Equal(t, map[string]float64{
+		"protocol,/syncer/0.1,direction,out":   15,
+		"protocol,/meshsub/1.1.0,direction,in": 7,
+	}, protocolBytes.values)
+
+	// the traffic of a peer which is not connected is not reported
+	assert.Empty(t, peerBytes.values)
+
+	reporter.peerConnected(peerID)
+
+	reporter.LogSentMessageStream(3, "/syncer/0.1", peerID)
+	reporter.LogRecvMessageStream(2, "/meshsub/1.1.0", peerID)
+
+	assert.Equal(t, map[string]float64{
+		"peer," + peerID.String() + ",direction,out": 3,
+		"peer," + peerID.String() + ",direction,in":  2,
+	}, peerBytes.values)
+
+	// the series of the peer are deleted once it disconnects
+	reporter.peerDisconnected(peerID)
+
+	reporter.LogSentMessageStream(1, "/syncer/0.1", peerID)
+
+	assert.Empty(t, peerBytes.values)
+
+	// the whole traffic with the peer is kept by the counter once the meters are updated
+	srv := &Server{bandwidth: reporter}
+
+	assert.Eventually(t, func() bool {
+		stats := srv.GetPeerBandwidth(peerID)
+
+		return stats.TotalOut == 19 && stats.TotalIn == 9
+	}, 5*time.Second, 100*time.Millisecond)
+}
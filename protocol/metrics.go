@@ -0,0 +1,61 @@
+package protocol
+
+import (
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+	prometheus "github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics represents the syncer metrics
+type Metrics struct {
+	// Number of full blocks received that were already known
+	DuplicateBlocks metrics.Counter
+
+	// Number of block announcements received for blocks that were already known
+	DuplicateAnnouncements metrics.Counter
+
+	// Number of block bodies fetched on demand after an announcement
+	FetchedBlocks metrics.Counter
+}
+
+// GetPrometheusMetrics return the syncer metrics instance
+func GetPrometheusMetrics(namespace string, labelsWithValues ...string) *Metrics {
+	labels := []string{}
+
+	for i := 0; i < len(labelsWithValues); i += 2 {
+		labels = append(labels, labelsWithValues[i])
+	}
+
+	return &Metrics{
+		DuplicateBlocks: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "syncer",
+			Name:      "duplicate_blocks",
+			Help:      "Number of full blocks received that were already known",
+		}, labels).With(labelsWithValues...),
+
+		DuplicateAnnouncements: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "syncer",
+			Name:      "duplicate_announcements",
+			Help:      "Number of block announcements received for blocks that were already known",
+		}, labels).With(labelsWithValues...),
+
+		FetchedBlocks: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "syncer",
+			Name:      "fetched_blocks",
+			Help:      "Number of block bodies fetched on demand after an announcement",
+		}, labels).With(labelsWithValues...),
+	}
+}
+
+// NilMetrics will return the non operational syncer metrics
+func NilMetrics() *Metrics {
+	return &Metrics{
+		DuplicateBlocks:        discard.NewCounter(),
+		DuplicateAnnouncements: discard.NewCounter(),
+		FetchedBlocks:          discard.NewCounter(),
+	}
+}
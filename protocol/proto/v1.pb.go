@@ -1,17 +1,16 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.25.0
-// 	protoc        v3.12.0
+// 	protoc-gen-go v1.28.0
+// 	protoc        v3.19.4
 // source: protocol/proto/v1.proto
 
 package proto
 
 import (
-	proto "github.com/golang/protobuf/proto"
-	any "github.com/golang/protobuf/ptypes/any"
-	empty "github.com/golang/protobuf/ptypes/empty"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	anypb "google.golang.org/protobuf/types/known/anypb"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
 	reflect "reflect"
 	sync "sync"
 )
@@ -23,10 +22,6 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
-// This is a compile-time assertion that a sufficiently up-to-date version
-// of the legacy proto package is being used.
-const _ = proto.ProtoPackageIsVersion4
-
 type HashRequest_Type int32
 
 const (
@@ -405,8 +400,8 @@ type NotifyReq struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Status *V1Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
-	Raw    *any.Any  `protobuf:"bytes,2,opt,name=raw,proto3" json:"raw,omitempty"`
+	Status *V1Status  `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Raw    *anypb.Any `protobuf:"bytes,2,opt,name=raw,proto3" json:"raw,omitempty"`
 }
 
 func (x *NotifyReq) Reset() {
@@ -448,25 +443,72 @@ func (x *NotifyReq) GetStatus() *V1Status {
 	return nil
 }
 
-func (x *NotifyReq) GetRaw() *any.Any {
+func (x *NotifyReq) GetRaw() *anypb.Any {
 	if x != nil {
 		return x.Raw
 	}
 	return nil
 }
 
+type AnnounceReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Status *V1Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+}
+
+func (x *AnnounceReq) Reset() {
+	*x = AnnounceReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protocol_proto_v1_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AnnounceReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnnounceReq) ProtoMessage() {}
+
+func (x *AnnounceReq) ProtoReflect() protoreflect.Message {
+	mi := &file_protocol_proto_v1_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnnounceReq.ProtoReflect.Descriptor instead.
+func (*AnnounceReq) Descriptor() ([]byte, []int) {
+	return file_protocol_proto_v1_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *AnnounceReq) GetStatus() *V1Status {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
 type Response_Component struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Spec *any.Any `protobuf:"bytes,1,opt,name=spec,proto3" json:"spec,omitempty"`
+	Spec *anypb.Any `protobuf:"bytes,1,opt,name=spec,proto3" json:"spec,omitempty"`
 }
 
 func (x *Response_Component) Reset() {
 	*x = Response_Component{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_protocol_proto_v1_proto_msgTypes[7]
+		mi := &file_protocol_proto_v1_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -479,7 +521,7 @@ func (x *Response_Component) String() string {
 func (*Response_Component) ProtoMessage() {}
 
 func (x *Response_Component) ProtoReflect() protoreflect.Message {
-	mi := &file_protocol_proto_v1_proto_msgTypes[7]
+	mi := &file_protocol_proto_v1_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -495,7 +537,7 @@ func (*Response_Component) Descriptor() ([]byte, []int) {
 	return file_protocol_proto_v1_proto_rawDescGZIP(), []int{4, 0}
 }
 
-func (x *Response_Component) GetSpec() *any.Any {
+func (x *Response_Component) GetSpec() *anypb.Any {
 	if x != nil {
 		return x.Spec
 	}
@@ -546,22 +588,29 @@ var file_protocol_proto_v1_proto_rawDesc = []byte{
 	0x0b, 0x32, 0x0c, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x31, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52,
 	0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x26, 0x0a, 0x03, 0x72, 0x61, 0x77, 0x18, 0x02,
 	0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x41, 0x6e, 0x79, 0x52, 0x03, 0x72, 0x61, 0x77, 0x32,
-	0xcf, 0x01, 0x0a, 0x02, 0x56, 0x31, 0x12, 0x32, 0x0a, 0x0a, 0x47, 0x65, 0x74, 0x43, 0x75, 0x72,
-	0x72, 0x65, 0x6e, 0x74, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x0c, 0x2e, 0x76,
-	0x31, 0x2e, 0x56, 0x31, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x31, 0x0a, 0x10, 0x47, 0x65,
-	0x74, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x73, 0x42, 0x79, 0x48, 0x61, 0x73, 0x68, 0x12, 0x0f,
-	0x2e, 0x76, 0x31, 0x2e, 0x48, 0x61, 0x73, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
-	0x0c, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x31, 0x0a,
-	0x0a, 0x47, 0x65, 0x74, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x12, 0x15, 0x2e, 0x76, 0x31,
-	0x2e, 0x47, 0x65, 0x74, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x1a, 0x0c, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x12, 0x2f, 0x0a, 0x06, 0x4e, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x12, 0x0d, 0x2e, 0x76, 0x31, 0x2e,
-	0x4e, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x52, 0x65, 0x71, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x41, 0x6e, 0x79, 0x52, 0x03, 0x72, 0x61, 0x77, 0x22,
+	0x33, 0x0a, 0x0b, 0x41, 0x6e, 0x6e, 0x6f, 0x75, 0x6e, 0x63, 0x65, 0x52, 0x65, 0x71, 0x12, 0x24,
+	0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c,
+	0x2e, 0x76, 0x31, 0x2e, 0x56, 0x31, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x32, 0x84, 0x02, 0x0a, 0x02, 0x56, 0x31, 0x12, 0x32, 0x0a, 0x0a, 0x47,
+	0x65, 0x74, 0x43, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
 	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74,
-	0x79, 0x42, 0x11, 0x5a, 0x0f, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x2f, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x79, 0x1a, 0x0c, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x31, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12,
+	0x31, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x73, 0x42, 0x79, 0x48,
+	0x61, 0x73, 0x68, 0x12, 0x0f, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x61, 0x73, 0x68, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x0c, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x31, 0x0a, 0x0a, 0x47, 0x65, 0x74, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73,
+	0x12, 0x15, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0c, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2f, 0x0a, 0x06, 0x4e, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x12,
+	0x0d, 0x2e, 0x76, 0x31, 0x2e, 0x4e, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x52, 0x65, 0x71, 0x1a, 0x16,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x33, 0x0a, 0x08, 0x41, 0x6e, 0x6e, 0x6f, 0x75, 0x6e,
+	0x63, 0x65, 0x12, 0x0f, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6e, 0x6e, 0x6f, 0x75, 0x6e, 0x63, 0x65,
+	0x52, 0x65, 0x71, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x42, 0x11, 0x5a, 0x0f, 0x2f,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -577,7 +626,7 @@ func file_protocol_proto_v1_proto_rawDescGZIP() []byte {
 }
 
 var file_protocol_proto_v1_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_protocol_proto_v1_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_protocol_proto_v1_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
 var file_protocol_proto_v1_proto_goTypes = []interface{}{
 	(HashRequest_Type)(0),      // 0: v1.HashRequest.Type
 	(*GetCurrentResponse)(nil), // 1: v1.GetCurrentResponse
@@ -587,29 +636,33 @@ var file_protocol_proto_v1_proto_goTypes = []interface{}{
 	(*Response)(nil),           // 5: v1.Response
 	(*V1Status)(nil),           // 6: v1.V1Status
 	(*NotifyReq)(nil),          // 7: v1.NotifyReq
-	(*Response_Component)(nil), // 8: v1.Response.Component
-	(*any.Any)(nil),            // 9: google.protobuf.Any
-	(*empty.Empty)(nil),        // 10: google.protobuf.Empty
+	(*AnnounceReq)(nil),        // 8: v1.AnnounceReq
+	(*Response_Component)(nil), // 9: v1.Response.Component
+	(*anypb.Any)(nil),          // 10: google.protobuf.Any
+	(*emptypb.Empty)(nil),      // 11: google.protobuf.Empty
 }
 var file_protocol_proto_v1_proto_depIdxs = []int32{
 	0,  // 0: v1.HashRequest.type:type_name -> v1.HashRequest.Type
-	8,  // 1: v1.Response.objs:type_name -> v1.Response.Component
+	9,  // 1: v1.Response.objs:type_name -> v1.Response.Component
 	6,  // 2: v1.NotifyReq.status:type_name -> v1.V1Status
-	9,  // 3: v1.NotifyReq.raw:type_name -> google.protobuf.Any
-	9,  // 4: v1.Response.Component.spec:type_name -> google.protobuf.Any
-	10, // 5: v1.V1.GetCurrent:input_type -> google.protobuf.Empty
-	3,  // 6: v1.V1.GetObjectsByHash:input_type -> v1.HashRequest
-	2,  // 7: v1.V1.GetHeaders:input_type -> v1.GetHeadersRequest
-	7,  // 8: v1.V1.Notify:input_type -> v1.NotifyReq
-	6,  // 9: v1.V1.GetCurrent:output_type -> v1.V1Status
-	5,  // 10: v1.V1.GetObjectsByHash:output_type -> v1.Response
-	5,  // 11: v1.V1.GetHeaders:output_type -> v1.Response
-	10, // 12: v1.V1.Notify:output_type -> google.protobuf.Empty
-	9,  // [9:13] is the sub-list for method output_type
-	5,  // [5:9] is the sub-list for method input_type
-	5,  // [5:5] is the sub-list for extension type_name
-	5,  // [5:5] is the sub-list for extension extendee
-	0,  // [0:5] is the sub-list for field type_name
+	10, // 3: v1.NotifyReq.raw:type_name -> google.protobuf.Any
+	6,  // 4: v1.AnnounceReq.status:type_name -> v1.V1Status
+	10, // 5: v1.Response.Component.spec:type_name -> google.protobuf.Any
+	11, // 6: v1.V1.GetCurrent:input_type -> google.protobuf.Empty
+	3,  // 7: v1.V1.GetObjectsByHash:input_type -> v1.HashRequest
+	2,  // 8: v1.V1.GetHeaders:input_type -> v1.GetHeadersRequest
+	7,  // 9: v1.V1.Notify:input_type -> v1.NotifyReq
+	8,  // 10: v1.V1.Announce:input_type -> v1.AnnounceReq
+	6,  // 11: v1.V1.GetCurrent:output_type -> v1.V1Status
+	5,  // 12: v1.V1.GetObjectsByHash:output_type -> v1.Response
+	5,  // 13: v1.V1.GetHeaders:output_type -> v1.Response
+	11, // 14: v1.V1.Notify:output_type -> google.protobuf.Empty
+	11, // 15: v1.V1.Announce:output_type -> google.protobuf.Empty
+	11, // [11:16] is the sub-list for method output_type
+	6,  // [6:11] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
 }
 
 func init() { file_protocol_proto_v1_proto_init() }
@@ -703,6 +756,18 @@ func file_protocol_proto_v1_proto_init() {
 			}
 		}
 		file_protocol_proto_v1_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AnnounceReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protocol_proto_v1_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Response_Component); i {
 			case 0:
 				return &v.state
@@ -721,7 +786,7 @@ func file_protocol_proto_v1_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_protocol_proto_v1_proto_rawDesc,
 			NumEnums:      1,
-			NumMessages:   8,
+			NumMessages:   9,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
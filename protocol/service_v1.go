@@ -49,12 +49,47 @@ func (s *serviceV1) Notify(ctx context.Context, req *proto.NotifyReq) (*empty.Em
 		return nil, err
 	}
 
-	s.syncer.enqueueBlock(id, b)
+	n := &blockNotification{
+		hash:   b.Hash(),
+		block:  b,
+		doneCh: make(chan struct{}),
+	}
+
+	// wait for the block to be queued, so the full blocks
+	// are available to the syncer once the call returns
+	s.syncer.notifyBlock(id, n, true)
 	s.syncer.updatePeerStatus(id, status)
 
 	return &empty.Empty{}, nil
 }
 
+// Announce implements the V1Server interface
+func (s *serviceV1) Announce(ctx context.Context, req *proto.AnnounceReq) (*empty.Empty, error) {
+	var id peer.ID
+
+	if ctx, ok := ctx.(*grpc.Context); ok {
+		id = ctx.PeerID
+	} else {
+		return &empty.Empty{}, nil
+	}
+
+	status, err := fromProto(req.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	s.syncer.updatePeerStatus(id, status)
+
+	// the block is fetched asynchronously, as the announcing peer
+	// is blocked until this call returns
+	s.syncer.notifyBlock(id, &blockNotification{
+		hash:   status.Hash,
+		doneCh: make(chan struct{}),
+	}, false)
+
+	return &empty.Empty{}, nil
+}
+
 // GetCurrent implements the V1Server interface
 func (s *serviceV1) GetCurrent(_ context.Context, _ *empty.Empty) (*proto.V1Status, error) {
 	return s.syncer.status.toProto(), nil
@@ -2,14 +2,21 @@ package protocol
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/juanidrobo/polygon-edge/protocol/proto"
 	"github.com/juanidrobo/polygon-edge/types"
+	"github.com/juanidrobo/polygon-edge/types/buildroot"
 )
 
-func getHeaders(clt proto.V1Client, req *proto.GetHeadersRequest) ([]*types.Header, error) {
-	resp, err := clt.GetHeaders(context.Background(), req)
+var (
+	ErrSkeletonNotFound = errors.New("skeleton headers not found")
+	ErrInvalidSlot      = errors.New("invalid slot data")
+)
+
+func getHeaders(ctx context.Context, clt proto.V1Client, req *proto.GetHeadersRequest) ([]*types.Header, error) {
+	resp, err := clt.GetHeaders(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -28,6 +35,9 @@ func getHeaders(clt proto.V1Client, req *proto.GetHeadersRequest) ([]*types.Head
 	return headers, nil
 }
 
+// skeleton is a set of headers, one every span blocks, fetched from a single peer.
+// The gaps between the skeleton headers (slots) can then be filled from any peer,
+// as the skeleton headers make it possible to validate the returned data
 type skeleton struct {
 	slots []*slot
 	span  int64
@@ -40,33 +50,42 @@ func (s *skeleton) LastHeader() *types.Header {
 	return slot.blocks[len(slot.blocks)-1].Header
 }
 
-func (s *skeleton) build(clt proto.V1Client, ancestor types.Hash) error {
-	// since ancestor is the common block we need to query the next one
-	headers, err := getHeaders(clt, &proto.GetHeadersRequest{Hash: ancestor.String(), Skip: s.span - 1, Amount: s.num})
+// build fetches the skeleton headers starting from the given block number
+func (s *skeleton) build(ctx context.Context, clt proto.V1Client, from uint64) error {
+	headers, err := getHeaders(ctx, clt, &proto.GetHeadersRequest{Number: int64(from), Skip: s.span - 1, Amount: s.num})
 	if err != nil {
 		return err
 	}
-	s.addSkeleton(headers) // nolint
 
-	return nil
+	if len(headers) == 0 {
+		return ErrSkeletonNotFound
+	}
+
+	return s.addSkeleton(headers)
 }
 
-func (s *skeleton) fillSlot(indx uint64, clt proto.V1Client) error {
+// fillSlot downloads the headers and bodies of the slot from the given peer,
+// and checks they link up with the skeleton headers
+func (s *skeleton) fillSlot(ctx context.Context, indx uint64, clt proto.V1Client) error {
 	slot := s.slots[indx]
 	req := &proto.GetHeadersRequest{
 		Hash:   slot.hash.String(),
 		Amount: s.span,
 	}
-	resp, err := getHeaders(clt, req)
 
+	resp, err := getHeaders(ctx, clt, req)
 	if err != nil {
 		return err
 	}
 
-	slot.blocks = []*types.Block{}
+	if err := s.validateSlotHeaders(indx, resp); err != nil {
+		return err
+	}
+
+	blocks := []*types.Block{}
 
 	for _, h := range resp {
-		slot.blocks = append(slot.blocks, &types.Block{
+		blocks = append(blocks, &types.Block{
 			Header: h,
 		})
 	}
@@ -82,17 +101,53 @@ func (s *skeleton) fillSlot(indx uint64, clt proto.V1Client) error {
 		}
 	}
 
-	if len(bodyHashes) == 0 {
-		return nil
+	if len(bodyHashes) != 0 {
+		bodies, err := getBodies(ctx, clt, bodyHashes)
+		if err != nil {
+			return err
+		}
+
+		for indx, body := range bodies {
+			block := blocks[bodyIndex[indx]]
+
+			if buildroot.CalculateTransactionsRoot(body.Transactions) != block.Header.TxRoot {
+				return fmt.Errorf("%w: transactions root mismatch at block %d", ErrInvalidSlot, block.Number())
+			}
+
+			block.Transactions = body.Transactions
+		}
 	}
 
-	bodies, err := getBodies(context.Background(), clt, bodyHashes)
-	if err != nil {
-		return err
+	slot.blocks = blocks
+
+	return nil
+}
+
+// validateSlotHeaders checks the headers start at the slot's skeleton header, are linked to each other,
+// and link up with the skeleton header of the next slot, if any
+func (s *skeleton) validateSlotHeaders(indx uint64, headers []*types.Header) error {
+	slot := s.slots[indx]
+
+	if len(headers) == 0 || headers[0].Hash != slot.hash {
+		return fmt.Errorf("%w: slot %d does not start at the skeleton header", ErrInvalidSlot, slot.number)
 	}
 
-	for indx, body := range bodies {
-		slot.blocks[bodyIndex[indx]].Transactions = body.Transactions
+	for i := 1; i < len(headers); i++ {
+		if headers[i].ParentHash != headers[i-1].Hash || headers[i].Number != headers[i-1].Number+1 {
+			return fmt.Errorf("%w: headers are not linked at block %d", ErrInvalidSlot, headers[i].Number)
+		}
+	}
+
+	if int(indx) == len(s.slots)-1 {
+		// the last slot is not bounded by another skeleton header
+		return nil
+	}
+
+	next := s.slots[indx+1]
+	last := headers[len(headers)-1]
+
+	if last.Number+1 != next.number || last.Hash != next.parentHash {
+		return fmt.Errorf("%w: slot %d does not link up with the next skeleton header", ErrInvalidSlot, slot.number)
 	}
 
 	return nil
@@ -116,9 +171,10 @@ func (s *skeleton) addSkeleton(headers []*types.Header) error {
 
 	for indx, header := range headers {
 		slot := &slot{
-			hash:   header.Hash,
-			number: header.Number,
-			blocks: make([]*types.Block, diff),
+			hash:       header.Hash,
+			parentHash: header.ParentHash,
+			number:     header.Number,
+			blocks:     make([]*types.Block, diff),
 		}
 		s.slots[indx] = slot
 	}
@@ -127,7 +183,8 @@ func (s *skeleton) addSkeleton(headers []*types.Header) error {
 }
 
 type slot struct {
-	hash   types.Hash
-	number uint64
-	blocks []*types.Block
+	hash       types.Hash
+	parentHash types.Hash
+	number     uint64
+	blocks     []*types.Block
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/juanidrobo/polygon-edge/network/event"
 	"math"
 	"math/big"
+	"math/rand"
 	"sync"
 	"time"
 
@@ -16,7 +17,9 @@ import (
 	libp2pGrpc "github.com/juanidrobo/polygon-edge/network/grpc"
 	"github.com/juanidrobo/polygon-edge/protocol/proto"
 	"github.com/juanidrobo/polygon-edge/types"
+	"github.com/juanidrobo/polygon-edge/types/buildroot"
 	"github.com/hashicorp/go-hclog"
+	lru "github.com/hashicorp/golang-lru"
 	"github.com/libp2p/go-libp2p-core/peer"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/connectivity"
@@ -27,6 +30,27 @@ import (
 const (
 	maxEnqueueSize = 50
 	popTimeout     = 10 * time.Second
+
+	// maxSeenBlocks is the number of recently received blocks kept around
+	// to answer announcements without fetching the block again
+	maxSeenBlocks = 128
+
+	// announceFetchTimeout is the time limit for fetching an announced block
+	announceFetchTimeout = 5 * time.Second
+
+	// skeletonSpan is the number of blocks in a skeleton slot
+	skeletonSpan = 10
+
+	// skeletonNum is the number of slots in a skeleton, which is the
+	// maximum number of slots being downloaded concurrently
+	skeletonNum = 20
+
+	// slotFillTimeout is the time limit for downloading a single skeleton slot
+	slotFillTimeout = 10 * time.Second
+
+	// maxSlotFailures is the number of failed slot downloads after which
+	// a peer is no longer used for the current sync
+	maxSlotFailures = 3
 )
 
 var (
@@ -36,6 +60,8 @@ var (
 	ErrForkNotFound           = errors.New("fork not found")
 	ErrPopTimeout             = errors.New("timeout")
 	ErrConnectionClosed       = errors.New("connection closed")
+	ErrInvalidBlockBody       = errors.New("block body does not match the header")
+	ErrNoSyncPeers            = errors.New("no peers left to sync from")
 )
 
 // SyncPeer is a representation of the peer the node is syncing with
@@ -50,6 +76,17 @@ type SyncPeer struct {
 	enqueueLock sync.Mutex
 	enqueue     []*types.Block
 	enqueueCh   chan struct{}
+
+	notifyCh chan *blockNotification // pending block notifications, processed in order
+	closeCh  chan struct{}
+}
+
+// blockNotification is a block received from a peer, either in full
+// or as an announcement of a block that might need to be fetched
+type blockNotification struct {
+	hash   types.Hash
+	block  *types.Block // nil for announcements
+	doneCh chan struct{}
 }
 
 // Number returns the latest peer block height
@@ -210,16 +247,29 @@ type Syncer struct {
 	server *network.Server
 
 	syncProgression *progress.ProgressionWrapper
+
+	seenBlocks *lru.Cache // recently received blocks, block hash -> *types.Block
+
+	metrics *Metrics
 }
 
 // NewSyncer creates a new Syncer instance
-func NewSyncer(logger hclog.Logger, server *network.Server, blockchain blockchainShim) *Syncer {
+func NewSyncer(
+	logger hclog.Logger,
+	server *network.Server,
+	blockchain blockchainShim,
+	metrics *Metrics,
+) *Syncer {
+	seenBlocks, _ := lru.New(maxSeenBlocks)
+
 	s := &Syncer{
 		logger:          logger.Named("syncer"),
 		stopCh:          make(chan struct{}),
 		blockchain:      blockchain,
 		server:          server,
 		syncProgression: progress.NewProgressionWrapper(progress.ChainSyncBulk),
+		seenBlocks:      seenBlocks,
+		metrics:         metrics,
 	}
 
 	return s
@@ -310,7 +360,9 @@ func (s *Syncer) updatePeerStatus(peerID peer.ID, status *Status) {
 	}
 }
 
-// Broadcast broadcasts a block to all peers
+// Broadcast propagates a block to the peers. The full block is sent only to
+// a square root sized subset of the peers, while the remaining peers receive
+// an announcement and fetch the block on demand if they don't have it yet
 func (s *Syncer) Broadcast(b *types.Block) {
 	// Get the chain difficulty associated with block
 	td, ok := s.blockchain.GetTD(b.Hash())
@@ -321,25 +373,241 @@ func (s *Syncer) Broadcast(b *types.Block) {
 		return
 	}
 
-	// broadcast the new block to all the peers
-	req := &proto.NotifyReq{
-		Status: &proto.V1Status{
-			Hash:       b.Hash().String(),
-			Number:     b.Number(),
-			Difficulty: td.String(),
-		},
+	status := &proto.V1Status{
+		Hash:       b.Hash().String(),
+		Number:     b.Number(),
+		Difficulty: td.String(),
+	}
+
+	notifyReq := &proto.NotifyReq{
+		Status: status,
 		Raw: &anypb.Any{
 			Value: b.MarshalRLP(),
 		},
 	}
 
-	s.peers.Range(func(peerID, peer interface{}) bool {
-		if _, err := peer.(*SyncPeer).client.Notify(context.Background(), req); err != nil {
-			s.logger.Error("failed to notify", "err", err)
+	announceReq := &proto.AnnounceReq{
+		Status: status,
+	}
+
+	peers := s.shuffledPeers()
+	numFullPeers := fullBlockPeersCount(len(peers))
+
+	for indx, peer := range peers {
+		var err error
+
+		if indx < numFullPeers {
+			_, err = peer.client.Notify(context.Background(), notifyReq)
+		} else {
+			_, err = peer.client.Announce(context.Background(), announceReq)
+		}
+
+		if err != nil {
+			s.logger.Error("failed to broadcast block", "peer", peer.peer, "err", err)
+		}
+	}
+}
+
+// fullBlockPeersCount returns the number of peers out of numPeers
+// that should receive the full block on broadcast
+func fullBlockPeersCount(numPeers int) int {
+	return int(math.Ceil(math.Sqrt(float64(numPeers))))
+}
+
+// shuffledPeers returns the sync peers in random order
+func (s *Syncer) shuffledPeers() []*SyncPeer {
+	peers := make([]*SyncPeer, 0)
+
+	s.peers.Range(func(_, peer interface{}) bool {
+		if syncPeer, ok := peer.(*SyncPeer); ok {
+			peers = append(peers, syncPeer)
 		}
 
 		return true
 	})
+
+	//nolint: gosec
+	rand.Shuffle(len(peers), func(i, j int) {
+		peers[i], peers[j] = peers[j], peers[i]
+	})
+
+	return peers
+}
+
+// isKnownBlock checks if the block was already received or written locally
+func (s *Syncer) isKnownBlock(hash types.Hash) bool {
+	if s.seenBlocks.Contains(hash) {
+		return true
+	}
+
+	_, ok := s.blockchain.GetHeaderByHash(hash)
+
+	return ok
+}
+
+// notifyBlock queues a block notification from the peer. Notifications from a single peer
+// are processed in the order they arrive, so announced blocks that still need to be fetched
+// don't end up in the peer's block queue after the blocks that follow them.
+// If wait is set, the call blocks until the notification is processed
+func (s *Syncer) notifyBlock(peerID peer.ID, n *blockNotification, wait bool) {
+	rawPeer, ok := s.peers.Load(peerID)
+	if !ok {
+		return
+	}
+
+	syncPeer, ok := rawPeer.(*SyncPeer)
+	if !ok {
+		s.logger.Error("invalid sync peer type cast")
+
+		return
+	}
+
+	select {
+	case syncPeer.notifyCh <- n:
+	case <-syncPeer.closeCh:
+		return
+	default:
+		s.logger.Warn("dropping block notification, queue is full", "peer", peerID, "hash", n.hash)
+
+		return
+	}
+
+	if wait {
+		select {
+		case <-n.doneCh:
+		case <-syncPeer.closeCh:
+		}
+	}
+}
+
+// processNotifications handles the block notifications of the peer until the peer is removed
+func (s *Syncer) processNotifications(p *SyncPeer) {
+	for {
+		select {
+		case n := <-p.notifyCh:
+			if n.block != nil {
+				s.handleNotifiedBlock(p, n.block)
+			} else {
+				s.handleAnnouncement(p, n.hash)
+			}
+
+			close(n.doneCh)
+		case <-p.closeCh:
+			return
+		}
+	}
+}
+
+// handleNotifiedBlock processes a full block received from a peer
+func (s *Syncer) handleNotifiedBlock(p *SyncPeer, b *types.Block) {
+	if err := validateBody(b); err != nil {
+		s.penalizePeer(p.peer, err)
+
+		return
+	}
+
+	s.addBlock(p.peer, b)
+}
+
+// addBlock remembers the validated block, so that it is not fetched again, and queues it for the peer
+func (s *Syncer) addBlock(peerID peer.ID, b *types.Block) {
+	if s.isKnownBlock(b.Hash()) {
+		s.metrics.DuplicateBlocks.Add(1)
+	}
+
+	s.seenBlocks.Add(b.Hash(), b)
+	s.enqueueBlock(peerID, b)
+}
+
+// penalizePeer disconnects the peer which sent a block not matching its header
+func (s *Syncer) penalizePeer(peerID peer.ID, err error) {
+	s.logger.Warn("disconnecting peer which sent an invalid block", "peer", peerID, "err", err)
+	s.server.DisconnectFromPeer(peerID, "invalid block")
+}
+
+// handleAnnouncement processes a block announcement from a peer, fetching the
+// block from it only if the block is not known locally
+func (s *Syncer) handleAnnouncement(p *SyncPeer, hash types.Hash) {
+	if _, ok := s.blockchain.GetHeaderByHash(hash); ok {
+		// the block is already written locally
+		s.metrics.DuplicateAnnouncements.Add(1)
+
+		return
+	}
+
+	if seen, ok := s.seenBlocks.Get(hash); ok {
+		// the block was already received from another peer
+		s.metrics.DuplicateAnnouncements.Add(1)
+
+		if b, ok := seen.(*types.Block); ok {
+			s.enqueueBlock(p.peer, b)
+		}
+
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), announceFetchTimeout)
+	defer cancel()
+
+	b, err := fetchBlock(ctx, p.client, hash)
+	if errors.Is(err, ErrInvalidBlockBody) {
+		s.penalizePeer(p.peer, err)
+
+		return
+	} else if err != nil {
+		s.logger.Error("failed to fetch announced block", "peer", p.peer, "hash", hash, "err", err)
+
+		return
+	}
+
+	s.metrics.FetchedBlocks.Add(1)
+
+	s.addBlock(p.peer, b)
+}
+
+// fetchBlock retrieves the header and body of the block with the given hash from the peer
+func fetchBlock(ctx context.Context, clt proto.V1Client, hash types.Hash) (*types.Block, error) {
+	header, err := getHeader(ctx, clt, nil, &hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if header == nil || header.Hash != hash {
+		return nil, fmt.Errorf("header not found")
+	}
+
+	b := &types.Block{
+		Header: header,
+	}
+
+	if header.HasBody() {
+		bodies, err := getBodies(ctx, clt, []types.Hash{hash})
+		if err != nil {
+			return nil, err
+		}
+
+		b.Transactions = bodies[0].Transactions
+		b.Uncles = bodies[0].Uncles
+	}
+
+	if err := validateBody(b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// validateBody checks the transactions and the uncles of the block match the roots of its header
+func validateBody(b *types.Block) error {
+	if hash := buildroot.CalculateTransactionsRoot(b.Transactions); hash != b.Header.TxRoot {
+		return fmt.Errorf("%w: transactions root mismatch, have %s, want %s", ErrInvalidBlockBody, hash, b.Header.TxRoot)
+	}
+
+	if hash := buildroot.CalculateUncleRoot(b.Uncles); hash != b.Header.Sha3Uncles {
+		return fmt.Errorf("%w: uncle root mismatch, have %s, want %s", ErrInvalidBlockBody, hash, b.Header.Sha3Uncles)
+	}
+
+	return nil
 }
 
 // Start starts the syncer protocol
@@ -476,13 +744,19 @@ func (s *Syncer) AddPeer(peerID peer.ID) error {
 		return err
 	}
 
-	s.peers.Store(peerID, &SyncPeer{
+	syncPeer := &SyncPeer{
 		peer:      peerID,
 		conn:      conn,
 		client:    clt,
 		status:    status,
 		enqueueCh: make(chan struct{}),
-	})
+		notifyCh:  make(chan *blockNotification, maxEnqueueSize),
+		closeCh:   make(chan struct{}),
+	}
+
+	s.peers.Store(peerID, syncPeer)
+
+	go s.processNotifications(syncPeer)
 
 	return nil
 }
@@ -496,6 +770,8 @@ func (s *Syncer) DeletePeer(peerID peer.ID) error {
 			return errors.New("invalid type assertion")
 		}
 
+		close(syncPeer.closeCh)
+
 		if err := syncPeer.conn.Close(); err != nil {
 			return err
 		}
@@ -536,7 +812,7 @@ func (s *Syncer) findCommonAncestor(clt proto.V1Client, status *Status) (*types.
 			break
 		}
 
-		found, err := getHeader(clt, &m, nil)
+		found, err := getHeader(context.Background(), clt, &m, nil)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -567,7 +843,7 @@ func (s *Syncer) findCommonAncestor(clt proto.V1Client, status *Status) (*types.
 
 	// get the block fork
 	forkNum := header.Number + 1
-	fork, err := getHeader(clt, &forkNum, nil)
+	fork, err := getHeader(context.Background(), clt, &forkNum, nil)
 
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get fork at num %d", header.Number)
@@ -622,7 +898,9 @@ func (s *Syncer) logSyncPeerPopBlockError(err error, peer *SyncPeer) {
 	}
 }
 
-// BulkSyncWithPeer finds common ancestor with a peer and syncs block until latest block
+// BulkSyncWithPeer finds common ancestor with a peer and syncs block until latest block.
+// The skeleton headers are downloaded from the given peer, while the blocks in between
+// are downloaded concurrently from all the peers that are at least as far ahead
 func (s *Syncer) BulkSyncWithPeer(p *SyncPeer, newBlockHandler func(block *types.Block)) error {
 	// find the common ancestor
 	ancestor, fork, err := s.findCommonAncestor(p.client, p.status)
@@ -633,20 +911,23 @@ func (s *Syncer) BulkSyncWithPeer(p *SyncPeer, newBlockHandler func(block *types
 	// find in batches
 	s.logger.Debug("fork found", "ancestor", ancestor.Number)
 
-	startBlock := fork
+	from := fork.Number
 
 	var lastTarget uint64
 
 	// Create a blockchain subscription for the sync progression and start tracking
-	s.syncProgression.StartProgression(startBlock.Number, s.blockchain.SubscribeEvents())
+	s.syncProgression.StartProgression(from, s.blockchain.SubscribeEvents())
 
 	// Stop monitoring the sync progression upon exit
 	defer s.syncProgression.StopProgression()
 
+	// peers that failed too many times during this sync
+	penalized := make(map[peer.ID]bool)
+
 	// sync up to the current known header
 	for {
 		// update target
-		target := p.status.Number
+		target := p.Number()
 
 		s.syncProgression.UpdateHighestProgression(target)
 
@@ -656,25 +937,25 @@ func (s *Syncer) BulkSyncWithPeer(p *SyncPeer, newBlockHandler func(block *types
 		}
 
 		for {
-			s.logger.Debug("sync up to block", "from", startBlock.Number, "to", target)
+			s.logger.Debug("sync up to block", "from", from, "to", target)
 
 			// start to synchronize with it
 			sk := &skeleton{
-				span: 10,
-				num:  5,
+				span: skeletonSpan,
+				num:  skeletonNum,
 			}
 
-			if err := sk.build(p.client, startBlock.Hash); err != nil {
-				return fmt.Errorf("failed to build skeleton: %w", err)
-			}
+			ctx, cancel := context.WithTimeout(context.Background(), slotFillTimeout)
+			err := sk.build(ctx, p.client, from)
+
+			cancel()
 
-			// fill skeleton
-			for indx := range sk.slots {
-				sk.fillSlot(uint64(indx), p.client) //nolint
+			if err != nil {
+				return fmt.Errorf("failed to build skeleton: %w", err)
 			}
 
-			// sync the data
-			for _, slot := range sk.slots {
+			// fill the skeleton and write the slots in order as they are filled
+			if err := s.fillSkeleton(sk, s.fillPeers(p, target, penalized), penalized, func(slot *slot) error {
 				for _, block := range slot.blocks {
 					if err := s.blockchain.WriteBlock(block); err != nil {
 						return fmt.Errorf("failed to write bulk sync blocks: %w", err)
@@ -682,12 +963,17 @@ func (s *Syncer) BulkSyncWithPeer(p *SyncPeer, newBlockHandler func(block *types
 
 					newBlockHandler(block)
 				}
+
+				return nil
+			}); err != nil {
+				return err
 			}
 
-			// try to get the next block
-			startBlock = sk.LastHeader()
+			// continue from the block after the last written one
+			lastHeader := sk.LastHeader()
+			from = lastHeader.Number + 1
 
-			if startBlock.Number >= target {
+			if lastHeader.Number >= target {
 				break
 			}
 		}
@@ -698,7 +984,136 @@ func (s *Syncer) BulkSyncWithPeer(p *SyncPeer, newBlockHandler func(block *types
 	return nil
 }
 
-func getHeader(clt proto.V1Client, num *uint64, hash *types.Hash) (*types.Header, error) {
+// fillPeers returns the peers that can be used to fill the skeleton built from the given peer
+func (s *Syncer) fillPeers(skeletonPeer *SyncPeer, target uint64, penalized map[peer.ID]bool) []*SyncPeer {
+	peers := []*SyncPeer{skeletonPeer}
+
+	s.peers.Range(func(_, rawPeer interface{}) bool {
+		syncPeer, ok := rawPeer.(*SyncPeer)
+		if !ok || syncPeer == skeletonPeer || penalized[syncPeer.peer] {
+			return true
+		}
+
+		if !syncPeer.IsClosed() && syncPeer.Number() >= target {
+			peers = append(peers, syncPeer)
+		}
+
+		return true
+	})
+
+	return peers
+}
+
+// fillSkeleton downloads the skeleton slots concurrently from the given peers. A slot that
+// fails to download is retried with the next available peer, and peers that fail too often
+// are penalized and no longer used. The handler is called with each slot in order, as soon
+// as it is filled, while the following slots are still downloading
+func (s *Syncer) fillSkeleton(
+	sk *skeleton,
+	peers []*SyncPeer,
+	penalized map[peer.ID]bool,
+	handler func(slot *slot) error,
+) error {
+	numSlots := len(sk.slots)
+
+	// each slot index is in the queue at most once, so sends never block
+	pendingCh := make(chan int, numSlots)
+	filledCh := make([]chan struct{}, numSlots)
+
+	for indx := range sk.slots {
+		pendingCh <- indx
+		filledCh[indx] = make(chan struct{})
+	}
+
+	var (
+		penalizedLock sync.Mutex
+		workersWg     sync.WaitGroup
+	)
+
+	doneCh := make(chan struct{})
+
+	// stop the workers and wait for the ones still downloading
+	defer func() {
+		close(doneCh)
+		workersWg.Wait()
+	}()
+
+	workersWg.Add(len(peers))
+
+	for _, p := range peers {
+		go func(p *SyncPeer) {
+			defer workersWg.Done()
+
+			failures := 0
+
+			for {
+				var indx int
+
+				select {
+				case indx = <-pendingCh:
+				case <-doneCh:
+					return
+				}
+
+				ctx, cancel := context.WithTimeout(context.Background(), slotFillTimeout)
+				err := sk.fillSlot(ctx, uint64(indx), p.client)
+
+				cancel()
+
+				if err == nil {
+					close(filledCh[indx])
+
+					continue
+				}
+
+				// give the slot to another peer
+				pendingCh <- indx
+
+				failures++
+
+				s.logger.Debug("failed to fill skeleton slot", "peer", p.peer, "slot", sk.slots[indx].number, "err", err)
+
+				if failures >= maxSlotFailures {
+					s.logger.Warn("penalizing slow or faulty sync peer", "peer", p.peer, "failures", failures)
+
+					penalizedLock.Lock()
+					penalized[p.peer] = true
+					penalizedLock.Unlock()
+
+					return
+				}
+			}
+		}(p)
+	}
+
+	// closed once every worker has given up
+	noWorkersCh := make(chan struct{})
+
+	go func() {
+		workersWg.Wait()
+		close(noWorkersCh)
+	}()
+
+	for indx, slot := range sk.slots {
+		select {
+		case <-filledCh[indx]:
+		case <-noWorkersCh:
+			select {
+			case <-filledCh[indx]:
+			default:
+				return ErrNoSyncPeers
+			}
+		}
+
+		if err := handler(slot); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func getHeader(ctx context.Context, clt proto.V1Client, num *uint64, hash *types.Hash) (*types.Header, error) {
 	req := &proto.GetHeadersRequest{}
 	if num != nil {
 		req.Number = int64(*num)
@@ -708,7 +1123,7 @@ func getHeader(clt proto.V1Client, num *uint64, hash *types.Hash) (*types.Header
 		req.Hash = (*hash).String()
 	}
 
-	resp, err := clt.GetHeaders(context.Background(), req)
+	resp, err := clt.GetHeaders(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -10,9 +10,13 @@ import (
 	"github.com/juanidrobo/polygon-edge/blockchain"
 	"github.com/juanidrobo/polygon-edge/helper/tests"
 	"github.com/juanidrobo/polygon-edge/network"
+	"github.com/juanidrobo/polygon-edge/protocol/proto"
 	"github.com/juanidrobo/polygon-edge/types"
+	"github.com/juanidrobo/polygon-edge/types/buildroot"
 	"github.com/hashicorp/go-hclog"
+	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
 )
 
 func TestHandleNewPeer(t *testing.T) {
@@ -140,6 +144,216 @@ func TestBroadcast(t *testing.T) {
 	}
 }
 
+func TestBroadcastAnnouncement(t *testing.T) {
+	tests := []struct {
+		name          string
+		syncerHeaders []*types.Header
+		numPeers      int
+		numNewBlocks  int
+	}{
+		{
+			name:          "peers should receive all blocks in order, in full or by announcement",
+			syncerHeaders: blockchain.NewTestHeaderChainWithSeed(nil, 5, 0),
+			numPeers:      5,
+			numNewBlocks:  5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := NewMockBlockchain(tt.syncerHeaders)
+
+			peerChains := make([]blockchainShim, tt.numPeers)
+			for i := range peerChains {
+				peerChains[i] = NewMockBlockchain(tt.syncerHeaders)
+			}
+
+			syncer, peerSyncers := SetupSyncerNetwork(t, chain, peerChains)
+
+			for _, peerSyncer := range peerSyncers {
+				WaitUntilPeerConnected(t, peerSyncer, 1, 10*time.Second)
+			}
+
+			newBlocks := GenerateNewBlocks(t, syncer.blockchain, tt.numNewBlocks)
+
+			for _, newBlock := range newBlocks {
+				assert.NoError(t, syncer.blockchain.WriteBlock(newBlock))
+			}
+
+			for _, newBlock := range newBlocks {
+				syncer.Broadcast(newBlock)
+			}
+
+			for _, peerSyncer := range peerSyncers {
+				for _, newBlock := range newBlocks {
+					block, ok := TryPopBlock(t, peerSyncer, syncer.server.AddrInfo().ID, 10*time.Second)
+					assert.True(t, ok, "peer should be able to pop new block from syncer")
+					assert.Equal(t, newBlock.Hash(), block.Hash(), "peer should get the blocks in order")
+				}
+			}
+		})
+	}
+}
+
+func TestFullBlockPeersCount(t *testing.T) {
+	tests := []struct {
+		numPeers int
+		expected int
+	}{
+		{0, 0},
+		{1, 1},
+		{2, 2},
+		{4, 2},
+		{10, 4},
+		{40, 7},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, fullBlockPeersCount(tt.numPeers))
+	}
+}
+
+// unresponsiveClient is a peer which never answers the header requests, until they are canceled
+type unresponsiveClient struct {
+	proto.V1Client
+}
+
+func (unresponsiveClient) GetHeaders(
+	ctx context.Context,
+	_ *proto.GetHeadersRequest,
+	_ ...grpc.CallOption,
+) (*proto.Response, error) {
+	<-ctx.Done()
+
+	return nil, ctx.Err()
+}
+
+func TestFetchBlockTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error, 1)
+
+	go func() {
+		_, err := fetchBlock(ctx, unresponsiveClient{}, types.StringToHash("1"))
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(5 * time.Second):
+		t.Fatal("fetching the block from an unresponsive peer should time out")
+	}
+}
+
+// serviceClient is a peer answering the requests from its store
+type serviceClient struct {
+	proto.V1Client
+	service *serviceV1
+}
+
+func (c serviceClient) GetHeaders(
+	ctx context.Context,
+	req *proto.GetHeadersRequest,
+	_ ...grpc.CallOption,
+) (*proto.Response, error) {
+	return c.service.GetHeaders(ctx, req)
+}
+
+func (c serviceClient) GetObjectsByHash(
+	ctx context.Context,
+	req *proto.HashRequest,
+	_ ...grpc.CallOption,
+) (*proto.Response, error) {
+	return c.service.GetObjectsByHash(ctx, req)
+}
+
+// announcedBlockStore is a chain which also holds an announced block, with the given body
+type announcedBlockStore struct {
+	*mockBlockchain
+
+	header *types.Header
+	body   *types.Body
+}
+
+func (b *announcedBlockStore) GetHeaderByHash(hash types.Hash) (*types.Header, bool) {
+	if hash == b.header.Hash {
+		return b.header, true
+	}
+
+	return b.mockBlockchain.GetHeaderByHash(hash)
+}
+
+func (b *announcedBlockStore) GetBodyByHash(types.Hash) (*types.Body, bool) {
+	return b.body, true
+}
+
+func TestAnnouncementInvalidBody(t *testing.T) {
+	servers := createNetworkServers(t, 1, defaultNetworkConfig)
+	headers := blockchain.NewTestHeaderChain(5)
+	syncer := NewSyncer(hclog.NewNullLogger(), servers[0], NewMockBlockchain(headers), NilMetrics())
+
+	t.Cleanup(func() {
+		assert.NoError(t, servers[0].Close())
+	})
+
+	txs := []*types.Transaction{
+		{Nonce: 0, GasPrice: big.NewInt(1), Gas: 21000, Value: big.NewInt(1), V: big.NewInt(27)},
+	}
+
+	header := &types.Header{
+		ParentHash: headers[4].Hash,
+		Number:     5,
+		TxRoot:     buildroot.CalculateTransactionsRoot(txs),
+		Sha3Uncles: types.EmptyUncleHash,
+	}
+	header.ComputeHash()
+
+	newPeer := func(id string, body *types.Body) *SyncPeer {
+		store := &announcedBlockStore{
+			mockBlockchain: NewMockBlockchain(headers),
+			header:         header,
+			body:           body,
+		}
+
+		p := &SyncPeer{
+			peer:      peer.ID(id),
+			client:    serviceClient{service: &serviceV1{store: store}},
+			enqueueCh: make(chan struct{}, 1),
+		}
+		syncer.peers.Store(p.peer, p)
+
+		return p
+	}
+
+	// the first peer announces the block, but serves a body not matching its header
+	faulty := newPeer("faulty", &types.Body{})
+	honest := newPeer("honest", &types.Body{Transactions: txs})
+
+	syncer.handleAnnouncement(faulty, header.Hash)
+
+	_, seen := syncer.seenBlocks.Get(header.Hash)
+	assert.False(t, seen, "the invalid block should not be remembered")
+	assert.Empty(t, faulty.enqueue)
+
+	// so the announcement from another peer fetches the block again
+	syncer.handleAnnouncement(honest, header.Hash)
+
+	_, seen = syncer.seenBlocks.Get(header.Hash)
+	assert.True(t, seen)
+
+	if assert.Len(t, honest.enqueue, 1) {
+		assert.Equal(t, header.Hash, honest.enqueue[0].Hash())
+		assert.Len(t, honest.enqueue[0].Transactions, 1)
+	}
+
+	t.Run("fetchBlock should reject a body not matching the header", func(t *testing.T) {
+		_, err := fetchBlock(context.Background(), faulty.client, header.Hash)
+		assert.ErrorIs(t, err, ErrInvalidBlockBody)
+	})
+}
+
 func TestBestPeer(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -374,6 +588,74 @@ func TestBulkSyncWithPeer(t *testing.T) {
 	}
 }
 
+func TestBulkSyncWithMultiplePeers(t *testing.T) {
+	headers := blockchain.NewTestHeaderChainWithSeed(nil, 10, 0)
+	peerHeaders := blockchain.NewTestHeaderChainWithSeed(nil, 450, 0)
+
+	chain := NewMockBlockchain(headers)
+	peerChains := []blockchainShim{
+		NewMockBlockchain(peerHeaders),
+		NewMockBlockchain(peerHeaders),
+		// faulty peer, its blocks never match the skeleton
+		NewMockBlockchain(blockchain.NewTestHeaderChainWithSeed(nil, 450, 1)),
+	}
+
+	syncer, peerSyncers := SetupSyncerNetwork(t, chain, peerChains)
+
+	var handledNewBlocks []*types.Block
+	newBlocksHandler := func(block *types.Block) {
+		handledNewBlocks = append(handledNewBlocks, block)
+	}
+
+	peer := getPeer(syncer, peerSyncers[0].server.AddrInfo().ID)
+	assert.NotNil(t, peer)
+
+	assert.NoError(t, syncer.BulkSyncWithPeer(peer, newBlocksHandler))
+	WaitUntilProcessedAllEvents(t, syncer, 10*time.Second)
+
+	expectedBlocks := blockchain.HeadersToBlocks(peerHeaders)
+
+	assert.Equal(t, expectedBlocks[len(headers):], handledNewBlocks, "not all blocks are handled in order")
+	assert.Equal(t, expectedBlocks, chain.blocks, "chain is not synced")
+}
+
+func TestFillSkeleton(t *testing.T) {
+	peerHeaders := blockchain.NewTestHeaderChainWithSeed(nil, 100, 0)
+
+	syncer := NewSyncer(hclog.NewNullLogger(), nil, NewMockBlockchain(peerHeaders), NilMetrics())
+
+	sk := &skeleton{
+		span: skeletonSpan,
+		num:  skeletonNum,
+	}
+	assert.NoError(t, sk.addSkeleton([]*types.Header{peerHeaders[1], peerHeaders[11], peerHeaders[21]}))
+
+	t.Run("should reject slots not linked to the next skeleton header", func(t *testing.T) {
+		headers := peerHeaders[1:10]
+
+		assert.ErrorIs(t, sk.validateSlotHeaders(0, headers), ErrInvalidSlot)
+	})
+
+	t.Run("should reject slots not starting at the skeleton header", func(t *testing.T) {
+		headers := peerHeaders[2:12]
+
+		assert.ErrorIs(t, sk.validateSlotHeaders(0, headers), ErrInvalidSlot)
+	})
+
+	t.Run("should accept valid slots", func(t *testing.T) {
+		assert.NoError(t, sk.validateSlotHeaders(0, peerHeaders[1:11]))
+		assert.NoError(t, sk.validateSlotHeaders(2, peerHeaders[21:25]))
+	})
+
+	t.Run("should fail if there are no peers", func(t *testing.T) {
+		err := syncer.fillSkeleton(sk, nil, map[peer.ID]bool{}, func(*slot) error {
+			return nil
+		})
+
+		assert.ErrorIs(t, err, ErrNoSyncPeers)
+	})
+}
+
 func TestSyncer_GetSyncProgression(t *testing.T) {
 	initialChainSize := 10
 	targetChainSize := 1000
@@ -545,7 +827,7 @@ func createSyncers(count int, servers []*network.Server, blockStores []*mockBloc
 	syncers := make([]*Syncer, count)
 
 	for indx := 0; indx < count; indx++ {
-		syncers[indx] = NewSyncer(hclog.NewNullLogger(), servers[indx], blockStores[indx])
+		syncers[indx] = NewSyncer(hclog.NewNullLogger(), servers[indx], blockStores[indx], NilMetrics())
 	}
 
 	return syncers
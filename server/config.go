@@ -6,6 +6,7 @@ import (
 	"github.com/hashicorp/go-hclog"
 
 	"github.com/juanidrobo/polygon-edge/chain"
+	"github.com/juanidrobo/polygon-edge/jsonrpc"
 	"github.com/juanidrobo/polygon-edge/network"
 	"github.com/juanidrobo/polygon-edge/secrets"
 )
@@ -23,8 +24,16 @@ type Config struct {
 
 	PriceLimit uint64
 	MaxSlots   uint64
+	NoTxGossip bool
 	BlockTime  uint64
 
+	// ParallelExecution is the number of workers executing the block transactions in parallel
+	ParallelExecution uint64
+
+	// StateSnapshotLayers is the number of block states kept as diff layers
+	// of the flat state snapshot, which is disabled if zero
+	StateSnapshotLayers uint64
+
 	Telemetry *Telemetry
 	Network   *network.Config
 
@@ -46,5 +55,15 @@ type Telemetry struct {
 // JSONRPC holds the config details for the JSON-RPC server
 type JSONRPC struct {
 	JSONRPCAddr              *net.TCPAddr
+	WSAddr                   *net.TCPAddr
+	IPCPath                  string
 	AccessControlAllowOrigin []string
+	GasPriceBlocks           uint64
+	GasPricePercentile       uint64
+	Limits                   jsonrpc.Limits
+	HTTPNamespaces           []string
+	WSNamespaces             []string
+	HTTPJWTSecret            []byte
+	WSJWTSecret              []byte
+	EnableAdmin              bool
 }
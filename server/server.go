@@ -19,11 +19,13 @@ import (
 	"github.com/juanidrobo/polygon-edge/state"
 	itrie "github.com/juanidrobo/polygon-edge/state/immutable-trie"
 	"github.com/juanidrobo/polygon-edge/state/runtime"
+	"github.com/juanidrobo/polygon-edge/state/runtime/allowlist"
 	"github.com/juanidrobo/polygon-edge/state/runtime/evm"
 	"github.com/juanidrobo/polygon-edge/state/runtime/precompiled"
 	"github.com/juanidrobo/polygon-edge/txpool"
 	"github.com/juanidrobo/polygon-edge/types"
 	"github.com/hashicorp/go-hclog"
+	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"google.golang.org/grpc"
@@ -40,6 +42,7 @@ type Server struct {
 	config       *Config
 	state        state.State
 	stateStorage itrie.Storage
+	trieState    *itrie.State
 
 	consensus consensus.Consensus
 
@@ -138,10 +141,28 @@ func NewServer(config *Config) (*Server, error) {
 
 	st := itrie.NewState(stateStorage)
 	m.state = st
+	m.trieState = st
+
+	if config.StateSnapshotLayers > 0 {
+		st.EnableSnapshot(int(config.StateSnapshotLayers))
+	}
 
 	m.executor = state.NewExecutor(config.Chain.Params, st, logger)
-	m.executor.SetRuntime(precompiled.NewPrecompiled())
+	precompiledRuntime := precompiled.NewPrecompiled()
+	if err := precompiledRuntime.SetupStatefulContracts(config.Chain.Params.StatefulPrecompiles); err != nil {
+		return nil, err
+	}
+
+	m.executor.SetRuntime(precompiledRuntime)
+
+	allowlists, err := allowlist.NewPolicy(config.Chain.Params.StatefulPrecompiles)
+	if err != nil {
+		return nil, err
+	}
+
+	m.executor.SetAllowlists(allowlists)
 	m.executor.SetRuntime(evm.NewEVM())
+	m.executor.SetParallelExecution(int(config.ParallelExecution))
 
 	// compute the genesis root state
 	genesisRoot := m.executor.WriteGenesis(config.Chain.Genesis.Alloc)
@@ -172,6 +193,7 @@ func NewServer(config *Config) (*Server, error) {
 				Sealing:    m.config.Seal,
 				MaxSlots:   m.config.MaxSlots,
 				PriceLimit: m.config.PriceLimit,
+				NoGossip:   m.config.NoTxGossip,
 			},
 		)
 		if err != nil {
@@ -181,6 +203,7 @@ func NewServer(config *Config) (*Server, error) {
 		// use the eip155 signer
 		signer := crypto.NewEIP155Signer(uint64(m.config.Chain.Params.ChainID))
 		m.txpool.SetSigner(signer)
+		m.txpool.SetAllowlists(allowlists)
 	}
 
 	{
@@ -213,6 +236,27 @@ func NewServer(config *Config) (*Server, error) {
 		return nil, err
 	}
 
+	if headRoot := m.blockchain.Header().StateRoot; config.StateSnapshotLayers > 0 && !st.HasSnapshot(headRoot) {
+		m.logger.Warn(
+			"the state snapshot does not cover the head state, regenerating it in the background, "+
+				"the state is read from the trie until it completes",
+			"root", headRoot,
+		)
+
+		st.GenerateSnapshot(headRoot, func(report *itrie.SnapshotReport, err error) {
+			if err != nil {
+				m.logger.Error("failed to regenerate the state snapshot", "err", err)
+
+				return
+			}
+
+			m.logger.Info(
+				"regenerated the state snapshot",
+				"root", report.Root, "accounts", report.Accounts, "slots", report.Slots,
+			)
+		})
+	}
+
 	// start consensus
 	if err := m.consensus.Start(); err != nil {
 		return nil, err
@@ -269,6 +313,15 @@ func (t *txpoolHub) GetNonce(root types.Hash, addr types.Address) uint64 {
 	return account.Nonce
 }
 
+func (t *txpoolHub) GetStorage(root types.Hash, addr types.Address, key types.Hash) types.Hash {
+	snap, err := t.state.NewSnapshotAt(root)
+	if err != nil {
+		return types.Hash{}
+	}
+
+	return state.NewTxn(t.state, snap).GetState(addr, key)
+}
+
 func (t *txpoolHub) GetBalance(root types.Hash, addr types.Address) (*big.Int, error) {
 	snap, err := t.state.NewSnapshotAt(root)
 	if err != nil {
@@ -364,6 +417,7 @@ func (s *Server) setupConsensus() error {
 			Grpc:           s.grpcServer,
 			Logger:         s.logger.Named("consensus"),
 			Metrics:        s.serverMetrics.consensus,
+			SyncerMetrics:  s.serverMetrics.syncer,
 			SecretsManager: s.secretsManager,
 			BlockTime:      s.config.BlockTime,
 		},
@@ -433,18 +487,41 @@ func (j *jsonRPCHub) GetForksInTime(blockNumber uint64) chain.ForksInTime {
 }
 
 func (j *jsonRPCHub) GetStorage(root types.Hash, addr types.Address, slot types.Hash) ([]byte, error) {
-	account, err := j.GetAccount(root, addr)
-
+	snap, err := j.state.NewSnapshotAt(root)
 	if err != nil {
 		return nil, err
 	}
 
-	obj, err := j.getState(account.Root, slot.Bytes())
+	addrHash := keccak.Keccak256(nil, addr.Bytes())
+
+	data, ok := snap.Get(addrHash)
+	if !ok {
+		return nil, jsonrpc.ErrStateNotFound
+	}
+
+	var account state.Account
+	if err := account.UnmarshalRlp(data); err != nil {
+		return nil, err
+	}
+
+	// read the storage from the flat snapshot if the state has one
+	var storage state.StorageReader
+
+	if storageSnap, ok := snap.(state.StorageSnapshot); ok {
+		storage, err = storageSnap.Storage(types.BytesToHash(addrHash), account.Root)
+	} else {
+		storage, err = j.state.NewSnapshotAt(account.Root)
+	}
 
 	if err != nil {
 		return nil, err
 	}
 
+	obj, ok := storage.Get(keccak.Keccak256(nil, slot.Bytes()))
+	if !ok {
+		return nil, jsonrpc.ErrStateNotFound
+	}
+
 	return obj, nil
 }
 
@@ -459,23 +536,126 @@ func (j *jsonRPCHub) GetCode(hash types.Hash) ([]byte, error) {
 }
 
 func (j *jsonRPCHub) ApplyTxn(
+	ctx context.Context,
 	header *types.Header,
 	txn *types.Transaction,
+	stateOverride types.StateOverride,
+	blockOverride *types.BlockOverride,
 ) (result *runtime.ExecutionResult, err error) {
+	transition, err := j.beginCallTxn(ctx, header, stateOverride, blockOverride)
+	if err != nil {
+		return
+	}
+
+	result, err = transition.Apply(txn)
+
+	return
+}
+
+func (j *jsonRPCHub) ApplyTxnWithAccessList(
+	ctx context.Context,
+	header *types.Header,
+	txn *types.Transaction,
+	accessList types.AccessList,
+) (types.AccessList, *runtime.ExecutionResult, error) {
+	transition, err := j.beginCallTxn(ctx, header, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return transition.ApplyWithAccessList(txn, accessList)
+}
+
+// beginCallTxn begins the transition of a call on top of the header, with the given overrides
+func (j *jsonRPCHub) beginCallTxn(
+	ctx context.Context,
+	header *types.Header,
+	stateOverride types.StateOverride,
+	blockOverride *types.BlockOverride,
+) (*state.Transition, error) {
 	blockCreator, err := j.GetConsensus().GetBlockCreator(header)
 	if err != nil {
 		return nil, err
 	}
 
 	transition, err := j.BeginTxn(header.StateRoot, header, blockCreator)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := transition.WithStateOverride(stateOverride); err != nil {
+		return nil, err
+	}
+
+	transition.WithBlockOverride(blockOverride)
+	transition.WithContext(ctx)
+
+	return transition, nil
+}
+
+// GetLocalPeerInfo returns the libp2p ID and listen addresses of the node
+func (j *jsonRPCHub) GetLocalPeerInfo() *jsonrpc.PeerInfo {
+	info := j.Server.AddrInfo()
+
+	addrs := make([]string, 0, len(info.Addrs))
+	for _, addr := range info.Addrs {
+		addrs = append(addrs, addr.String())
+	}
+
+	return &jsonrpc.PeerInfo{
+		ID:    info.ID.String(),
+		Addrs: addrs,
+	}
+}
 
+// GetPeersInfo returns the connected peers, with their addresses and supported protocols
+func (j *jsonRPCHub) GetPeersInfo() ([]*jsonrpc.PeerInfo, error) {
+	peers := j.Server.Peers()
+	peersInfo := make([]*jsonrpc.PeerInfo, 0, len(peers))
+
+	for _, p := range peers {
+		protocols, err := j.Server.GetProtocols(p.Info.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		addrs := []string{}
+		for _, addr := range j.Server.GetPeerInfo(p.Info.ID).Addrs {
+			addrs = append(addrs, addr.String())
+		}
+
+		peersInfo = append(peersInfo, &jsonrpc.PeerInfo{
+			ID:        p.Info.ID.String(),
+			Addrs:     addrs,
+			Protocols: protocols,
+		})
+	}
+
+	return peersInfo, nil
+}
+
+// RemovePeer disconnects from the peer with the given ID or multiaddr,
+// returning false if it wasn't connected
+func (j *jsonRPCHub) RemovePeer(rawPeer string) (bool, error) {
+	peerID, err := peer.Decode(rawPeer)
 	if err != nil {
-		return
+		info, infoErr := peer.AddrInfoFromString(rawPeer)
+		if infoErr != nil {
+			return false, fmt.Errorf("invalid peer ID or multiaddr %s: %w", rawPeer, err)
+		}
+
+		peerID = info.ID
 	}
 
-	result, err = transition.Apply(txn)
+	for _, p := range j.Server.Peers() {
+		if p.Info.ID == peerID {
+			j.Server.DisconnectFromPeer(peerID, "removed by the admin")
 
-	return
+			return true, nil
+		}
+	}
+
+	return false, nil
 }
 
 func (j *jsonRPCHub) GetSyncProgression() *progress.Progression {
@@ -509,8 +689,19 @@ func (s *Server) setupJSONRPC() error {
 	conf := &jsonrpc.Config{
 		Store:                    hub,
 		Addr:                     s.config.JSONRPC.JSONRPCAddr,
+		WSAddr:                   s.config.JSONRPC.WSAddr,
+		IPCPath:                  s.config.JSONRPC.IPCPath,
 		ChainID:                  uint64(s.config.Chain.Params.ChainID),
 		AccessControlAllowOrigin: s.config.JSONRPC.AccessControlAllowOrigin,
+		GasPriceBlocks:           s.config.JSONRPC.GasPriceBlocks,
+		GasPricePercentile:       s.config.JSONRPC.GasPricePercentile,
+		Limits:                   s.config.JSONRPC.Limits,
+		HTTPNamespaces:           s.config.JSONRPC.HTTPNamespaces,
+		WSNamespaces:             s.config.JSONRPC.WSNamespaces,
+		HTTPJWTSecret:            s.config.JSONRPC.HTTPJWTSecret,
+		WSJWTSecret:              s.config.JSONRPC.WSJWTSecret,
+		EnableAdmin:              s.config.JSONRPC.EnableAdmin,
+		DataDir:                  s.config.DataDir,
 	}
 
 	srv, err := jsonrpc.NewJSONRPC(s.logger, conf)
@@ -570,6 +761,14 @@ func (s *Server) Close() {
 		s.logger.Error("failed to close consensus", "err", err.Error())
 	}
 
+	// Write the state snapshot down to the head state, so that it is usable on restart.
+	// If it was still being regenerated, it is regenerated again on restart
+	if s.trieState.StopSnapshotGeneration() {
+		s.logger.Info("stopped regenerating the state snapshot")
+	} else if err := s.trieState.FlattenSnapshot(s.blockchain.Header().StateRoot); err != nil {
+		s.logger.Error("failed to flatten the state snapshot", "err", err.Error())
+	}
+
 	// Close the state storage
 	if err := s.stateStorage.Close(); err != nil {
 		s.logger.Error("failed to close storage for trie", "err", err.Error())
@@ -583,6 +782,13 @@ func (s *Server) Close() {
 
 	// close the txpool's main loop
 	s.txpool.Close()
+
+	// stop serving the JSON-RPC IPC endpoint
+	if s.jsonrpcServer != nil {
+		if err := s.jsonrpcServer.Close(); err != nil {
+			s.logger.Error("failed to close JSON-RPC IPC endpoint", "err", err.Error())
+		}
+	}
 }
 
 // Entry is a backend configuration entry
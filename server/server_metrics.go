@@ -3,6 +3,7 @@ package server
 import (
 	"github.com/juanidrobo/polygon-edge/consensus"
 	"github.com/juanidrobo/polygon-edge/network"
+	"github.com/juanidrobo/polygon-edge/protocol"
 	"github.com/juanidrobo/polygon-edge/txpool"
 )
 
@@ -10,6 +11,7 @@ import (
 type serverMetrics struct {
 	consensus *consensus.Metrics
 	network   *network.Metrics
+	syncer    *protocol.Metrics
 	txpool    *txpool.Metrics
 }
 
@@ -19,6 +21,7 @@ func metricProvider(nameSpace string, chainID string, metricsRequired bool) *ser
 		return &serverMetrics{
 			consensus: consensus.GetPrometheusMetrics(nameSpace, "chain_id", chainID),
 			network:   network.GetPrometheusMetrics(nameSpace, "chain_id", chainID),
+			syncer:    protocol.GetPrometheusMetrics(nameSpace, "chain_id", chainID),
 			txpool:    txpool.GetPrometheusMetrics(nameSpace, "chain_id", chainID),
 		}
 	}
@@ -26,6 +29,7 @@ func metricProvider(nameSpace string, chainID string, metricsRequired bool) *ser
 	return &serverMetrics{
 		consensus: consensus.NilMetrics(),
 		network:   network.NilMetrics(),
+		syncer:    protocol.NilMetrics(),
 		txpool:    txpool.NilMetrics(),
 	}
 }
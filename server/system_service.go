@@ -26,6 +26,8 @@ type systemService struct {
 // Current: { Number: <blockNumber>; Hash: <headerHash> }
 //
 // P2PAddr: <libp2pAddress>
+//
+// Reachability: <reachability>
 func (s *systemService) GetStatus(ctx context.Context, req *empty.Empty) (*proto.ServerStatus, error) {
 	header := s.server.blockchain.Header()
 
@@ -35,7 +37,8 @@ func (s *systemService) GetStatus(ctx context.Context, req *empty.Empty) (*proto
 			Number: int64(header.Number),
 			Hash:   header.Hash.String(),
 		},
-		P2PAddr: common.AddrInfoToString(s.server.network.AddrInfo()),
+		P2PAddr:      common.AddrInfoToString(s.server.network.AddrInfo()),
+		Reachability: s.server.network.Reachability().String(),
 	}
 
 	return status, nil
@@ -124,10 +127,14 @@ func (s *systemService) getPeer(id peer.ID) (*proto.Peer, error) {
 		addrs = append(addrs, addr.String())
 	}
 
+	bandwidth := s.server.network.GetPeerBandwidth(id)
+
 	peer := &proto.Peer{
 		Id:        id.String(),
 		Protocols: protocols,
 		Addrs:     addrs,
+		BytesIn:   uint64(bandwidth.TotalIn),
+		BytesOut:  uint64(bandwidth.TotalOut),
 	}
 
 	return peer, nil
@@ -0,0 +1,199 @@
+package state
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+
+	"github.com/juanidrobo/polygon-edge/chain"
+	"github.com/juanidrobo/polygon-edge/crypto"
+	"github.com/juanidrobo/polygon-edge/state/runtime"
+	"github.com/juanidrobo/polygon-edge/types"
+)
+
+// accessListHost is a host recording the accounts and the storage slots accessed by the execution.
+// The sender, the recipient and the precompiled contracts are left out, as they are always accessed
+type accessListHost struct {
+	runtime.Host
+
+	t        *Transition
+	excluded map[types.Address]struct{}
+	accounts map[types.Address]map[types.Hash]struct{}
+}
+
+func newAccessListHost(t *Transition, msg *types.Transaction, list types.AccessList) *accessListHost {
+	h := &accessListHost{
+		Host:     t,
+		t:        t,
+		excluded: map[types.Address]struct{}{msg.From: {}},
+		accounts: map[types.Address]map[types.Hash]struct{}{},
+	}
+
+	if msg.IsContractCreation() {
+		h.excluded[crypto.CreateAddress(msg.From, msg.Nonce)] = struct{}{}
+	} else {
+		h.excluded[*msg.To] = struct{}{}
+	}
+
+	for _, tuple := range list {
+		h.addAddress(tuple.Address)
+
+		for _, key := range tuple.StorageKeys {
+			h.addSlot(tuple.Address, key)
+		}
+	}
+
+	return h
+}
+
+func (h *accessListHost) addAddress(addr types.Address) {
+	if _, ok := h.accounts[addr]; !ok {
+		h.accounts[addr] = map[types.Hash]struct{}{}
+	}
+}
+
+func (h *accessListHost) addSlot(addr types.Address, key types.Hash) {
+	h.addAddress(addr)
+	h.accounts[addr][key] = struct{}{}
+}
+
+// accessList returns the recorded access list, sorted by address and storage slot
+func (h *accessListHost) accessList() types.AccessList {
+	list := types.AccessList{}
+
+	for addr, slots := range h.accounts {
+		_, excluded := h.excluded[addr]
+		if excluded && len(slots) == 0 {
+			continue
+		}
+
+		if len(slots) == 0 && h.t.isPrecompiled(addr) {
+			continue
+		}
+
+		tuple := types.AccessTuple{
+			Address:     addr,
+			StorageKeys: make([]types.Hash, 0, len(slots)),
+		}
+
+		for key := range slots {
+			tuple.StorageKeys = append(tuple.StorageKeys, key)
+		}
+
+		sort.Slice(tuple.StorageKeys, func(i, j int) bool {
+			return bytes.Compare(tuple.StorageKeys[i].Bytes(), tuple.StorageKeys[j].Bytes()) < 0
+		})
+
+		list = append(list, tuple)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return bytes.Compare(list[i].Address.Bytes(), list[j].Address.Bytes()) < 0
+	})
+
+	return list
+}
+
+func (h *accessListHost) AccountExists(addr types.Address) bool {
+	h.addAddress(addr)
+
+	return h.Host.AccountExists(addr)
+}
+
+func (h *accessListHost) GetStorage(addr types.Address, key types.Hash) types.Hash {
+	h.addSlot(addr, key)
+
+	return h.Host.GetStorage(addr, key)
+}
+
+func (h *accessListHost) SetStorage(
+	addr types.Address,
+	key types.Hash,
+	value types.Hash,
+	config *chain.ForksInTime,
+) runtime.StorageStatus {
+	h.addSlot(addr, key)
+
+	return h.Host.SetStorage(addr, key, value, config)
+}
+
+func (h *accessListHost) GetBalance(addr types.Address) *big.Int {
+	h.addAddress(addr)
+
+	return h.Host.GetBalance(addr)
+}
+
+func (h *accessListHost) GetCodeSize(addr types.Address) int {
+	h.addAddress(addr)
+
+	return h.Host.GetCodeSize(addr)
+}
+
+func (h *accessListHost) GetCodeHash(addr types.Address) types.Hash {
+	h.addAddress(addr)
+
+	return h.Host.GetCodeHash(addr)
+}
+
+func (h *accessListHost) GetCode(addr types.Address) []byte {
+	h.addAddress(addr)
+
+	return h.Host.GetCode(addr)
+}
+
+func (h *accessListHost) Selfdestruct(addr types.Address, beneficiary types.Address) {
+	h.addAddress(beneficiary)
+
+	h.Host.Selfdestruct(addr, beneficiary)
+}
+
+func (h *accessListHost) Empty(addr types.Address) bool {
+	h.addAddress(addr)
+
+	return h.Host.Empty(addr)
+}
+
+func (h *accessListHost) Callx(c *runtime.Contract, host runtime.Host) *runtime.ExecutionResult {
+	// the addresses of the created contracts are not accessed before their creation
+	if c.Type != runtime.Create && c.Type != runtime.Create2 {
+		h.addAddress(c.Address)
+		h.addAddress(c.CodeAddress)
+	}
+
+	return h.Host.Callx(c, host)
+}
+
+// isPrecompiled checks if the account is run by one of the runtimes other than the EVM
+func (t *Transition) isPrecompiled(addr types.Address) bool {
+	c := &runtime.Contract{Address: addr, CodeAddress: addr}
+
+	for _, r := range t.r.runtimes {
+		if r.CanRun(c, t, &t.config) {
+			return r.Name() != "evm"
+		}
+	}
+
+	return false
+}
+
+// ApplyWithAccessList applies the transaction like Apply, through a host recording the accounts and
+// the storage slots accessed by the execution. It returns them along with the ones of the given list.
+// There are no access list transactions, so the list is not charged and does not change the execution
+func (t *Transition) ApplyWithAccessList(
+	msg *types.Transaction,
+	list types.AccessList,
+) (types.AccessList, *runtime.ExecutionResult, error) {
+	host := newAccessListHost(t, msg, list)
+
+	t.host = host
+	defer func() {
+		t.host = nil
+	}()
+
+	result, err := t.Apply(msg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return host.accessList(), result, nil
+}
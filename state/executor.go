@@ -1,16 +1,19 @@
 package state
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math"
 	"math/big"
+	"sync/atomic"
 
 	"github.com/hashicorp/go-hclog"
 
 	"github.com/juanidrobo/polygon-edge/chain"
 	"github.com/juanidrobo/polygon-edge/crypto"
 	"github.com/juanidrobo/polygon-edge/state/runtime"
+	"github.com/juanidrobo/polygon-edge/state/runtime/allowlist"
 	"github.com/juanidrobo/polygon-edge/types"
 )
 
@@ -36,6 +39,12 @@ type Executor struct {
 	state    State
 	GetHash  GetHashByNumberHelper
 
+	// allowlists restricts the transaction senders and the contract deployers
+	allowlists *allowlist.Policy
+
+	// parallelWorkers is the number of workers executing the block transactions in parallel
+	parallelWorkers int
+
 	PostHook func(txn *Transition)
 }
 
@@ -71,6 +80,8 @@ func (e *Executor) WriteGenesis(alloc map[types.Address]*chain.GenesisAccount) t
 		}
 	}
 
+	e.enableStatefulPrecompiles(txn, 0)
+
 	_, root := txn.Commit(false)
 
 	return types.BytesToHash(root)
@@ -81,6 +92,29 @@ func (e *Executor) SetRuntime(r runtime.Runtime) {
 	e.runtimes = append(e.runtimes, r)
 }
 
+// SetAllowlists sets the policy of the allowlists enforced on the transactions
+func (e *Executor) SetAllowlists(p *allowlist.Policy) {
+	e.allowlists = p
+}
+
+// SetParallelExecution enables the optimistic parallel execution of the block transactions
+// with the given number of workers. Less than two workers disable it.
+// It only applies to ProcessBlock: the transitions started with BeginTxn, as when building a block, are sequential
+func (e *Executor) SetParallelExecution(workers int) {
+	e.parallelWorkers = workers
+}
+
+// enableStatefulPrecompiles sets up the accounts of the stateful precompiled contracts enabled at the block.
+// They get a nonce, so that they are not deleted (along with their storage) as empty accounts
+func (e *Executor) enableStatefulPrecompiles(txn *Txn, block uint64) {
+	for _, p := range e.config.StatefulPrecompiles {
+		if p.Block.Active(block) && txn.GetNonce(p.Address) == 0 {
+			txn.SetNonce(p.Address, 1)
+			e.allowlists.Init(txn, p.Address)
+		}
+	}
+}
+
 type BlockResult struct {
 	Root     types.Hash
 	Receipts []*types.Receipt
@@ -93,6 +127,16 @@ func (e *Executor) ProcessBlock(
 	block *types.Block,
 	blockCreator types.Address,
 ) (*Transition, error) {
+	if e.parallelWorkers > 1 {
+		txn, err := e.processBlockParallel(parentRoot, block, blockCreator)
+		if err == nil {
+			return txn, nil
+		}
+
+		// the block is executed again sequentially, which also reports the error of an invalid block
+		e.logger.Debug("falling back to the sequential execution", "block", block.Number(), "err", err)
+	}
+
 	txn, err := e.BeginTxn(parentRoot, block.Header, blockCreator)
 	if err != nil {
 		return nil, err
@@ -146,6 +190,8 @@ func (e *Executor) BeginTxn(
 
 	newTxn := NewTxn(e.state, auxSnap2)
 
+	e.enableStatefulPrecompiles(newTxn, header.Number)
+
 	env2 := runtime.TxContext{
 		Coinbase:   coinbaseReceiver,
 		Timestamp:  int64(header.Timestamp),
@@ -188,6 +234,16 @@ type Transition struct {
 	ctx     runtime.TxContext
 	gasPool uint64
 
+	// the execution is aborted once the context is done
+	abortCtx context.Context
+	aborted  uint32
+
+	// deferCoinbaseFee leaves the payment of the coinbase to the parallel execution
+	deferCoinbaseFee bool
+
+	// host wraps the transition as the host of the transaction execution, if set
+	host runtime.Host
+
 	// result
 	receipts []*types.Receipt
 	totalGas uint64
@@ -256,15 +312,20 @@ func (t *Transition) Write(txn *types.Transaction) error {
 		return e
 	}
 
-	t.totalGas += result.GasUsed
+	t.writeReceipt(msg, result, t.state.Logs())
 
-	logs := t.state.Logs()
+	return nil
+}
+
+// writeReceipt appends the receipt of the applied transaction, with its logs
+func (t *Transition) writeReceipt(msg *types.Transaction, result *runtime.ExecutionResult, logs []*types.Log) {
+	t.totalGas += result.GasUsed
 
 	var root []byte
 
 	receipt := &types.Receipt{
 		CumulativeGasUsed: t.totalGas,
-		TxHash:            txn.Hash,
+		TxHash:            msg.Hash,
 		GasUsed:           result.GasUsed,
 	}
 
@@ -286,15 +347,13 @@ func (t *Transition) Write(txn *types.Transaction) error {
 
 	// if the transaction created a contract, store the creation address in the receipt.
 	if msg.To == nil {
-		receipt.ContractAddress = crypto.CreateAddress(msg.From, txn.Nonce)
+		receipt.ContractAddress = crypto.CreateAddress(msg.From, msg.Nonce)
 	}
 
 	// Set the receipt logs and create a bloom for filtering
 	receipt.Logs = logs
 	receipt.LogsBloom = types.CreateBloom([]*types.Receipt{receipt})
 	t.receipts = append(t.receipts, receipt)
-
-	return nil
 }
 
 // Commit commits the final result
@@ -332,9 +391,18 @@ func (t *Transition) GetTxnHash() types.Hash {
 
 // Apply applies a new transaction
 func (t *Transition) Apply(msg *types.Transaction) (*runtime.ExecutionResult, error) {
+	if t.abortCtx != nil {
+		stop := t.watchAbort()
+		defer stop()
+	}
+
 	s := t.state.Snapshot() //nolint:ifshort
 	result, err := t.apply(msg)
 
+	if err == nil && errors.Is(result.Err, runtime.ErrExecutionAborted) {
+		err = fmt.Errorf("%w: %v", runtime.ErrExecutionAborted, t.abortCtx.Err())
+	}
+
 	if err != nil {
 		t.state.RevertToSnapshot(s)
 	}
@@ -346,6 +414,94 @@ func (t *Transition) Apply(msg *types.Transaction) (*runtime.ExecutionResult, er
 	return result, err
 }
 
+// WithContext binds the execution of the transition to the context,
+// the running transaction is aborted once the context is done (i.e. on timeout)
+func (t *Transition) WithContext(ctx context.Context) {
+	t.abortCtx = ctx
+}
+
+// watchAbort raises the abort flag once the context is done. The returned function stops watching
+func (t *Transition) watchAbort() func() {
+	stopCh := make(chan struct{})
+
+	go func() {
+		select {
+		case <-t.abortCtx.Done():
+			atomic.StoreUint32(&t.aborted, 1)
+		case <-stopCh:
+		}
+	}()
+
+	return func() {
+		close(stopCh)
+	}
+}
+
+// Aborted returns whether the execution of the transition has been aborted
+func (t *Transition) Aborted() bool {
+	return atomic.LoadUint32(&t.aborted) == 1
+}
+
+// WithStateOverride applies the account overrides on top of the transition state.
+// It is used to simulate calls, the overridden state is never committed
+func (t *Transition) WithStateOverride(override types.StateOverride) error {
+	for addr, account := range override {
+		if account.State != nil && account.StateDiff != nil {
+			return fmt.Errorf("%w: %s", ErrStateOverrideConflict, addr)
+		}
+
+		if account.Nonce != nil {
+			t.state.SetNonce(addr, *account.Nonce)
+		}
+
+		if account.Balance != nil {
+			t.state.SetBalance(addr, account.Balance)
+		}
+
+		if account.Code != nil {
+			t.state.SetCode(addr, account.Code)
+		}
+
+		if account.State != nil {
+			t.state.SetFullStorage(addr, account.State)
+		}
+
+		for key, value := range account.StateDiff {
+			t.state.SetState(addr, key, value)
+		}
+	}
+
+	return nil
+}
+
+// WithBlockOverride overrides the block context fields of the transition
+func (t *Transition) WithBlockOverride(override *types.BlockOverride) {
+	if override == nil {
+		return
+	}
+
+	if override.Number != nil {
+		t.ctx.Number = int64(*override.Number)
+	}
+
+	if override.Timestamp != nil {
+		t.ctx.Timestamp = int64(*override.Timestamp)
+	}
+
+	if override.GasLimit != nil {
+		t.ctx.GasLimit = int64(*override.GasLimit)
+		t.gasPool = *override.GasLimit
+	}
+
+	if override.Coinbase != nil {
+		t.ctx.Coinbase = *override.Coinbase
+	}
+
+	if override.Difficulty != nil {
+		t.ctx.Difficulty = types.BytesToHash(override.Difficulty.Bytes())
+	}
+}
+
 // ContextPtr returns reference of context
 // This method is called only by test
 func (t *Transition) ContextPtr() *runtime.TxContext {
@@ -388,6 +544,7 @@ var (
 	ErrIntrinsicGasOverflow  = fmt.Errorf("overflow in intrinsic gas calculation")
 	ErrNotEnoughIntrinsicGas = fmt.Errorf("not enough gas supplied for intrinsic gas costs")
 	ErrNotEnoughFunds        = fmt.Errorf("not enough funds for transfer with given value")
+	ErrStateOverrideConflict = fmt.Errorf("both state and stateDiff overridden")
 )
 
 type TransitionApplicationError struct {
@@ -426,6 +583,8 @@ func (t *Transition) apply(msg *types.Transaction) (*runtime.ExecutionResult, er
 	// 4. there is no overflow when calculating intrinsic gas
 	// 5. the purchased gas is enough to cover intrinsic usage
 	// 6. caller has enough balance to cover asset transfer for **topmost** call
+	// 7. the initcode of a contract creation doesn't exceed the size limit
+	// 8. caller is allowed to send transactions, and to deploy contracts, by the allowlists
 	txn := t.state
 
 	// 1. the nonce of the message caller is correct
@@ -444,7 +603,7 @@ func (t *Transition) apply(msg *types.Transaction) (*runtime.ExecutionResult, er
 	}
 
 	// 4. there is no overflow when calculating intrinsic gas
-	intrinsicGasCost, err := TransactionGasCost(msg, t.config.Homestead, t.config.Istanbul)
+	intrinsicGasCost, err := TransactionGasCost(msg, t.config.Homestead, t.config.Istanbul, t.config.EIP3860)
 	if err != nil {
 		return nil, NewTransitionApplicationError(err, false)
 	}
@@ -461,6 +620,22 @@ func (t *Transition) apply(msg *types.Transaction) (*runtime.ExecutionResult, er
 		return nil, NewTransitionApplicationError(ErrNotEnoughFunds, true)
 	}
 
+	// 7. the initcode doesn't exceed the size limit of eip-3860
+	if t.config.EIP3860 && msg.IsContractCreation() && len(msg.Input) > runtime.MaxInitCodeSize {
+		return nil, NewTransitionApplicationError(runtime.ErrMaxInitCodeSizeExceeded, false)
+	}
+
+	// 8. caller is allowed to send transactions, and to deploy contracts, by the allowlists
+	if err := t.r.allowlists.CheckSender(txn, uint64(t.ctx.Number), msg.From); err != nil {
+		return nil, NewTransitionApplicationError(err, false)
+	}
+
+	if msg.IsContractCreation() {
+		if err := t.r.allowlists.CheckDeployer(txn, uint64(t.ctx.Number), msg.From); err != nil {
+			return nil, NewTransitionApplicationError(err, false)
+		}
+	}
+
 	gasPrice := new(big.Int).Set(msg.GasPrice)
 	value := new(big.Int).Set(msg.Value)
 
@@ -476,16 +651,23 @@ func (t *Transition) apply(msg *types.Transaction) (*runtime.ExecutionResult, er
 		result = t.Call2(msg.From, *msg.To, msg.Input, value, gasLeft)
 	}
 
+	refundQuotient := runtime.RefundQuotient
+	if t.config.EIP3529 {
+		refundQuotient = runtime.RefundQuotientEIP3529
+	}
+
 	refund := txn.GetRefund()
-	result.UpdateGasUsed(msg.Gas, refund)
+	result.UpdateGasUsed(msg.Gas, refund, refundQuotient)
 
 	// refund the sender
 	remaining := new(big.Int).Mul(new(big.Int).SetUint64(result.GasLeft), gasPrice)
 	txn.AddBalance(msg.From, remaining)
 
-	// pay the coinbase
+	// pay the coinbase, unless the fees are collected once the parallel execution is merged
 	coinbaseFee := new(big.Int).Mul(new(big.Int).SetUint64(result.GasUsed), gasPrice)
-	txn.AddBalance(t.ctx.Coinbase, coinbaseFee)
+	if !t.deferCoinbaseFee {
+		txn.AddBalance(t.ctx.Coinbase, coinbaseFee)
+	}
 
 	// return gas to the pool
 	t.addGasPool(result.GasLeft)
@@ -502,7 +684,7 @@ func (t *Transition) Create2(
 	address := crypto.CreateAddress(caller, t.state.GetNonce(caller))
 	contract := runtime.NewContractCreation(1, caller, caller, address, value, gas, code)
 
-	return t.applyCreate(contract, t)
+	return t.applyCreate(contract, t.getHost())
 }
 
 func (t *Transition) Call2(
@@ -513,8 +695,18 @@ func (t *Transition) Call2(
 	gas uint64,
 ) *runtime.ExecutionResult {
 	c := runtime.NewContractCall(1, caller, caller, to, value, gas, t.state.GetCode(to), input)
+	c.CodeHash = t.state.GetCodeHash(to)
+
+	return t.applyCall(c, runtime.Call, t.getHost())
+}
+
+// getHost returns the host of the transaction execution, the transition itself unless it is wrapped
+func (t *Transition) getHost() runtime.Host {
+	if t.host != nil {
+		return t.host
+	}
 
-	return t.applyCall(c, runtime.Call, t)
+	return t
 }
 
 func (t *Transition) run(contract *runtime.Contract, host runtime.Host) *runtime.ExecutionResult {
@@ -608,6 +800,14 @@ func (t *Transition) applyCreate(c *runtime.Contract, host runtime.Host) *runtim
 		}
 	}
 
+	// Contracts can only be deployed by the transactions of the allowed senders
+	if err := t.r.allowlists.CheckDeployer(t.state, uint64(t.ctx.Number), c.Origin); err != nil {
+		return &runtime.ExecutionResult{
+			GasLeft: 0,
+			Err:     err,
+		}
+	}
+
 	// Increment the nonce of the caller
 	t.state.IncrNonce(c.Caller)
 
@@ -654,6 +854,16 @@ func (t *Transition) applyCreate(c *runtime.Contract, host runtime.Host) *runtim
 		}
 	}
 
+	if t.config.EIP3541 && len(result.ReturnValue) > 0 && result.ReturnValue[0] == 0xEF {
+		// New code starting with 0xEF is rejected by eip-3541
+		t.state.RevertToSnapshot(snapshot)
+
+		return &runtime.ExecutionResult{
+			GasLeft: 0,
+			Err:     runtime.ErrInvalidCodePrefix,
+		}
+	}
+
 	gasCost := uint64(len(result.ReturnValue)) * 200
 
 	if result.GasLeft < gasCost {
@@ -685,6 +895,14 @@ func (t *Transition) SetStorage(
 	return t.state.SetStorage(addr, key, value, config)
 }
 
+func (t *Transition) GetTransientStorage(addr types.Address, key types.Hash) types.Hash {
+	return t.state.GetTransientState(addr, key)
+}
+
+func (t *Transition) SetTransientStorage(addr types.Address, key types.Hash, value types.Hash) {
+	t.state.SetTransientState(addr, key, value)
+}
+
 func (t *Transition) GetTxContext() runtime.TxContext {
 	return t.ctx
 }
@@ -730,7 +948,8 @@ func (t *Transition) GetNonce(addr types.Address) uint64 {
 }
 
 func (t *Transition) Selfdestruct(addr types.Address, beneficiary types.Address) {
-	if !t.state.HasSuicided(addr) {
+	// the refund is removed by eip-3529
+	if !t.config.EIP3529 && !t.state.HasSuicided(addr) {
 		t.state.AddRefund(24000)
 	}
 
@@ -765,7 +984,7 @@ func (t *Transition) SetAccountDirectly(addr types.Address, account *chain.Genes
 	return nil
 }
 
-func TransactionGasCost(msg *types.Transaction, isHomestead, isIstanbul bool) (uint64, error) {
+func TransactionGasCost(msg *types.Transaction, isHomestead, isIstanbul, isEIP3860 bool) (uint64, error) {
 	cost := uint64(0)
 
 	// Contract creation is only paid on the homestead fork
@@ -775,6 +994,11 @@ func TransactionGasCost(msg *types.Transaction, isHomestead, isIstanbul bool) (u
 		cost += TxGas
 	}
 
+	// Every word of the initcode is paid since eip-3860
+	if msg.IsContractCreation() && isEIP3860 {
+		cost += runtime.InitCodeWordGas * ((uint64(len(msg.Input)) + 31) / 32)
+	}
+
 	payload := msg.Input
 	if len(payload) > 0 {
 		zeros := uint64(0)
@@ -78,3 +78,14 @@ func decodeCompact(compact []byte) []byte {
 
 	return base
 }
+
+// hexNibblesToBytes packs an even sequence of nibbles
+// (terminator flag removed) into bytes.
+func hexNibblesToBytes(nibbles []byte) []byte {
+	bytes := make([]byte, len(nibbles)/2)
+	for i := range bytes {
+		bytes[i] = nibbles[2*i]<<4 | nibbles[2*i+1]
+	}
+
+	return bytes
+}
@@ -0,0 +1,478 @@
+package itrie
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/juanidrobo/polygon-edge/state"
+	"github.com/juanidrobo/polygon-edge/types"
+)
+
+var (
+	// snapshotAccountPrefix is the prefix of the flat accounts, keyed by the hashed address
+	snapshotAccountPrefix = []byte("sa")
+
+	// snapshotStoragePrefix is the prefix of the flat storage slots, keyed by the hashed address and slot
+	snapshotStoragePrefix = []byte("ss")
+
+	// snapshotRootKey is the key of the state root of the flat entries on disk
+	snapshotRootKey = []byte("snapshot-root")
+)
+
+var (
+	errSnapshotStale      = errors.New("snapshot layer is stale")
+	errSnapshotNotFound   = errors.New("snapshot layer not found")
+	errSnapshotGenerating = errors.New("snapshot is being generated")
+	errSnapshotAborted    = errors.New("snapshot generation aborted")
+)
+
+func snapshotAccountKey(hash types.Hash) []byte {
+	key := make([]byte, 0, len(snapshotAccountPrefix)+types.HashLength)
+	key = append(key, snapshotAccountPrefix...)
+
+	return append(key, hash.Bytes()...)
+}
+
+func snapshotStorageKey(hash types.Hash, slot types.Hash) []byte {
+	key := make([]byte, 0, len(snapshotStoragePrefix)+2*types.HashLength)
+	key = append(key, snapshotStoragePrefix...)
+	key = append(key, hash.Bytes()...)
+
+	return append(key, slot.Bytes()...)
+}
+
+// snapshotLayer is a layer of the flat snapshot. The bottom layer reads the flat
+// entries on disk, the other ones hold the changes of a commit over their parent
+type snapshotLayer struct {
+	root   types.Hash
+	parent *snapshotLayer
+
+	// stale is set once the layer is not part of the snapshot anymore
+	stale bool
+
+	// accounts are the account entries, nil for the deleted accounts
+	accounts map[types.Hash][]byte
+
+	// destructs are the accounts whose storage was wiped before
+	// applying the storage entries
+	destructs map[types.Hash]struct{}
+
+	// storage are the slot entries of the accounts, nil for the deleted slots
+	storage map[types.Hash]map[types.Hash][]byte
+}
+
+func (l *snapshotLayer) setAccount(hash types.Hash, data []byte) {
+	l.accounts[hash] = data
+}
+
+func (l *snapshotLayer) destruct(hash types.Hash) {
+	l.destructs[hash] = struct{}{}
+	delete(l.storage, hash)
+}
+
+func (l *snapshotLayer) setSlot(hash types.Hash, slot types.Hash, data []byte) {
+	slots, ok := l.storage[hash]
+	if !ok {
+		slots = map[types.Hash][]byte{}
+		l.storage[hash] = slots
+	}
+
+	slots[slot] = data
+}
+
+// Snapshots is the flat key-value snapshot of the state. It is made of a layer
+// on disk and the diff layers of the latest commits on top of it, which handle
+// the reorgs. Once there are more diff layers than the limit, the bottom one
+// of the latest commit is flattened into disk. While the layer on disk is
+// generated, the diff layers are kept in memory and the reads fall back to the trie
+type Snapshots struct {
+	storage Storage
+	layers  int
+
+	lock sync.RWMutex
+	disk *snapshotLayer
+	tree map[types.Hash]*snapshotLayer
+
+	// generating is set while the layer on disk is generated
+	generating bool
+	genStop    chan struct{}
+	genDone    chan struct{}
+	genErr     error
+}
+
+func newSnapshots(storage Storage, layers int) *Snapshots {
+	root := types.EmptyRootHash
+	if data, ok := storage.Get(snapshotRootKey); ok {
+		root = types.BytesToHash(data)
+	}
+
+	disk := &snapshotLayer{root: root}
+
+	return &Snapshots{
+		storage: storage,
+		layers:  layers,
+		disk:    disk,
+		tree: map[types.Hash]*snapshotLayer{
+			root: disk,
+		},
+	}
+}
+
+// layer returns the layer of the state with the root, or nil
+func (s *Snapshots) layer(root types.Hash) *snapshotLayer {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.tree[root]
+}
+
+// account returns the account with the hashed address in the layer, nil if it does not exist
+func (s *Snapshots) account(l *snapshotLayer, hash types.Hash) ([]byte, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if l.stale {
+		return nil, errSnapshotStale
+	}
+
+	for ; l.parent != nil; l = l.parent {
+		if data, ok := l.accounts[hash]; ok {
+			return data, nil
+		}
+	}
+
+	if s.generating {
+		return nil, errSnapshotGenerating
+	}
+
+	data, ok := s.storage.Get(snapshotAccountKey(hash))
+	if !ok {
+		return nil, nil
+	}
+
+	return data, nil
+}
+
+// slot returns the storage slot of the account in the layer, nil if it is empty
+func (s *Snapshots) slot(l *snapshotLayer, hash types.Hash, slot types.Hash) ([]byte, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if l.stale {
+		return nil, errSnapshotStale
+	}
+
+	for ; l.parent != nil; l = l.parent {
+		if data, ok := l.storage[hash][slot]; ok {
+			return data, nil
+		}
+
+		if _, ok := l.destructs[hash]; ok {
+			return nil, nil
+		}
+	}
+
+	if s.generating {
+		return nil, errSnapshotGenerating
+	}
+
+	data, ok := s.storage.Get(snapshotStorageKey(hash, slot))
+	if !ok {
+		return nil, nil
+	}
+
+	return data, nil
+}
+
+// newDiff returns an empty diff layer on top of the state with the root,
+// or nil if the snapshot does not have it
+func (s *Snapshots) newDiff(parent types.Hash) *snapshotLayer {
+	p := s.layer(parent)
+	if p == nil {
+		return nil
+	}
+
+	return &snapshotLayer{
+		parent:    p,
+		accounts:  map[types.Hash][]byte{},
+		destructs: map[types.Hash]struct{}{},
+		storage:   map[types.Hash]map[types.Hash][]byte{},
+	}
+}
+
+// hasStorage returns whether the account in the parent of the diff may have storage
+func (s *Snapshots) hasStorage(diff *snapshotLayer, hash types.Hash) bool {
+	data, err := s.account(diff.parent, hash)
+	if err != nil {
+		// wipe it anyway, the layer is dropped when added
+		return true
+	}
+
+	if data == nil {
+		return false
+	}
+
+	var account state.Account
+	if err := account.UnmarshalRlp(data); err != nil {
+		return true
+	}
+
+	return account.Root != types.EmptyRootHash
+}
+
+// add adds the diff layer of the state with the root
+func (s *Snapshots) add(root types.Hash, diff *snapshotLayer) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if diff.parent.stale {
+		return
+	}
+
+	if _, ok := s.tree[root]; ok {
+		// the state is already known
+		return
+	}
+
+	diff.root = root
+	s.tree[root] = diff
+
+	// the disk layer is not flattened into until it is generated
+	for !s.generating && s.depth(diff) > s.layers {
+		s.flatten(s.bottom(diff))
+	}
+}
+
+// depth returns the number of diff layers from the layer to the disk one
+func (s *Snapshots) depth(l *snapshotLayer) int {
+	depth := 0
+	for ; l.parent != nil; l = l.parent {
+		depth++
+	}
+
+	return depth
+}
+
+// bottom returns the diff layer on top of the disk one in the path of the layer
+func (s *Snapshots) bottom(l *snapshotLayer) *snapshotLayer {
+	for l.parent != s.disk {
+		l = l.parent
+	}
+
+	return l
+}
+
+// flatten writes the diff layer on top of the disk layer into disk, and drops
+// the layers which do not descend from it
+func (s *Snapshots) flatten(l *snapshotLayer) {
+	batch := s.storage.Batch()
+
+	for hash := range l.destructs {
+		prefix := snapshotStorageKey(hash, types.Hash{})[:len(snapshotStoragePrefix)+types.HashLength]
+
+		s.storage.Iterate(prefix, func(k, _ []byte) bool {
+			batch.Delete(k)
+
+			return true
+		})
+	}
+
+	for hash, data := range l.accounts {
+		if data == nil {
+			batch.Delete(snapshotAccountKey(hash))
+		} else {
+			batch.Put(snapshotAccountKey(hash), data)
+		}
+	}
+
+	for hash, slots := range l.storage {
+		for slot, data := range slots {
+			if data == nil {
+				batch.Delete(snapshotStorageKey(hash, slot))
+			} else {
+				batch.Put(snapshotStorageKey(hash, slot), data)
+			}
+		}
+	}
+
+	batch.Put(snapshotRootKey, l.root.Bytes())
+	batch.Write()
+
+	// the layer becomes the disk one in place, so that
+	// the layers on top of it stay valid
+	old := s.disk
+	old.stale = true
+
+	l.parent = nil
+	l.accounts = nil
+	l.destructs = nil
+	l.storage = nil
+	s.disk = l
+
+	for root, layer := range s.tree {
+		bottom := layer
+		for bottom.parent != nil {
+			bottom = bottom.parent
+		}
+
+		if bottom != l {
+			layer.stale = true
+
+			delete(s.tree, root)
+		}
+	}
+}
+
+// flattenTo writes the diff layers down to the state with the root into disk
+func (s *Snapshots) flattenTo(root types.Hash) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.generating {
+		return errSnapshotGenerating
+	}
+
+	l, ok := s.tree[root]
+	if !ok {
+		return fmt.Errorf("%w: %s", errSnapshotNotFound, root)
+	}
+
+	for l != s.disk {
+		s.flatten(s.bottom(l))
+	}
+
+	return nil
+}
+
+// generate regenerates the layer on disk from the state trie with the root in the background,
+// dropping the current layers. The commits on top of the state are kept as diff layers until
+// it completes. If it fails, the snapshot is dropped and the state is read from the trie
+func (s *Snapshots) generate(root types.Hash, done func(*SnapshotReport, error)) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, layer := range s.tree {
+		layer.stale = true
+	}
+
+	s.disk = &snapshotLayer{root: root}
+	s.tree = map[types.Hash]*snapshotLayer{
+		root: s.disk,
+	}
+
+	s.generating = true
+	s.genStop = make(chan struct{})
+	s.genDone = make(chan struct{})
+	s.genErr = nil
+
+	go func(stop <-chan struct{}, genDone chan struct{}) {
+		defer close(genDone)
+
+		report, err := regenerateSnapshot(s.storage, root, stop)
+
+		s.lock.Lock()
+
+		if err != nil {
+			for _, layer := range s.tree {
+				layer.stale = true
+			}
+
+			s.tree = map[types.Hash]*snapshotLayer{}
+		}
+
+		s.generating = false
+		s.genErr = err
+
+		s.lock.Unlock()
+
+		if !errors.Is(err, errSnapshotAborted) {
+			done(report, err)
+		}
+	}(s.genStop, s.genDone)
+}
+
+// stopGeneration aborts the generation of the layer on disk, if any, and waits for it to stop.
+// It returns whether the generation was aborted, in which case the snapshot is dropped
+func (s *Snapshots) stopGeneration() bool {
+	s.lock.Lock()
+
+	if !s.generating {
+		s.lock.Unlock()
+
+		return false
+	}
+
+	stop, genDone := s.genStop, s.genDone
+
+	s.lock.Unlock()
+
+	close(stop)
+	<-genDone
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return errors.Is(s.genErr, errSnapshotAborted)
+}
+
+// snapshotReader is the state snapshot reading the accounts and the storage
+// from the flat snapshot, and from the trie once its layer is stale
+type snapshotReader struct {
+	state *State
+	layer *snapshotLayer
+	trie  *Trie
+}
+
+// Get implements the state.Snapshot interface
+func (r *snapshotReader) Get(k []byte) ([]byte, bool) {
+	data, err := r.state.snaps.account(r.layer, types.BytesToHash(k))
+	if err != nil {
+		return r.trie.Get(k)
+	}
+
+	return data, data != nil
+}
+
+// Commit implements the state.Snapshot interface
+func (r *snapshotReader) Commit(objs []*state.Object) (state.Snapshot, []byte) {
+	return r.trie.Commit(objs)
+}
+
+// Storage implements the state.StorageSnapshot interface
+func (r *snapshotReader) Storage(addrHash types.Hash, root types.Hash) (state.StorageReader, error) {
+	return &snapshotStorageReader{
+		state:    r.state,
+		layer:    r.layer,
+		addrHash: addrHash,
+		root:     root,
+	}, nil
+}
+
+// snapshotStorageReader reads the storage of an account from the flat snapshot,
+// and from the storage trie once the layer is stale
+type snapshotStorageReader struct {
+	state    *State
+	layer    *snapshotLayer
+	addrHash types.Hash
+	root     types.Hash
+	trie     *Trie
+}
+
+func (r *snapshotStorageReader) Get(k []byte) ([]byte, bool) {
+	data, err := r.state.snaps.slot(r.layer, r.addrHash, types.BytesToHash(k))
+	if err == nil {
+		return data, data != nil
+	}
+
+	if r.trie == nil {
+		trie, err := r.state.newTrieAt(r.root)
+		if err != nil {
+			return nil, false
+		}
+
+		r.trie = trie
+	}
+
+	return r.trie.Get(k)
+}
@@ -0,0 +1,330 @@
+package itrie
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/juanidrobo/polygon-edge/helper/hex"
+	"github.com/juanidrobo/polygon-edge/state"
+	"github.com/juanidrobo/polygon-edge/types"
+)
+
+const (
+	// snapshotBatchSize is the number of flat entries written per batch
+	snapshotBatchSize = 10000
+
+	// maxSnapshotMismatches is the number of mismatches listed by the verification
+	maxSnapshotMismatches = 100
+)
+
+// SnapshotReport is the result of the regeneration or the verification of the flat snapshot
+type SnapshotReport struct {
+	Root     types.Hash
+	Accounts uint64
+	Slots    uint64
+
+	// MismatchCount is the number of inconsistent entries found by the verification,
+	// of which the first ones are described in Mismatches
+	MismatchCount uint64
+	Mismatches    []string
+}
+
+func (r *SnapshotReport) mismatch(format string, args ...interface{}) {
+	r.MismatchCount++
+
+	if len(r.Mismatches) < maxSnapshotMismatches {
+		r.Mismatches = append(r.Mismatches, fmt.Sprintf(format, args...))
+	}
+}
+
+// snapshotBatch writes the flat entries in batches of bounded size
+type snapshotBatch struct {
+	storage Storage
+	batch   Batch
+	size    int
+}
+
+func newSnapshotBatch(storage Storage) *snapshotBatch {
+	return &snapshotBatch{storage: storage, batch: storage.Batch()}
+}
+
+func (b *snapshotBatch) put(k, v []byte) {
+	b.batch.Put(k, v)
+	b.inc()
+}
+
+func (b *snapshotBatch) delete(k []byte) {
+	b.batch.Delete(k)
+	b.inc()
+}
+
+func (b *snapshotBatch) inc() {
+	b.size++
+	if b.size == snapshotBatchSize {
+		b.write()
+	}
+}
+
+func (b *snapshotBatch) write() {
+	b.batch.Write()
+	b.batch = b.storage.Batch()
+	b.size = 0
+}
+
+// iterateSnapshot calls the handler with the flat accounts on disk or, if slots is set,
+// with the flat storage slots, until the handler returns false
+func iterateSnapshot(storage Storage, slots bool, handler func(k, v []byte) bool) {
+	prefix, length := snapshotAccountPrefix, len(snapshotAccountPrefix)+types.HashLength
+	if slots {
+		prefix, length = snapshotStoragePrefix, len(snapshotStoragePrefix)+2*types.HashLength
+	}
+
+	storage.Iterate(prefix, func(k, v []byte) bool {
+		// skip the trie nodes sharing the prefix
+		if len(k) != length {
+			return true
+		}
+
+		return handler(k, v)
+	})
+}
+
+// iterateTrie calls the handler with the entries of the trie with the root, in key order
+func iterateTrie(storage Storage, root types.Hash, handler func(k, v []byte) error) error {
+	if root == types.EmptyRootHash {
+		return nil
+	}
+
+	n, ok, err := GetNode(root.Bytes(), storage)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return fmt.Errorf("state not found at hash %s", root)
+	}
+
+	return iterateNode(storage, n, nil, handler)
+}
+
+func iterateNode(storage Storage, node Node, path []byte, handler func(k, v []byte) error) error {
+	switch n := node.(type) {
+	case nil:
+		return nil
+
+	case *ValueNode:
+		if n.hash {
+			nc, ok, err := GetNode(n.buf, storage)
+			if err != nil {
+				return err
+			}
+
+			if !ok {
+				return fmt.Errorf("trie node not found at hash %s", hex.EncodeToHex(n.buf))
+			}
+
+			return iterateNode(storage, nc, path, handler)
+		}
+
+		return handler(hexNibblesToBytes(path), n.buf)
+
+	case *ShortNode:
+		key := n.key
+		if hasTerminator(key) {
+			key = key[:len(key)-1]
+		}
+
+		return iterateNode(storage, n.child, appendNibbles(path, key...), handler)
+
+	case *FullNode:
+		if err := iterateNode(storage, n.value, path, handler); err != nil {
+			return err
+		}
+
+		for i, child := range n.children {
+			if err := iterateNode(storage, child, appendNibbles(path, byte(i)), handler); err != nil {
+				return err
+			}
+		}
+
+		return nil
+
+	default:
+		return fmt.Errorf("unknown node type %T", n)
+	}
+}
+
+func appendNibbles(path []byte, nibbles ...byte) []byte {
+	res := make([]byte, 0, len(path)+len(nibbles))
+	res = append(res, path...)
+
+	return append(res, nibbles...)
+}
+
+// RegenerateSnapshot rebuilds the flat snapshot on disk from the state trie with the root.
+// The flat snapshot must not be in use while it is regenerated
+func RegenerateSnapshot(storage Storage, root types.Hash) (*SnapshotReport, error) {
+	return regenerateSnapshot(storage, root, nil)
+}
+
+// regenerateSnapshot rebuilds the flat snapshot on disk from the state trie with the root,
+// until the stop channel is closed
+func regenerateSnapshot(storage Storage, root types.Hash, stop <-chan struct{}) (*SnapshotReport, error) {
+	// the flat entries are not valid until the regeneration completes
+	storage.Delete(snapshotRootKey)
+
+	batch := newSnapshotBatch(storage)
+
+	for _, slots := range []bool{false, true} {
+		iterateSnapshot(storage, slots, func(k, _ []byte) bool {
+			batch.delete(k)
+
+			return true
+		})
+	}
+
+	report := &SnapshotReport{Root: root}
+
+	err := iterateTrie(storage, root, func(k, v []byte) error {
+		if stopped(stop) {
+			return errSnapshotAborted
+		}
+
+		report.Accounts++
+
+		hash := types.BytesToHash(k)
+		batch.put(snapshotAccountKey(hash), v)
+
+		var account state.Account
+		if err := account.UnmarshalRlp(v); err != nil {
+			return err
+		}
+
+		return iterateTrie(storage, account.Root, func(k, v []byte) error {
+			if stopped(stop) {
+				return errSnapshotAborted
+			}
+
+			report.Slots++
+
+			batch.put(snapshotStorageKey(hash, types.BytesToHash(k)), v)
+
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	batch.put(snapshotRootKey, root.Bytes())
+	batch.write()
+
+	return report, nil
+}
+
+func stopped(stop <-chan struct{}) bool {
+	select {
+	case <-stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// VerifySnapshot checks the flat snapshot on disk against the state trie with the root
+func VerifySnapshot(storage Storage, root types.Hash) (*SnapshotReport, error) {
+	report := &SnapshotReport{Root: root}
+
+	if data, ok := storage.Get(snapshotRootKey); !ok {
+		report.mismatch("snapshot root: missing")
+	} else if diskRoot := types.BytesToHash(data); diskRoot != root {
+		report.mismatch("snapshot root: %s", diskRoot)
+	}
+
+	// every entry of the trie is in the snapshot
+	err := iterateTrie(storage, root, func(k, v []byte) error {
+		report.Accounts++
+
+		hash := types.BytesToHash(k)
+		compareSnapshotEntry(report, storage, snapshotAccountKey(hash), v, "account %s", hash)
+
+		var account state.Account
+		if err := account.UnmarshalRlp(v); err != nil {
+			return err
+		}
+
+		return iterateTrie(storage, account.Root, func(k, v []byte) error {
+			report.Slots++
+
+			slot := types.BytesToHash(k)
+			compareSnapshotEntry(report, storage, snapshotStorageKey(hash, slot), v, "slot %s of account %s", slot, hash)
+
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// every entry of the snapshot is in the trie
+	st := NewState(storage)
+
+	trie, err := st.newTrieAt(root)
+	if err != nil {
+		return nil, err
+	}
+
+	iterateSnapshot(storage, false, func(k, _ []byte) bool {
+		if _, ok := trie.Get(k[len(snapshotAccountPrefix):]); !ok {
+			report.mismatch("account %s: not in the trie", types.BytesToHash(k[len(snapshotAccountPrefix):]))
+		}
+
+		return true
+	})
+
+	var (
+		storageHash types.Hash
+		storageTrie *Trie
+	)
+
+	iterateSnapshot(storage, true, func(k, _ []byte) bool {
+		hash := types.BytesToHash(k[len(snapshotStoragePrefix) : len(snapshotStoragePrefix)+types.HashLength])
+		slot := types.BytesToHash(k[len(snapshotStoragePrefix)+types.HashLength:])
+
+		// the slots are sorted by account
+		if storageTrie == nil || storageHash != hash {
+			storageHash, storageTrie = hash, nil
+
+			var account state.Account
+			if data, ok := trie.Get(hash.Bytes()); ok && account.UnmarshalRlp(data) == nil {
+				storageTrie, _ = st.newTrieAt(account.Root)
+			}
+		}
+
+		if storageTrie == nil {
+			report.mismatch("slot %s of account %s: account not in the trie", slot, hash)
+		} else if _, ok := storageTrie.Get(slot.Bytes()); !ok {
+			report.mismatch("slot %s of account %s: not in the trie", slot, hash)
+		}
+
+		return true
+	})
+
+	return report, nil
+}
+
+func compareSnapshotEntry(
+	report *SnapshotReport,
+	storage Storage,
+	key []byte,
+	expected []byte,
+	format string,
+	args ...interface{},
+) {
+	data, ok := storage.Get(key)
+	if !ok {
+		report.mismatch(format+": missing", args...)
+	} else if !bytes.Equal(data, expected) {
+		report.mismatch(format+": %s, expected %s", append(args, hex.EncodeToHex(data), hex.EncodeToHex(expected))...)
+	}
+}
@@ -0,0 +1,368 @@
+package itrie
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/juanidrobo/polygon-edge/state"
+	"github.com/juanidrobo/polygon-edge/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestState_Snapshot(t *testing.T) {
+	state.TestState(t, func(pre state.PreStates) (state.State, state.Snapshot) {
+		st := NewState(NewMemoryStorage())
+		st.EnableSnapshot(4)
+
+		return st, st.NewSnapshot()
+	})
+}
+
+// snapshotChain commits random blocks on top of random recent states
+type snapshotChain struct {
+	t     *testing.T
+	rand  *rand.Rand
+	st    *State
+	addrs []types.Address
+	slots []types.Hash
+	roots []types.Hash
+}
+
+func newSnapshotChain(t *testing.T, seed int64, layers int) *snapshotChain {
+	t.Helper()
+
+	c := &snapshotChain{
+		t:     t,
+		rand:  rand.New(rand.NewSource(seed)), //nolint:gosec
+		st:    NewState(NewMemoryStorage()),
+		roots: []types.Hash{types.EmptyRootHash},
+	}
+
+	for i := 0; i < 8; i++ {
+		c.addrs = append(c.addrs, types.StringToAddress(string(rune('a'+i))))
+		c.slots = append(c.slots, types.BytesToHash([]byte{byte(i + 1)}))
+	}
+
+	c.st.EnableSnapshot(layers)
+
+	return c
+}
+
+func (c *snapshotChain) value() types.Hash {
+	// one in four writes clears the slot
+	if c.rand.Intn(4) == 0 {
+		return types.Hash{}
+	}
+
+	return types.BytesToHash([]byte{byte(c.rand.Intn(255) + 1)})
+}
+
+// commit applies random changes on top of the state with the root
+func (c *snapshotChain) commit(parent types.Hash) types.Hash {
+	snap, err := c.st.NewSnapshotAt(parent)
+	assert.NoError(c.t, err)
+
+	txn := state.NewTxn(c.st, snap)
+
+	for i := 0; i < 4; i++ {
+		addr := c.addrs[c.rand.Intn(len(c.addrs))]
+
+		switch c.rand.Intn(6) {
+		case 0:
+			txn.Suicide(addr)
+		case 1:
+			txn.SetFullStorage(addr, map[types.Hash]types.Hash{
+				c.slots[c.rand.Intn(len(c.slots))]: c.value(),
+			})
+		case 2:
+			txn.AddBalance(addr, big.NewInt(int64(c.rand.Intn(100)+1)))
+		default:
+			txn.SetState(addr, c.slots[c.rand.Intn(len(c.slots))], c.value())
+			txn.SetNonce(addr, txn.GetNonce(addr)+1)
+		}
+	}
+
+	_, root := txn.Commit(false)
+
+	return types.BytesToHash(root)
+}
+
+// next commits a block on top of the latest state or, at times, of an older one
+func (c *snapshotChain) next() types.Hash {
+	parent := c.roots[len(c.roots)-1]
+	if n := len(c.roots); n > 2 && c.rand.Intn(4) == 0 {
+		parent = c.roots[n-1-c.rand.Intn(3)]
+	}
+
+	root := c.commit(parent)
+	c.roots = append(c.roots, root)
+
+	return root
+}
+
+// check compares the reads from the snapshot with the ones from the trie
+func (c *snapshotChain) check(root types.Hash) {
+	snap, err := c.st.NewSnapshotAt(root)
+	assert.NoError(c.t, err)
+
+	trie, err := c.st.newTrieAt(root)
+	assert.NoError(c.t, err)
+
+	snapTxn := state.NewTxn(c.st, snap)
+	trieTxn := state.NewTxn(c.st, trie)
+
+	for _, addr := range c.addrs {
+		assert.Equal(c.t, trieTxn.Exist(addr), snapTxn.Exist(addr))
+		assert.Equal(c.t, trieTxn.GetBalance(addr), snapTxn.GetBalance(addr))
+		assert.Equal(c.t, trieTxn.GetNonce(addr), snapTxn.GetNonce(addr))
+
+		for _, slot := range c.slots {
+			assert.Equal(c.t, trieTxn.GetState(addr, slot), snapTxn.GetState(addr, slot))
+		}
+	}
+}
+
+func TestSnapshot_Reads(t *testing.T) {
+	c := newSnapshotChain(t, 1, 4)
+
+	for i := 0; i < 32; i++ {
+		c.next()
+
+		// the state on top of the latest one is always in the snapshot
+		root := c.commit(c.roots[len(c.roots)-1])
+		c.roots = append(c.roots, root)
+
+		snap, err := c.st.NewSnapshotAt(root)
+		assert.NoError(t, err)
+		assert.IsType(t, &snapshotReader{}, snap)
+
+		for _, root := range c.roots[len(c.roots)-3:] {
+			c.check(root)
+		}
+	}
+
+	// the states before the flattened layers fall back to the trie
+	root := c.roots[1]
+	assert.False(t, c.st.HasSnapshot(root))
+
+	snap, err := c.st.NewSnapshotAt(root)
+	assert.NoError(t, err)
+	assert.IsType(t, &Trie{}, snap)
+}
+
+func TestSnapshot_StaleLayer(t *testing.T) {
+	c := newSnapshotChain(t, 2, 2)
+
+	first := c.commit(types.EmptyRootHash)
+	sibling := c.commit(types.EmptyRootHash)
+
+	snap, err := c.st.NewSnapshotAt(sibling)
+	assert.NoError(t, err)
+
+	reader, ok := snap.(*snapshotReader)
+	assert.True(t, ok)
+
+	// flattening the other branch drops the sibling
+	parent := first
+	for i := 0; i < 3; i++ {
+		parent = c.commit(parent)
+	}
+
+	assert.False(t, c.st.HasSnapshot(sibling))
+	assert.True(t, reader.layer.stale)
+
+	trie, err := c.st.newTrieAt(sibling)
+	assert.NoError(t, err)
+
+	for _, addr := range c.addrs {
+		key := hashit(addr.Bytes())
+
+		expected, expectedOk := trie.Get(key)
+		data, ok := reader.Get(key)
+
+		assert.Equal(t, expectedOk, ok)
+		assert.Equal(t, expected, data)
+	}
+}
+
+func TestSnapshot_FlattenAndVerify(t *testing.T) {
+	for seed := int64(0); seed < 5; seed++ {
+		c := newSnapshotChain(t, seed, 3)
+
+		for i := 0; i < 40; i++ {
+			c.next()
+		}
+
+		head := c.roots[len(c.roots)-1]
+		assert.NoError(t, c.st.FlattenSnapshot(head))
+		c.check(head)
+
+		report, err := VerifySnapshot(c.st.storage, head)
+		assert.NoError(t, err)
+		assert.Empty(t, report.Mismatches)
+		assert.NotZero(t, report.Accounts)
+		assert.NotZero(t, report.Slots)
+	}
+}
+
+func TestSnapshot_FlattenUnknownRoot(t *testing.T) {
+	st := NewState(NewMemoryStorage())
+	st.EnableSnapshot(4)
+
+	assert.ErrorIs(t, st.FlattenSnapshot(types.StringToHash("1")), errSnapshotNotFound)
+}
+
+func TestSnapshot_RegenerateAndVerify(t *testing.T) {
+	c := newSnapshotChain(t, 3, 128)
+
+	// the snapshot is not maintained by the commits
+	c.st.snaps = nil
+
+	for i := 0; i < 20; i++ {
+		c.next()
+	}
+
+	head := c.roots[len(c.roots)-1]
+	storage := c.st.storage
+
+	report, err := VerifySnapshot(storage, head)
+	assert.NoError(t, err)
+	assert.NotZero(t, report.MismatchCount)
+
+	generated, err := RegenerateSnapshot(storage, head)
+	assert.NoError(t, err)
+
+	report, err = VerifySnapshot(storage, head)
+	assert.NoError(t, err)
+	assert.Empty(t, report.Mismatches)
+	assert.Equal(t, generated.Accounts, report.Accounts)
+	assert.Equal(t, generated.Slots, report.Slots)
+
+	// the regenerated snapshot is used once enabled
+	c.st.EnableSnapshot(4)
+	assert.True(t, c.st.HasSnapshot(head))
+	c.check(head)
+
+	// regenerating at an older state removes the newer entries
+	_, err = RegenerateSnapshot(storage, c.roots[1])
+	assert.NoError(t, err)
+
+	report, err = VerifySnapshot(storage, c.roots[1])
+	assert.NoError(t, err)
+	assert.Empty(t, report.Mismatches)
+
+	t.Run("mismatches", func(t *testing.T) {
+		var account, slot []byte
+
+		iterateSnapshot(storage, false, func(k, _ []byte) bool {
+			account = k
+
+			return false
+		})
+		iterateSnapshot(storage, true, func(k, _ []byte) bool {
+			slot = k
+
+			return false
+		})
+
+		storage.Put(account, []byte{0x1})
+		storage.Delete(slot)
+		storage.Put(snapshotStorageKey(types.StringToHash("1"), types.StringToHash("2")), []byte{0x1})
+
+		report, err := VerifySnapshot(storage, c.roots[1])
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(3), report.MismatchCount)
+		assert.Len(t, report.Mismatches, 3)
+	})
+}
+
+// pausedStorage holds the deletions, which start the generation, until it is resumed
+type pausedStorage struct {
+	Storage
+	resume chan struct{}
+}
+
+func (s *pausedStorage) Delete(k []byte) {
+	<-s.resume
+	s.Storage.Delete(k)
+}
+
+func TestSnapshot_Generate(t *testing.T) {
+	c := newSnapshotChain(t, 4, 4)
+
+	// the snapshot is not maintained by the commits, as after an unclean shutdown
+	snaps := c.st.snaps
+	c.st.snaps = nil
+
+	for i := 0; i < 20; i++ {
+		c.next()
+	}
+
+	head := c.roots[len(c.roots)-1]
+	storage := &pausedStorage{Storage: c.st.storage, resume: make(chan struct{})}
+
+	c.st.snaps = newSnapshots(storage, snaps.layers)
+	assert.False(t, c.st.HasSnapshot(head))
+
+	done := make(chan *SnapshotReport)
+
+	c.st.GenerateSnapshot(head, func(report *SnapshotReport, err error) {
+		assert.NoError(t, err)
+
+		done <- report
+	})
+
+	// the commits on top of the head are kept while it is generated, reading from the trie
+	parent := head
+	for i := 0; i < 6; i++ {
+		parent = c.commit(parent)
+		c.roots = append(c.roots, parent)
+
+		assert.True(t, c.st.HasSnapshot(parent))
+		c.check(parent)
+	}
+
+	assert.ErrorIs(t, c.st.FlattenSnapshot(parent), errSnapshotGenerating)
+
+	close(storage.resume)
+
+	report := <-done
+	assert.Equal(t, head, report.Root)
+
+	// the layers are flattened once generated
+	parent = c.commit(parent)
+	assert.True(t, c.st.HasSnapshot(parent))
+	c.check(parent)
+
+	assert.NoError(t, c.st.FlattenSnapshot(parent))
+
+	verified, err := VerifySnapshot(storage, parent)
+	assert.NoError(t, err)
+	assert.Empty(t, verified.Mismatches)
+
+	t.Run("stopped", func(t *testing.T) {
+		storage.resume = make(chan struct{})
+
+		c.st.GenerateSnapshot(parent, func(*SnapshotReport, error) {
+			t.Error("the stopped generation is not reported")
+		})
+
+		go func() {
+			// the generation stops once it is resumed
+			time.Sleep(100 * time.Millisecond)
+			close(storage.resume)
+		}()
+
+		assert.True(t, c.st.StopSnapshotGeneration())
+		assert.False(t, c.st.StopSnapshotGeneration())
+
+		// the snapshot is dropped, and regenerated on restart
+		assert.False(t, c.st.HasSnapshot(parent))
+		c.check(parent)
+
+		_, ok := storage.Get(snapshotRootKey)
+		assert.False(t, ok)
+	})
+}
@@ -13,6 +13,9 @@ import (
 type State struct {
 	storage Storage
 	cache   *lru.Cache
+
+	// snaps is the flat snapshot of the state, if enabled
+	snaps *Snapshots
 }
 
 func NewState(storage Storage) *State {
@@ -26,7 +29,49 @@ func NewState(storage Storage) *State {
 	return s
 }
 
+// EnableSnapshot maintains the flat snapshot of the state on the commits,
+// keeping the states of the given number of latest commits as diff layers
+func (s *State) EnableSnapshot(layers int) {
+	s.snaps = newSnapshots(s.storage, layers)
+}
+
+// HasSnapshot returns whether the flat snapshot has the state with the root
+func (s *State) HasSnapshot(root types.Hash) bool {
+	return s.snaps != nil && s.snaps.layer(root) != nil
+}
+
+// GenerateSnapshot regenerates the flat snapshot from the state trie with the root in the background,
+// keeping the commits on top of the state as diff layers until it completes. The reads fall back to
+// the trie meanwhile. The done callback is called once it completes or fails, but not if it is stopped
+func (s *State) GenerateSnapshot(root types.Hash, done func(*SnapshotReport, error)) {
+	if s.snaps == nil {
+		return
+	}
+
+	s.snaps.generate(root, done)
+}
+
+// StopSnapshotGeneration aborts the generation of the flat snapshot, if any, and waits for it
+// to stop. It returns whether it was aborted, in which case the snapshot is dropped
+func (s *State) StopSnapshotGeneration() bool {
+	return s.snaps != nil && s.snaps.stopGeneration()
+}
+
+// FlattenSnapshot writes the diff layers of the flat snapshot down to
+// the state with the root into disk, dropping the other ones
+func (s *State) FlattenSnapshot(root types.Hash) error {
+	if s.snaps == nil {
+		return nil
+	}
+
+	return s.snaps.flattenTo(root)
+}
+
 func (s *State) NewSnapshot() state.Snapshot {
+	return s.newTrie()
+}
+
+func (s *State) newTrie() *Trie {
 	t := NewTrie()
 	t.state = s
 	t.storage = s.storage
@@ -43,9 +88,37 @@ func (s *State) GetCode(hash types.Hash) ([]byte, bool) {
 }
 
 func (s *State) NewSnapshotAt(root types.Hash) (state.Snapshot, error) {
+	t, err := s.newTrieAt(root)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.snapshotAt(root, t), nil
+}
+
+// snapshotAt returns the reader of the flat snapshot of the state with the root
+// falling back to its trie, or the trie if the flat snapshot does not have it
+func (s *State) snapshotAt(root types.Hash, t *Trie) state.Snapshot {
+	if s.snaps == nil || root == types.EmptyRootHash {
+		return t
+	}
+
+	layer := s.snaps.layer(root)
+	if layer == nil {
+		return t
+	}
+
+	return &snapshotReader{
+		state: s,
+		layer: layer,
+		trie:  t,
+	}
+}
+
+func (s *State) newTrieAt(root types.Hash) (*Trie, error) {
 	if root == types.EmptyRootHash {
 		// empty state
-		return s.NewSnapshot(), nil
+		return s.newTrie(), nil
 	}
 
 	tt, ok := s.cache.Get(root)
@@ -57,12 +130,7 @@ func (s *State) NewSnapshotAt(root types.Hash) (state.Snapshot, error) {
 
 		t.state = s
 
-		trie, ok := tt.(*Trie)
-		if !ok {
-			return nil, errors.New("invalid type assertion")
-		}
-
-		return trie, nil
+		return t, nil
 	}
 
 	n, ok, err := GetNode(root.Bytes(), s.storage)
@@ -77,6 +145,7 @@ func (s *State) NewSnapshotAt(root types.Hash) (state.Snapshot, error) {
 
 	t := &Trie{
 		root:    n,
+		hash:    root,
 		state:   s,
 		storage: s.storage,
 	}
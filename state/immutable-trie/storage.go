@@ -2,11 +2,14 @@ package itrie
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/juanidrobo/polygon-edge/helper/hex"
 	"github.com/juanidrobo/polygon-edge/types"
 	"github.com/hashicorp/go-hclog"
 	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
 	"github.com/umbracle/fastrlp"
 )
 
@@ -19,6 +22,7 @@ var (
 
 type Batch interface {
 	Put(k, v []byte)
+	Delete(k []byte)
 	Write()
 }
 
@@ -26,6 +30,10 @@ type Batch interface {
 type Storage interface {
 	Put(k, v []byte)
 	Get(k []byte) ([]byte, bool)
+	Delete(k []byte)
+	// Iterate calls the handler with the entries having the prefix, in key order,
+	// until the handler returns false
+	Iterate(prefix []byte, handler func(k, v []byte) bool)
 	Batch() Batch
 	SetCode(hash types.Hash, code []byte)
 	GetCode(hash types.Hash) ([]byte, bool)
@@ -48,6 +56,10 @@ func (b *KVBatch) Put(k, v []byte) {
 	b.batch.Put(k, v)
 }
 
+func (b *KVBatch) Delete(k []byte) {
+	b.batch.Delete(k)
+}
+
 func (b *KVBatch) Write() {
 	_ = b.db.Write(b.batch, nil)
 }
@@ -81,6 +93,21 @@ func (kv *KVStorage) Get(k []byte) ([]byte, bool) {
 	return data, true
 }
 
+func (kv *KVStorage) Delete(k []byte) {
+	_ = kv.db.Delete(k, nil)
+}
+
+func (kv *KVStorage) Iterate(prefix []byte, handler func(k, v []byte) bool) {
+	iter := kv.db.NewIterator(util.BytesPrefix(prefix), nil)
+	defer iter.Release()
+
+	for iter.Next() {
+		if !handler(iter.Key(), iter.Value()) {
+			return
+		}
+	}
+}
+
 func (kv *KVStorage) Close() error {
 	return kv.db.Close()
 }
@@ -123,6 +150,37 @@ func (m *memStorage) Get(p []byte) ([]byte, bool) {
 	return v, true
 }
 
+func (m *memStorage) Delete(p []byte) {
+	delete(m.db, hex.EncodeToHex(p))
+}
+
+func (m *memStorage) Iterate(prefix []byte, handler func(k, v []byte) bool) {
+	// the keys are hex encoded, which keeps the order of the raw keys
+	hexPrefix := hex.EncodeToHex(prefix)
+
+	keys := []string{}
+
+	for k := range m.db {
+		if strings.HasPrefix(k, hexPrefix) {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v, ok := m.db[k]
+		if !ok {
+			// deleted by the handler
+			continue
+		}
+
+		if !handler(hex.MustDecodeHex(k), v) {
+			return
+		}
+	}
+}
+
 func (m *memStorage) SetCode(hash types.Hash, code []byte) {
 	m.code[hash.String()] = code
 }
@@ -147,6 +205,10 @@ func (m *memBatch) Put(p, v []byte) {
 	(*m.db)[hex.EncodeToHex(p)] = buf
 }
 
+func (m *memBatch) Delete(p []byte) {
+	delete(*m.db, hex.EncodeToHex(p))
+}
+
 func (m *memBatch) Write() {
 }
 
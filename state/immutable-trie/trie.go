@@ -94,6 +94,9 @@ type Trie struct {
 	root    Node
 	epoch   uint32
 	storage Storage
+
+	// hash is the root hash of the trie when it was loaded or committed
+	hash types.Hash
 }
 
 func NewTrie() *Trie {
@@ -131,9 +134,26 @@ func (t *Trie) Commit(objs []*state.Object) (state.Snapshot, []byte) {
 	ar1 := stateArenaPool.Get()
 	defer stateArenaPool.Put(ar1)
 
+	// diff collects the changes for the flat snapshot, if it has the state of the trie
+	var diff *snapshotLayer
+	if t.state.snaps != nil {
+		diff = t.state.snaps.newDiff(t.stateRoot())
+	}
+
 	for _, obj := range objs {
+		key := hashit(obj.Address.Bytes())
+		hash := types.BytesToHash(key)
+
 		if obj.Deleted {
-			tt.Delete(hashit(obj.Address.Bytes()))
+			tt.Delete(key)
+
+			if diff != nil {
+				if t.state.snaps.hasStorage(diff, hash) {
+					diff.destruct(hash)
+				}
+
+				diff.setAccount(hash, nil)
+			}
 		} else {
 			account := state.Account{
 				Balance:  obj.Balance,
@@ -142,17 +162,17 @@ func (t *Trie) Commit(objs []*state.Object) (state.Snapshot, []byte) {
 				Root:     obj.Root, // old root
 			}
 
+			// the storage is wiped if the account starts from an empty one
+			if diff != nil && obj.Root == types.EmptyRootHash && t.state.snaps.hasStorage(diff, hash) {
+				diff.destruct(hash)
+			}
+
 			if len(obj.Storage) != 0 {
-				localSnapshot, err := t.state.NewSnapshotAt(obj.Root)
+				trie, err := t.state.newTrieAt(obj.Root)
 				if err != nil {
 					panic(err)
 				}
 
-				trie, ok := localSnapshot.(*Trie)
-				if !ok {
-					panic("invalid type assertion")
-				}
-
 				localTxn := trie.Txn()
 				localTxn.batch = batch
 
@@ -160,9 +180,18 @@ func (t *Trie) Commit(objs []*state.Object) (state.Snapshot, []byte) {
 					k := hashit(entry.Key)
 					if entry.Deleted {
 						localTxn.Delete(k)
+
+						if diff != nil {
+							diff.setSlot(hash, types.BytesToHash(k), nil)
+						}
 					} else {
 						vv := ar1.NewBytes(bytes.TrimLeft(entry.Val, "\x00"))
-						localTxn.Insert(k, vv.MarshalTo(nil))
+						data := vv.MarshalTo(nil)
+						localTxn.Insert(k, data)
+
+						if diff != nil {
+							diff.setSlot(hash, types.BytesToHash(k), data)
+						}
 					}
 				}
 
@@ -182,8 +211,12 @@ func (t *Trie) Commit(objs []*state.Object) (state.Snapshot, []byte) {
 			vv := account.MarshalWith(arena)
 			data := vv.MarshalTo(nil)
 
-			tt.Insert(hashit(obj.Address.Bytes()), data)
+			tt.Insert(key, data)
 			arena.Reset()
+
+			if diff != nil {
+				diff.setAccount(hash, data)
+			}
 		}
 	}
 
@@ -192,13 +225,27 @@ func (t *Trie) Commit(objs []*state.Object) (state.Snapshot, []byte) {
 	nTrie := tt.Commit()
 	nTrie.state = t.state
 	nTrie.storage = t.storage
+	nTrie.hash = types.BytesToHash(root)
 
 	// Write all the entries to db
 	batch.Write()
 
 	t.state.AddState(types.BytesToHash(root), nTrie)
 
-	return nTrie, root
+	if diff != nil {
+		t.state.snaps.add(nTrie.hash, diff)
+	}
+
+	return t.state.snapshotAt(nTrie.hash, nTrie), root
+}
+
+// stateRoot returns the root hash of the trie, if it is known
+func (t *Trie) stateRoot() types.Hash {
+	if t.root == nil {
+		return types.EmptyRootHash
+	}
+
+	return t.hash
 }
 
 // Hash returns the root hash of the trie. It does not write to the
@@ -0,0 +1,757 @@
+package state
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/juanidrobo/polygon-edge/crypto"
+	"github.com/juanidrobo/polygon-edge/state/runtime"
+	"github.com/juanidrobo/polygon-edge/types"
+)
+
+// The parallel execution of the block transactions is optimistic (Block-STM style).
+// The transactions run concurrently, each on its own Txn, reading the state written by the
+// previous transactions of the block from a multi-version memory. The read and write sets of
+// each transaction are recorded. Once a round of executions is over, the transactions are
+// validated in order, a transaction being valid if all its reads still return the same values.
+// Only the invalid transactions are executed again, until the block converges. The write sets
+// are then merged in order on the transition of the block, so that the state root and the
+// receipts are the same as the ones of the sequential execution.
+//
+// The coinbase fees would make every transaction conflict with the others, so they are deferred:
+// they are paid once the write sets are merged, and the reads of the coinbase add the fees of
+// the previous transactions. The transactions writing the coinbase pay their own fee instead.
+
+const (
+	// maxParallelRounds is the maximum number of rounds executing the invalid transactions in parallel,
+	// the remaining ones are executed in order
+	maxParallelRounds = 8
+)
+
+var (
+	errParallelNotByzantium = errors.New("the receipts before byzantium require the sequential execution")
+	errParallelPostHook     = errors.New("the post hook requires the sequential execution")
+	errParallelCoinbase     = errors.New("the empty coinbase requires the sequential execution")
+)
+
+// slotKey is the location of a storage slot
+type slotKey struct {
+	addr types.Address
+	key  types.Hash
+}
+
+// accountValue is the account read by a transaction
+type accountValue struct {
+	exists   bool
+	nonce    uint64
+	balance  *big.Int
+	codeHash types.Hash
+
+	// reset is set if the storage has been reset by a previous transaction of the block
+	reset bool
+}
+
+func (a *accountValue) equal(b *accountValue) bool {
+	if a.exists != b.exists {
+		return false
+	}
+
+	if !a.exists {
+		return true
+	}
+
+	return a.nonce == b.nonce &&
+		a.balance.Cmp(b.balance) == 0 &&
+		a.codeHash == b.codeHash &&
+		a.reset == b.reset
+}
+
+// accountWrite is the account written by a transaction
+type accountWrite struct {
+	deleted bool
+
+	// reset is set if the storage of the account starts empty (i.e. the account has been created)
+	reset bool
+
+	nonce     uint64
+	balance   *big.Int
+	codeHash  []byte
+	dirtyCode bool
+	code      []byte
+}
+
+// sameAccount returns true if the write doesn't change the account fields read
+func (a *accountWrite) sameAccount(read *accountValue) bool {
+	return read.exists &&
+		!a.deleted &&
+		!a.reset &&
+		a.nonce == read.nonce &&
+		a.balance.Cmp(read.balance) == 0 &&
+		bytes.Equal(a.codeHash, read.codeHash.Bytes())
+}
+
+// txReads is the read set of a transaction
+type txReads struct {
+	accounts map[types.Address]*accountValue
+	slots    map[slotKey]types.Hash
+}
+
+// txWrites is the write set of a transaction
+type txWrites struct {
+	accounts map[types.Address]*accountWrite
+	slots    map[slotKey]types.Hash
+}
+
+// parallelExecution is the result of an execution of a transaction
+type parallelExecution struct {
+	result *runtime.ExecutionResult
+	err    error
+	logs   []*types.Log
+
+	// payFee is set if the transaction paid the coinbase fee itself
+	payFee bool
+	fee    *big.Int
+
+	reads  *txReads
+	writes *txWrites
+}
+
+// parallelTx is a transaction of the block executed in parallel
+type parallelTx struct {
+	tx *types.Transaction
+
+	// skip is set if the transaction exceeds the block gas limit, it gets a failed receipt
+	skip bool
+
+	// payFee is set if the next execution pays the coinbase fee, as the transaction writes the coinbase
+	payFee bool
+
+	exec *parallelExecution
+}
+
+// parallelBlock is the multi-version memory of the block transactions executed in parallel
+type parallelBlock struct {
+	t   *Transition
+	txs []*parallelTx
+
+	// lock guards the reads of the base state, the Txn and the tries are not safe for concurrent use
+	lock sync.Mutex
+
+	// base holds the accounts read from the transition of the block, nil if missing
+	base map[types.Address]*StateObject
+
+	// emptyTrie is the storage trie of the accounts created in the block
+	emptyTrie accountTrie
+
+	// the indexes of the transactions writing each location, in ascending order
+	accountWriters map[types.Address][]int
+	resetWriters   map[types.Address][]int
+	slotWriters    map[slotKey][]int
+
+	// codes holds the code deployed by the transactions
+	codes map[types.Hash][]byte
+}
+
+// processBlockParallel executes the block transactions optimistically in parallel.
+// Any error falls back to the sequential execution
+func (e *Executor) processBlockParallel(
+	parentRoot types.Hash,
+	block *types.Block,
+	blockCreator types.Address,
+) (*Transition, error) {
+	t, err := e.BeginTxn(parentRoot, block.Header, blockCreator)
+	if err != nil {
+		return nil, err
+	}
+
+	t.block = block
+
+	if !t.config.Byzantium {
+		return nil, errParallelNotByzantium
+	}
+
+	if e.PostHook != nil {
+		return nil, errParallelPostHook
+	}
+
+	// an existing empty coinbase is deleted once it is paid, which the deferred fees can't account for
+	if obj, ok := t.state.getStateObject(t.ctx.Coinbase); ok && obj.Empty() {
+		return nil, errParallelCoinbase
+	}
+
+	p := newParallelBlock(t, block.Transactions)
+	p.execute(e.parallelWorkers)
+
+	if err := p.merge(); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func newParallelBlock(t *Transition, txs []*types.Transaction) *parallelBlock {
+	p := &parallelBlock{
+		t:              t,
+		txs:            make([]*parallelTx, len(txs)),
+		base:           map[types.Address]*StateObject{},
+		emptyTrie:      t.auxState.NewSnapshot(),
+		accountWriters: map[types.Address][]int{},
+		resetWriters:   map[types.Address][]int{},
+		slotWriters:    map[slotKey][]int{},
+		codes:          map[types.Hash][]byte{},
+	}
+
+	for i, tx := range txs {
+		p.txs[i] = &parallelTx{
+			tx:   tx,
+			skip: tx.ExceedsBlockGasLimit(uint64(t.ctx.GasLimit)),
+		}
+	}
+
+	return p
+}
+
+// execute runs the transactions until all of them are valid
+func (p *parallelBlock) execute(workers int) {
+	pending := []int{}
+
+	for i, ptx := range p.txs {
+		if !ptx.skip {
+			pending = append(pending, i)
+		}
+	}
+
+	for round := 0; len(pending) > 0; round++ {
+		p.runParallel(pending, workers)
+
+		invalid := p.validate(pending[0])
+
+		// once the re-executions stop paying off (i.e. the transactions depend on each other),
+		// the remaining transactions are executed in order
+		if round+1 == maxParallelRounds || len(invalid) > len(pending)/2 {
+			if len(invalid) > 0 {
+				p.runInOrder(invalid[0])
+			}
+
+			return
+		}
+
+		pending = invalid
+	}
+}
+
+// runParallel executes the transactions concurrently, on the state of the previous round
+func (p *parallelBlock) runParallel(indexes []int, workers int) {
+	execs := make([]*parallelExecution, len(indexes))
+	next := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for n := range next {
+				execs[n] = p.run(indexes[n])
+			}
+		}()
+	}
+
+	for n := range indexes {
+		next <- n
+	}
+
+	close(next)
+	wg.Wait()
+
+	for n, i := range indexes {
+		p.install(i, execs[n])
+	}
+}
+
+// runInOrder validates the transactions in order from the given one,
+// executing again each invalid transaction on top of the previous ones
+func (p *parallelBlock) runInOrder(from int) {
+	for i := from; i < len(p.txs); i++ {
+		if p.txs[i].skip || p.valid(i) {
+			continue
+		}
+
+		p.install(i, p.run(i))
+
+		if !p.valid(i) {
+			// the transaction started writing the coinbase, it runs again paying its fee
+			p.install(i, p.run(i))
+		}
+	}
+}
+
+// validate returns the invalid transactions in order, from the given one
+func (p *parallelBlock) validate(from int) []int {
+	invalid := []int{}
+
+	for i := from; i < len(p.txs); i++ {
+		if !p.txs[i].skip && !p.valid(i) {
+			invalid = append(invalid, i)
+		}
+	}
+
+	return invalid
+}
+
+// valid returns true if the last execution of the transaction read the current state
+func (p *parallelBlock) valid(i int) bool {
+	ptx := p.txs[i]
+
+	if ptx.payFee != ptx.exec.payFee {
+		return false
+	}
+
+	for addr, read := range ptx.exec.reads.accounts {
+		if !read.equal(p.account(i, addr)) {
+			return false
+		}
+	}
+
+	for k, read := range ptx.exec.reads.slots {
+		if read != p.slot(i, k.addr, k.key) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// run executes the transaction on its own Txn, reading the state left by the previous transactions
+func (p *parallelBlock) run(i int) *parallelExecution {
+	ptx := p.txs[i]
+
+	exec := &parallelExecution{
+		payFee: ptx.payFee,
+		reads: &txReads{
+			accounts: map[types.Address]*accountValue{},
+			slots:    map[slotKey]types.Hash{},
+		},
+		writes: &txWrites{
+			accounts: map[types.Address]*accountWrite{},
+			slots:    map[slotKey]types.Hash{},
+		},
+	}
+
+	if ptx.tx.From == emptyFrom {
+		signer := crypto.NewSigner(p.t.config, uint64(p.t.r.config.ChainID))
+
+		from, err := signer.Sender(ptx.tx)
+		if err != nil {
+			exec.err = NewTransitionApplicationError(err, false)
+
+			return exec
+		}
+
+		ptx.tx.From = from
+	}
+
+	txn := newTxn(p.t.auxState, nil)
+	txn.reader = &parallelReader{
+		p:     p,
+		i:     i,
+		reads: exec.reads,
+	}
+
+	t := &Transition{
+		logger:           p.t.logger,
+		auxState:         p.t.auxState,
+		block:            p.t.block,
+		r:                p.t.r,
+		config:           p.t.config,
+		state:            txn,
+		getHash:          p.t.getHash,
+		ctx:              p.t.ctx,
+		gasPool:          uint64(p.t.ctx.GasLimit),
+		deferCoinbaseFee: !exec.payFee,
+	}
+
+	msg := ptx.tx.Copy()
+
+	exec.result, exec.err = t.Apply(msg)
+	if exec.err != nil {
+		return exec
+	}
+
+	exec.logs = txn.Logs()
+	exec.fee = new(big.Int).Mul(new(big.Int).SetUint64(exec.result.GasUsed), msg.GasPrice)
+
+	txn.CleanDeleteObjects(true)
+	collectWrites(txn, exec)
+
+	return exec
+}
+
+// install replaces the last execution of the transaction
+func (p *parallelBlock) install(i int, exec *parallelExecution) {
+	ptx := p.txs[i]
+
+	if ptx.exec != nil {
+		for addr, w := range ptx.exec.writes.accounts {
+			p.accountWriters[addr] = removeWriter(p.accountWriters[addr], i)
+
+			if w.deleted || w.reset {
+				p.resetWriters[addr] = removeWriter(p.resetWriters[addr], i)
+			}
+		}
+
+		for k := range ptx.exec.writes.slots {
+			p.slotWriters[k] = removeWriter(p.slotWriters[k], i)
+		}
+	}
+
+	ptx.exec = exec
+
+	for addr, w := range exec.writes.accounts {
+		p.accountWriters[addr] = insertWriter(p.accountWriters[addr], i)
+
+		if w.deleted || w.reset {
+			p.resetWriters[addr] = insertWriter(p.resetWriters[addr], i)
+		}
+
+		if w.dirtyCode {
+			p.codes[types.BytesToHash(w.codeHash)] = w.code
+		}
+	}
+
+	for k := range exec.writes.slots {
+		p.slotWriters[k] = insertWriter(p.slotWriters[k], i)
+	}
+
+	// the fee of a transaction writing the coinbase can't be deferred
+	if _, ok := exec.writes.accounts[p.t.ctx.Coinbase]; ok {
+		ptx.payFee = true
+	}
+}
+
+func insertWriter(writers []int, i int) []int {
+	n := sort.SearchInts(writers, i)
+	if n < len(writers) && writers[n] == i {
+		return writers
+	}
+
+	writers = append(writers, 0)
+	copy(writers[n+1:], writers[n:])
+	writers[n] = i
+
+	return writers
+}
+
+func removeWriter(writers []int, i int) []int {
+	n := sort.SearchInts(writers, i)
+	if n == len(writers) || writers[n] != i {
+		return writers
+	}
+
+	return append(writers[:n], writers[n+1:]...)
+}
+
+// lastWriter returns the last of the writers before the transaction, or -1
+func lastWriter(writers []int, i int) int {
+	n := sort.SearchInts(writers, i)
+	if n == 0 {
+		return -1
+	}
+
+	return writers[n-1]
+}
+
+// baseObject returns the account from the transition of the block
+func (p *parallelBlock) baseObject(addr types.Address) (*StateObject, bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	obj, ok := p.base[addr]
+	if !ok {
+		if obj, ok = p.t.state.getStateObject(addr); ok {
+			obj.Account.Trie = &lockedTrie{lock: &p.lock, trie: obj.Account.Trie}
+		}
+
+		p.base[addr] = obj
+	}
+
+	return obj, obj != nil
+}
+
+// account returns the account seen by the transaction
+func (p *parallelBlock) account(i int, addr types.Address) *accountValue {
+	v := &accountValue{}
+
+	j := lastWriter(p.accountWriters[addr], i)
+	if j >= 0 {
+		if w := p.txs[j].exec.writes.accounts[addr]; !w.deleted {
+			v.exists = true
+			v.nonce = w.nonce
+			v.balance = w.balance
+			v.codeHash = types.BytesToHash(w.codeHash)
+		}
+	} else if obj, ok := p.baseObject(addr); ok {
+		v.exists = true
+		v.nonce = obj.Account.Nonce
+		v.balance = obj.Account.Balance
+		v.codeHash = types.BytesToHash(obj.Account.CodeHash)
+	}
+
+	v.reset = lastWriter(p.resetWriters[addr], i) >= 0
+
+	if addr == p.t.ctx.Coinbase {
+		p.addFees(v, i, j)
+	}
+
+	return v
+}
+
+// addFees adds to the coinbase the deferred fees of the transactions since its last writer
+func (p *parallelBlock) addFees(v *accountValue, i, writer int) {
+	from := 0
+
+	if writer >= 0 {
+		from = writer
+
+		if p.txs[writer].exec.payFee {
+			from = writer + 1
+		}
+	}
+
+	fees := new(big.Int)
+
+	for k := from; k < i; k++ {
+		if exec := p.txs[k].exec; exec != nil && exec.err == nil && !exec.payFee {
+			fees.Add(fees, exec.fee)
+		}
+	}
+
+	if fees.Sign() == 0 {
+		return
+	}
+
+	if !v.exists {
+		v.exists = true
+		v.balance = new(big.Int)
+		v.codeHash = types.BytesToHash(emptyCodeHash)
+	}
+
+	v.balance = new(big.Int).Add(v.balance, fees)
+}
+
+// slot returns the storage slot seen by the transaction
+func (p *parallelBlock) slot(i int, addr types.Address, key types.Hash) types.Hash {
+	reset := lastWriter(p.resetWriters[addr], i)
+
+	k := slotKey{addr: addr, key: key}
+	if j := lastWriter(p.slotWriters[k], i); j >= 0 && j >= reset {
+		return p.txs[j].exec.writes.slots[k]
+	}
+
+	if reset >= 0 {
+		return types.Hash{}
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.t.state.GetState(addr, key)
+}
+
+// stateObject builds the state object of the account seen by the transaction
+func (p *parallelBlock) stateObject(addr types.Address, v *accountValue) *StateObject {
+	obj := &StateObject{
+		Account: &Account{
+			Nonce:    v.nonce,
+			Balance:  new(big.Int).Set(v.balance),
+			CodeHash: v.codeHash.Bytes(),
+			Root:     emptyStateHash,
+			Trie:     p.emptyTrie,
+		},
+		readerStorage: true,
+	}
+
+	// the committed state is the one at the beginning of the block, unless the storage has been reset
+	if base, ok := p.baseObject(addr); ok && !v.reset {
+		obj.Account.Root = base.Account.Root
+		obj.Account.Trie = base.Account.Trie
+	}
+
+	if code, ok := p.codes[v.codeHash]; ok {
+		obj.Code = code
+		obj.DirtyCode = true
+	}
+
+	return obj
+}
+
+// merge writes the transactions in order on the transition of the block, along with their receipts
+func (p *parallelBlock) merge() error {
+	t := p.t
+
+	for _, ptx := range p.txs {
+		if ptx.skip {
+			if err := t.WriteFailedReceipt(ptx.tx); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		exec := ptx.exec
+		if exec.err != nil {
+			return exec.err
+		}
+
+		if err := t.subGasPool(ptx.tx.Gas); err != nil {
+			return err
+		}
+
+		t.state.applyWrites(exec.writes)
+
+		if !exec.payFee {
+			t.state.AddBalance(t.ctx.Coinbase, exec.fee)
+		}
+
+		t.addGasPool(exec.result.GasLeft)
+		t.writeReceipt(ptx.tx, exec.result, exec.logs)
+	}
+
+	return nil
+}
+
+// collectWrites collects the write set of the executed transaction
+func collectWrites(txn *Txn, exec *parallelExecution) {
+	txn.txn.Root().Walk(func(k []byte, v interface{}) bool {
+		obj, ok := v.(*StateObject)
+		if !ok {
+			return false
+		}
+
+		addr := types.BytesToAddress(k)
+
+		if obj.Deleted {
+			exec.writes.accounts[addr] = &accountWrite{deleted: true}
+
+			return false
+		}
+
+		w := &accountWrite{
+			reset:     !obj.readerStorage,
+			nonce:     obj.Account.Nonce,
+			balance:   new(big.Int).Set(obj.Account.Balance),
+			codeHash:  obj.Account.CodeHash,
+			dirtyCode: obj.DirtyCode,
+			code:      obj.Code,
+		}
+
+		// the accounts only touched are not written
+		if read, ok := exec.reads.accounts[addr]; !ok || !w.sameAccount(read) {
+			exec.writes.accounts[addr] = w
+		}
+
+		if obj.Txn != nil {
+			obj.Txn.Root().Walk(func(k []byte, v interface{}) bool {
+				var value types.Hash
+				if v != nil {
+					value = types.BytesToHash(v.([]byte)) //nolint:forcetypeassert
+				}
+
+				exec.writes.slots[slotKey{addr: addr, key: types.BytesToHash(k)}] = value
+
+				return false
+			})
+		}
+
+		return false
+	})
+}
+
+// applyWrites writes the write set of a transaction executed in parallel
+func (txn *Txn) applyWrites(writes *txWrites) {
+	for addr, w := range writes.accounts {
+		obj, exists := txn.getStateObject(addr)
+
+		if w.deleted {
+			if !exists {
+				obj = newStateObject(txn)
+			}
+
+			obj.Deleted = true
+			txn.txn.Insert(addr.Bytes(), obj)
+
+			continue
+		}
+
+		if w.reset || !exists {
+			obj = newStateObject(txn)
+		}
+
+		obj.Account.Nonce = w.nonce
+		obj.Account.Balance = new(big.Int).Set(w.balance)
+		obj.Account.CodeHash = w.codeHash
+
+		if w.dirtyCode {
+			obj.DirtyCode = true
+			obj.Code = w.code
+		}
+
+		txn.txn.Insert(addr.Bytes(), obj)
+	}
+
+	for k, value := range writes.slots {
+		txn.SetState(k.addr, k.key, value)
+	}
+}
+
+// parallelReader is the reader of a transaction executed in parallel,
+// it records the values read
+type parallelReader struct {
+	p     *parallelBlock
+	i     int
+	reads *txReads
+}
+
+func (r *parallelReader) getStateObject(addr types.Address) (*StateObject, bool) {
+	v, ok := r.reads.accounts[addr]
+	if !ok {
+		v = r.p.account(r.i, addr)
+		r.reads.accounts[addr] = v
+	}
+
+	if !v.exists {
+		return nil, false
+	}
+
+	return r.p.stateObject(addr, v), true
+}
+
+func (r *parallelReader) getState(addr types.Address, key types.Hash) types.Hash {
+	k := slotKey{addr: addr, key: key}
+
+	v, ok := r.reads.slots[k]
+	if !ok {
+		v = r.p.slot(r.i, addr, key)
+		r.reads.slots[k] = v
+	}
+
+	return v
+}
+
+// lockedTrie serializes the lookups of an account trie, which resolve its nodes in place
+type lockedTrie struct {
+	lock *sync.Mutex
+	trie accountTrie
+}
+
+func (l *lockedTrie) Get(k []byte) ([]byte, bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return l.trie.Get(k)
+}
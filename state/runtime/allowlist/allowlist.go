@@ -0,0 +1,242 @@
+package allowlist
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/juanidrobo/polygon-edge/chain"
+	"github.com/juanidrobo/polygon-edge/types"
+)
+
+const (
+	// TxAllowListName is the name of the allowlist of the transaction senders, in the genesis
+	TxAllowListName = "txAllowList"
+
+	// DeployerAllowListName is the name of the allowlist of the contract deployers, in the genesis
+	DeployerAllowListName = "contractDeployerAllowList"
+)
+
+var (
+	// DeployerAllowListAddr is the default address of the allowlist of the contract deployers
+	DeployerAllowListAddr = types.StringToAddress("0x0200000000000000000000000000000000000000")
+
+	// TxAllowListAddr is the default address of the allowlist of the transaction senders
+	TxAllowListAddr = types.StringToAddress("0x0200000000000000000000000000000000000002")
+)
+
+var (
+	ErrSenderNotAllowed   = errors.New("sender is not allowed to send transactions")
+	ErrDeployerNotAllowed = errors.New("sender is not allowed to deploy contracts")
+)
+
+// Role is the role of an address in an allowlist
+type Role uint64
+
+const (
+	// NoRole is the role of the addresses not in the allowlist
+	NoRole Role = iota
+	// EnabledRole is the role of the allowed addresses
+	EnabledRole
+	// AdminRole is the role of the allowed addresses which can change the allowlist
+	AdminRole
+)
+
+// IsEnabled returns true if the role is allowed
+func (r Role) IsEnabled() bool {
+	return r == EnabledRole || r == AdminRole
+}
+
+func (r Role) String() string {
+	switch r {
+	case NoRole:
+		return "none"
+	case EnabledRole:
+		return "enabled"
+	case AdminRole:
+		return "admin"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint64(r))
+	}
+}
+
+// Config is the genesis config of an allowlist, with the initial roles
+type Config struct {
+	Admins  []types.Address `json:"admins,omitempty"`
+	Enabled []types.Address `json:"enabled,omitempty"`
+}
+
+// role returns the initial role of the address
+func (c *Config) role(addr types.Address) Role {
+	for _, admin := range c.Admins {
+		if admin == addr {
+			return AdminRole
+		}
+	}
+
+	for _, enabled := range c.Enabled {
+		if enabled == addr {
+			return EnabledRole
+		}
+	}
+
+	return NoRole
+}
+
+// decodeConfig decodes the genesis config of an allowlist, empty by default
+func decodeConfig(raw json.RawMessage) (*Config, error) {
+	config := &Config{}
+
+	if len(raw) == 0 {
+		return config, nil
+	}
+
+	if err := json.Unmarshal(raw, config); err != nil {
+		return nil, fmt.Errorf("invalid allowlist config: %w", err)
+	}
+
+	return config, nil
+}
+
+// StateReader reads the storage of the accounts
+type StateReader interface {
+	GetState(addr types.Address, key types.Hash) types.Hash
+}
+
+// AccountReader reads the storage and the nonce of the accounts
+type AccountReader interface {
+	StateReader
+	GetNonce(addr types.Address) uint64
+}
+
+// StateWriter writes the storage of the accounts
+type StateWriter interface {
+	SetState(addr types.Address, key types.Hash, value types.Hash)
+}
+
+// roleKey returns the storage slot of the role of the address
+func roleKey(addr types.Address) types.Hash {
+	return types.BytesToHash(addr.Bytes())
+}
+
+// roleValue returns the storage value of the role
+func roleValue(role Role) types.Hash {
+	return types.BytesToHash([]byte{byte(role)})
+}
+
+// decodeRole decodes the storage value of a role
+func decodeRole(value types.Hash) Role {
+	return Role(value[types.HashLength-1])
+}
+
+// list is an allowlist enabled from a block
+type list struct {
+	addr   types.Address
+	block  chain.Fork
+	config *Config
+}
+
+// role returns the role of the address in the allowlist
+func (l *list) role(state StateReader, addr types.Address) Role {
+	return decodeRole(state.GetState(l.addr, roleKey(addr)))
+}
+
+// Policy enforces the allowlists enabled by the chain params on the
+// transaction senders and on the contract deployers
+type Policy struct {
+	lists    map[types.Address]*list
+	sender   *list
+	deployer *list
+}
+
+// NewPolicy creates the policy of the allowlists among the stateful precompiled contracts
+func NewPolicy(configs []*chain.StatefulPrecompile) (*Policy, error) {
+	p := &Policy{
+		lists: map[types.Address]*list{},
+	}
+
+	for _, c := range configs {
+		if c.Name != TxAllowListName && c.Name != DeployerAllowListName {
+			continue
+		}
+
+		config, err := decodeConfig(c.Config)
+		if err != nil {
+			return nil, err
+		}
+
+		l := &list{
+			addr:   c.Address,
+			block:  c.Block,
+			config: config,
+		}
+
+		if c.Name == TxAllowListName {
+			p.sender = l
+		} else {
+			p.deployer = l
+		}
+
+		p.lists[c.Address] = l
+	}
+
+	return p, nil
+}
+
+// check returns the error if the allowlist is enabled at the block, and the address not allowed.
+// The allowlist account gets a nonce once it is set up, the initial roles apply until then,
+// i.e. while checking the block enabling it against the state of its parent
+func (p *Policy) check(l *list, state AccountReader, block uint64, addr types.Address, err error) error {
+	if l == nil || !l.block.Active(block) {
+		return nil
+	}
+
+	role := l.config.role(addr)
+	if state.GetNonce(l.addr) != 0 {
+		role = l.role(state, addr)
+	}
+
+	if !role.IsEnabled() {
+		return fmt.Errorf("%w: %s", err, addr)
+	}
+
+	return nil
+}
+
+// CheckSender returns ErrSenderNotAllowed if the address can't send transactions at the block
+func (p *Policy) CheckSender(state AccountReader, block uint64, from types.Address) error {
+	if p == nil {
+		return nil
+	}
+
+	return p.check(p.sender, state, block, from, ErrSenderNotAllowed)
+}
+
+// CheckDeployer returns ErrDeployerNotAllowed if the address can't deploy contracts at the block
+func (p *Policy) CheckDeployer(state AccountReader, block uint64, origin types.Address) error {
+	if p == nil {
+		return nil
+	}
+
+	return p.check(p.deployer, state, block, origin, ErrDeployerNotAllowed)
+}
+
+// Init writes the initial roles of the allowlist at the address, if any, once it is enabled
+func (p *Policy) Init(state StateWriter, addr types.Address) {
+	if p == nil {
+		return
+	}
+
+	l, ok := p.lists[addr]
+	if !ok {
+		return
+	}
+
+	for _, enabled := range l.config.Enabled {
+		state.SetState(l.addr, roleKey(enabled), roleValue(EnabledRole))
+	}
+
+	for _, admin := range l.config.Admins {
+		state.SetState(l.addr, roleKey(admin), roleValue(AdminRole))
+	}
+}
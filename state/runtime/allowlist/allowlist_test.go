@@ -0,0 +1,201 @@
+package allowlist
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/juanidrobo/polygon-edge/chain"
+	"github.com/juanidrobo/polygon-edge/state/runtime"
+	"github.com/juanidrobo/polygon-edge/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	addr1 = types.StringToAddress("1")
+	addr2 = types.StringToAddress("2")
+	addr3 = types.StringToAddress("3")
+)
+
+// mockState is an in-memory storage, implementing both the StateReader and the StateWriter
+type mockState map[types.Address]map[types.Hash]types.Hash
+
+func (m mockState) GetState(addr types.Address, key types.Hash) types.Hash {
+	return m[addr][key]
+}
+
+func (m mockState) SetState(addr types.Address, key types.Hash, value types.Hash) {
+	if _, ok := m[addr]; !ok {
+		m[addr] = map[types.Hash]types.Hash{}
+	}
+
+	m[addr][key] = value
+}
+
+// GetNonce returns no nonce, as for the allowlists not set up yet
+func (m mockState) GetNonce(types.Address) uint64 {
+	return 0
+}
+
+// mockSetUpState is an in-memory storage of the allowlists set up by the executor, which gives them a nonce
+type mockSetUpState struct {
+	mockState
+}
+
+func (m mockSetUpState) GetNonce(types.Address) uint64 {
+	return 1
+}
+
+// mockHost is a host over the in-memory storage
+type mockHost struct {
+	runtime.Host
+	state mockState
+}
+
+func (m *mockHost) GetStorage(addr types.Address, key types.Hash) types.Hash {
+	return m.state.GetState(addr, key)
+}
+
+func (m *mockHost) SetStorage(
+	addr types.Address,
+	key types.Hash,
+	value types.Hash,
+	_ *chain.ForksInTime,
+) runtime.StorageStatus {
+	m.state.SetState(addr, key, value)
+
+	return runtime.StorageModified
+}
+
+func TestPolicy(t *testing.T) {
+	policy, err := NewPolicy([]*chain.StatefulPrecompile{
+		{
+			Name:    TxAllowListName,
+			Address: TxAllowListAddr,
+			Block:   chain.Fork(10),
+			Config: []byte(`{
+				"admins": ["0x0000000000000000000000000000000000000001"],
+				"enabled": ["0x0000000000000000000000000000000000000002"]
+			}`),
+		},
+	})
+	assert.NoError(t, err)
+
+	state := mockSetUpState{mockState{}}
+	policy.Init(state, TxAllowListAddr)
+
+	t.Run("should allow the senders with a role", func(t *testing.T) {
+		assert.NoError(t, policy.CheckSender(state, 10, addr1))
+		assert.NoError(t, policy.CheckSender(state, 10, addr2))
+		assert.ErrorIs(t, policy.CheckSender(state, 10, addr3), ErrSenderNotAllowed)
+	})
+
+	t.Run("should read the roles once set up", func(t *testing.T) {
+		changed := mockSetUpState{mockState{}}
+		policy.Init(changed, TxAllowListAddr)
+		changed.SetState(TxAllowListAddr, roleKey(addr2), roleValue(NoRole))
+
+		assert.ErrorIs(t, policy.CheckSender(changed, 10, addr2), ErrSenderNotAllowed)
+	})
+
+	t.Run("should allow the initial roles until set up", func(t *testing.T) {
+		// the parent state of the block enabling the allowlist
+		parent := mockState{}
+
+		assert.NoError(t, policy.CheckSender(parent, 10, addr1))
+		assert.NoError(t, policy.CheckSender(parent, 10, addr2))
+		assert.ErrorIs(t, policy.CheckSender(parent, 10, addr3), ErrSenderNotAllowed)
+	})
+
+	t.Run("should allow all the senders before the block", func(t *testing.T) {
+		assert.NoError(t, policy.CheckSender(state, 9, addr3))
+	})
+
+	t.Run("should allow all the deployers without the deployer allowlist", func(t *testing.T) {
+		assert.NoError(t, policy.CheckDeployer(state, 10, addr3))
+	})
+
+	t.Run("should allow everything without a policy", func(t *testing.T) {
+		var nilPolicy *Policy
+
+		assert.NoError(t, nilPolicy.CheckSender(state, 10, addr3))
+		assert.NoError(t, nilPolicy.CheckDeployer(state, 10, addr3))
+	})
+
+	t.Run("should fail for an invalid config", func(t *testing.T) {
+		_, err := NewPolicy([]*chain.StatefulPrecompile{
+			{Name: DeployerAllowListName, Address: DeployerAllowListAddr, Config: []byte(`{"admins": 1}`)},
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestContract(t *testing.T) {
+	input := func(method [4]byte, addr types.Address) []byte {
+		return append(method[:], types.BytesToHash(addr.Bytes()).Bytes()...)
+	}
+
+	setup := func() (*contract, *mockHost) {
+		c, err := newContract(TxAllowListAddr, nil)
+		assert.NoError(t, err)
+
+		host := &mockHost{state: mockState{}}
+		host.state.SetState(TxAllowListAddr, roleKey(addr1), roleValue(AdminRole))
+		host.state.SetState(TxAllowListAddr, roleKey(addr2), roleValue(EnabledRole))
+
+		return c.(*contract), host
+	}
+
+	t.Run("should read the roles", func(t *testing.T) {
+		c, host := setup()
+
+		for addr, role := range map[types.Address]Role{addr1: AdminRole, addr2: EnabledRole, addr3: NoRole} {
+			ret, gasLeft, err := c.Run(host, nil, addr3, big.NewInt(0), input(readAllowListMethod, addr), 10000, true)
+
+			assert.NoError(t, err)
+			assert.Equal(t, roleValue(role).Bytes(), ret)
+			assert.Equal(t, 10000-ReadAllowListGas, gasLeft)
+		}
+	})
+
+	t.Run("should let the admins change the roles", func(t *testing.T) {
+		c, host := setup()
+
+		_, gasLeft, err := c.Run(host, nil, addr1, big.NewInt(0), input(setNoneMethod, addr2), 30000, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 30000-ModifyAllowListGas, gasLeft)
+		assert.Equal(t, NoRole, c.list.role(host.state, addr2))
+	})
+
+	testCases := []struct {
+		name     string
+		caller   types.Address
+		value    int64
+		input    []byte
+		gas      uint64
+		readOnly bool
+		err      error
+	}{
+		{"should not let the enabled addresses change the roles", addr2, 0, input(setAdminMethod, addr2), 30000, false,
+			errNotAdmin},
+		{"should not change the roles in a static call", addr1, 0, input(setAdminMethod, addr2), 30000, true,
+			errWriteProtection},
+		{"should not accept value", addr1, 1, input(setAdminMethod, addr2), 30000, false, errValueNotAllowed},
+		{"should fail for an invalid input", addr1, 0, setAdminMethod[:], 30000, false, errInvalidInput},
+		{"should fail for an unknown method", addr1, 0, input([4]byte{1, 2, 3, 4}, addr2), 30000, false, errUnknownMethod},
+		{"should fail out of gas", addr1, 0, input(setAdminMethod, addr2), 10000, false, runtime.ErrOutOfGas},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			c, host := setup()
+
+			_, _, err := c.Run(host, nil, tc.caller, big.NewInt(tc.value), tc.input, tc.gas, tc.readOnly)
+
+			assert.ErrorIs(t, err, tc.err)
+			assert.Equal(t, EnabledRole, c.list.role(host.state, addr2))
+		})
+	}
+}
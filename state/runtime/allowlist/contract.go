@@ -0,0 +1,150 @@
+package allowlist
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+
+	"github.com/juanidrobo/polygon-edge/chain"
+	"github.com/juanidrobo/polygon-edge/crypto"
+	"github.com/juanidrobo/polygon-edge/state/runtime"
+	"github.com/juanidrobo/polygon-edge/state/runtime/precompiled"
+	"github.com/juanidrobo/polygon-edge/types"
+)
+
+const (
+	// ReadAllowListGas is the gas cost of reading a role
+	ReadAllowListGas uint64 = 5000
+
+	// ModifyAllowListGas is the gas cost of changing a role
+	ModifyAllowListGas uint64 = 20000
+)
+
+var (
+	errNotAdmin        = errors.New("only the admins can modify the allowlist")
+	errInvalidInput    = errors.New("invalid allowlist input")
+	errUnknownMethod   = errors.New("unknown allowlist method")
+	errWriteProtection = errors.New("can't modify the allowlist in a static call")
+	errValueNotAllowed = errors.New("the allowlist doesn't accept value")
+)
+
+var (
+	// readAllowListMethod is the selector of readAllowList(address) returns (uint256)
+	readAllowListMethod = methodSelector("readAllowList(address)")
+
+	// the selectors of the methods setting the role of an address, callable by the admins
+	setAdminMethod   = methodSelector("setAdmin(address)")
+	setEnabledMethod = methodSelector("setEnabled(address)")
+	setNoneMethod    = methodSelector("setNone(address)")
+)
+
+func methodSelector(signature string) [4]byte {
+	var selector [4]byte
+
+	copy(selector[:], crypto.Keccak256([]byte(signature)))
+
+	return selector
+}
+
+func init() {
+	precompiled.RegisterStatefulContract(TxAllowListName, newContract)
+	precompiled.RegisterStatefulContract(DeployerAllowListName, newContract)
+}
+
+// contract is the stateful precompiled contract managing an allowlist,
+// with the interface:
+//
+//	function readAllowList(address addr) external view returns (uint256 role);
+//	function setAdmin(address addr) external;
+//	function setEnabled(address addr) external;
+//	function setNone(address addr) external;
+type contract struct {
+	list *list
+}
+
+func newContract(addr types.Address, raw json.RawMessage) (precompiled.StatefulContract, error) {
+	config, err := decodeConfig(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return &contract{
+		list: &list{
+			addr:   addr,
+			config: config,
+		},
+	}, nil
+}
+
+// hostState reads the state through the host
+type hostState struct {
+	host runtime.Host
+}
+
+func (h *hostState) GetState(addr types.Address, key types.Hash) types.Hash {
+	return h.host.GetStorage(addr, key)
+}
+
+// Run implements the precompiled.StatefulContract interface
+func (c *contract) Run(
+	host runtime.Host,
+	config *chain.ForksInTime,
+	caller types.Address,
+	value *big.Int,
+	input []byte,
+	gas uint64,
+	readOnly bool,
+) ([]byte, uint64, error) {
+	if value != nil && value.Sign() != 0 {
+		return nil, 0, errValueNotAllowed
+	}
+
+	// the input is the method selector, followed by the ABI encoded address
+	if len(input) != 4+32 {
+		return nil, 0, errInvalidInput
+	}
+
+	var method [4]byte
+
+	copy(method[:], input[:4])
+	addr := types.BytesToAddress(input[4:])
+
+	if method == readAllowListMethod {
+		if gas < ReadAllowListGas {
+			return nil, 0, runtime.ErrOutOfGas
+		}
+
+		role := c.list.role(&hostState{host}, addr)
+
+		return roleValue(role).Bytes(), gas - ReadAllowListGas, nil
+	}
+
+	var role Role
+
+	switch method {
+	case setAdminMethod:
+		role = AdminRole
+	case setEnabledMethod:
+		role = EnabledRole
+	case setNoneMethod:
+		role = NoRole
+	default:
+		return nil, 0, errUnknownMethod
+	}
+
+	if gas < ModifyAllowListGas {
+		return nil, 0, runtime.ErrOutOfGas
+	}
+
+	if readOnly {
+		return nil, 0, errWriteProtection
+	}
+
+	if c.list.role(&hostState{host}, caller) != AdminRole {
+		return nil, 0, errNotAdmin
+	}
+
+	host.SetStorage(c.list.addr, roleKey(addr), roleValue(role), config)
+
+	return nil, gas - ModifyAllowListGas, nil
+}
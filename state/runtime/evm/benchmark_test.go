@@ -0,0 +1,498 @@
+package evm
+
+import (
+	"encoding/hex"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/juanidrobo/polygon-edge/chain"
+	"github.com/juanidrobo/polygon-edge/crypto"
+	"github.com/juanidrobo/polygon-edge/state/runtime"
+	"github.com/juanidrobo/polygon-edge/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const benchGas = 10_000_000
+
+var (
+	benchHolder = types.StringToAddress("1000")
+	benchToken0 = types.StringToAddress("2000")
+	benchToken1 = types.StringToAddress("2001")
+	benchPair   = types.StringToAddress("3000")
+
+	// the selectors of the erc20 and pair methods
+	transferSelector     = []byte{0xa9, 0x05, 0x9c, 0xbb}
+	transferFromSelector = []byte{0x23, 0xb8, 0x72, 0xdd}
+	approveSelector      = []byte{0x09, 0x5e, 0xa7, 0xb3}
+	balanceOfSelector    = []byte{0x70, 0xa0, 0x82, 0x31}
+	swapSelector         = []byte{0x94, 0xb9, 0x18, 0xde}
+)
+
+// benchAccount is an account of the benchmark host
+type benchAccount struct {
+	code     []byte
+	codeHash types.Hash
+	storage  map[types.Hash]types.Hash
+}
+
+// benchHost is an in-memory host running the calls with the EVM,
+// enough for the contracts of the benchmarks which do not revert
+type benchHost struct {
+	evm      *EVM
+	config   chain.ForksInTime
+	accounts map[types.Address]*benchAccount
+	logs     int
+}
+
+func newBenchHost(evm *EVM) *benchHost {
+	return &benchHost{
+		evm:      evm,
+		config:   chain.AllForksEnabled.At(0),
+		accounts: map[types.Address]*benchAccount{},
+	}
+}
+
+func (h *benchHost) account(addr types.Address) *benchAccount {
+	acc, ok := h.accounts[addr]
+	if !ok {
+		acc = &benchAccount{storage: map[types.Hash]types.Hash{}}
+		h.accounts[addr] = acc
+	}
+
+	return acc
+}
+
+// deploy runs the init code and stores the returned code in the account
+func (h *benchHost) deploy(t testing.TB, addr types.Address, initCode []byte) {
+	t.Helper()
+
+	c := runtime.NewContractCreation(1, benchHolder, benchHolder, addr, big.NewInt(0), benchGas, initCode)
+
+	res := h.evm.Run(c, h, &h.config)
+	if res.Failed() {
+		t.Fatalf("failed to deploy the contract: %v", res.Err)
+	}
+
+	acc := h.account(addr)
+	acc.code = res.ReturnValue
+	acc.codeHash = types.BytesToHash(crypto.Keccak256(res.ReturnValue))
+}
+
+// call runs the call of the contract and checks it returns the expected word
+func (h *benchHost) call(t testing.TB, from, to types.Address, input []byte, expected *big.Int) {
+	t.Helper()
+
+	acc := h.account(to)
+
+	c := runtime.NewContractCall(1, from, from, to, big.NewInt(0), benchGas, acc.code, input)
+	c.CodeHash = acc.codeHash
+
+	res := h.evm.Run(c, h, &h.config)
+	if res.Failed() {
+		t.Fatalf("call failed: %v", res.Err)
+	}
+
+	if expected != nil && new(big.Int).SetBytes(res.ReturnValue).Cmp(expected) != 0 {
+		t.Fatalf("unexpected result 0x%x, expected %s", res.ReturnValue, expected)
+	}
+}
+
+func (h *benchHost) AccountExists(addr types.Address) bool {
+	_, ok := h.accounts[addr]
+
+	return ok
+}
+
+func (h *benchHost) GetStorage(addr types.Address, key types.Hash) types.Hash {
+	return h.account(addr).storage[key]
+}
+
+func (h *benchHost) SetStorage(
+	addr types.Address,
+	key types.Hash,
+	value types.Hash,
+	config *chain.ForksInTime,
+) runtime.StorageStatus {
+	storage := h.account(addr).storage
+	current := storage[key]
+	storage[key] = value
+
+	switch {
+	case current == value:
+		return runtime.StorageUnchanged
+	case current == types.ZeroHash:
+		return runtime.StorageAdded
+	case value == types.ZeroHash:
+		return runtime.StorageDeleted
+	default:
+		return runtime.StorageModified
+	}
+}
+
+func (h *benchHost) GetTransientStorage(addr types.Address, key types.Hash) types.Hash {
+	return types.ZeroHash
+}
+
+func (h *benchHost) SetTransientStorage(addr types.Address, key types.Hash, value types.Hash) {
+}
+
+func (h *benchHost) GetBalance(addr types.Address) *big.Int {
+	return big.NewInt(0)
+}
+
+func (h *benchHost) GetCodeSize(addr types.Address) int {
+	return len(h.account(addr).code)
+}
+
+func (h *benchHost) GetCodeHash(addr types.Address) types.Hash {
+	return h.account(addr).codeHash
+}
+
+func (h *benchHost) GetCode(addr types.Address) []byte {
+	return h.account(addr).code
+}
+
+func (h *benchHost) Selfdestruct(addr types.Address, beneficiary types.Address) {
+}
+
+func (h *benchHost) GetTxContext() runtime.TxContext {
+	return runtime.TxContext{Origin: benchHolder}
+}
+
+func (h *benchHost) GetBlockHash(number int64) types.Hash {
+	return types.ZeroHash
+}
+
+func (h *benchHost) EmitLog(addr types.Address, topics []types.Hash, data []byte) {
+	h.logs++
+}
+
+func (h *benchHost) Callx(c *runtime.Contract, host runtime.Host) *runtime.ExecutionResult {
+	return h.evm.Run(c, host, &h.config)
+}
+
+func (h *benchHost) Empty(addr types.Address) bool {
+	return !h.AccountExists(addr)
+}
+
+func (h *benchHost) GetNonce(addr types.Address) uint64 {
+	return 0
+}
+
+func (h *benchHost) Aborted() bool {
+	return false
+}
+
+// abiCall encodes the call of the method with the selector, the arguments
+// being addresses or *big.Int values
+func abiCall(selector []byte, args ...interface{}) []byte {
+	input := append([]byte{}, selector...)
+
+	for _, arg := range args {
+		switch arg := arg.(type) {
+		case types.Address:
+			input = append(input, types.BytesToHash(arg.Bytes()).Bytes()...)
+		case *big.Int:
+			input = append(input, types.BytesToHash(arg.Bytes()).Bytes()...)
+		default:
+			panic("unsupported argument")
+		}
+	}
+
+	return input
+}
+
+// erc20InitCode returns the init code of an erc20 token minting the supply to its deployer
+func erc20InitCode(t testing.TB, supply *big.Int) []byte {
+	t.Helper()
+
+	data, err := os.ReadFile("testdata/erc20.hex")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// constructor(uint256 supply, string name, string symbol)
+	args := abiCall(nil, supply, big.NewInt(0x60), big.NewInt(0xa0))
+	for _, str := range []string{"Token", "TKN"} {
+		args = append(args, types.BytesToHash(big.NewInt(int64(len(str))).Bytes()).Bytes()...)
+		args = append(args, types.BytesToHash([]byte(str)).Bytes()...)
+	}
+
+	// the strings are left aligned
+	copy(args[0x80:0xa0], append([]byte("Token"), make([]byte, 27)...))
+	copy(args[0xc0:0xe0], append([]byte("TKN"), make([]byte, 29)...))
+
+	return append(code, args...)
+}
+
+// assembler builds the code from the instructions, resolving the jump destinations
+type assembler struct {
+	code   []byte
+	labels map[string]int
+	refs   map[int]string
+}
+
+func newAssembler() *assembler {
+	return &assembler{labels: map[string]int{}, refs: map[int]string{}}
+}
+
+func (a *assembler) op(ops ...OpCode) *assembler {
+	for _, op := range ops {
+		a.code = append(a.code, byte(op))
+	}
+
+	return a
+}
+
+func (a *assembler) push(v []byte) *assembler {
+	if len(v) == 0 {
+		v = []byte{0}
+	}
+
+	a.code = append(a.code, byte(PUSH1)+byte(len(v)-1))
+	a.code = append(a.code, v...)
+
+	return a
+}
+
+func (a *assembler) pushInt(v uint64) *assembler {
+	return a.push(new(big.Int).SetUint64(v).Bytes())
+}
+
+func (a *assembler) pushLabel(name string) *assembler {
+	a.code = append(a.code, byte(PUSH1)+1)
+	a.refs[len(a.code)] = name
+	a.code = append(a.code, 0, 0)
+
+	return a
+}
+
+func (a *assembler) label(name string) *assembler {
+	a.labels[name] = len(a.code)
+
+	return a.op(JUMPDEST)
+}
+
+func (a *assembler) bytes() []byte {
+	for pos, name := range a.refs {
+		dest := a.labels[name]
+		a.code[pos], a.code[pos+1] = byte(dest>>8), byte(dest)
+	}
+
+	return a.code
+}
+
+// selector stores the selector at the start of the memory
+func (a *assembler) selector(selector []byte) *assembler {
+	return a.push(selector).pushInt(0xe0).op(SHL).pushInt(0).op(MSTORE)
+}
+
+// call calls the token with the input in memory, reverting if it fails
+func (a *assembler) call(op OpCode, token types.Address, inputSize uint64) *assembler {
+	a.pushInt(0x20).pushInt(0x80).pushInt(inputSize).pushInt(0)
+
+	if op == CALL {
+		a.pushInt(0)
+	}
+
+	return a.push(token.Bytes()).op(GAS, op, ISZERO).pushLabel("revert").op(JUMPI)
+}
+
+// pairCode returns the code of a Uniswap V2 style pair of the tokens,
+// with a swap(uint256 amountIn) method swapping token0 for token1
+func pairCode() []byte {
+	a := newAssembler()
+
+	// dispatch
+	a.pushInt(0).op(CALLDATALOAD).pushInt(0xe0).op(SHR)
+	a.push(swapSelector).op(EQ).pushLabel("swap").op(JUMPI)
+	a.label("revert").pushInt(0).op(DUP1, REVERT)
+
+	a.label("swap")
+
+	// amountIn at 0x100
+	a.pushInt(4).op(CALLDATALOAD).pushInt(0x100).op(MSTORE)
+
+	// amountOut = amountIn * 997 * reserve1 / (reserve0 * 1000 + amountIn * 997) at 0x120
+	a.pushInt(0x100).op(MLOAD).pushInt(997).op(MUL)
+	a.op(DUP1).pushInt(1).op(SLOAD, MUL, SWAP1)
+	a.pushInt(1000).pushInt(0).op(SLOAD, MUL, ADD)
+	a.op(SWAP1, DIV).pushInt(0x120).op(MSTORE)
+
+	// token0.transferFrom(msg.sender, this, amountIn)
+	a.selector(transferFromSelector)
+	a.op(CALLER).pushInt(0x04).op(MSTORE)
+	a.op(ADDRESS).pushInt(0x24).op(MSTORE)
+	a.pushInt(0x100).op(MLOAD).pushInt(0x44).op(MSTORE)
+	a.call(CALL, benchToken0, 0x64)
+
+	// token1.transfer(msg.sender, amountOut)
+	a.selector(transferSelector)
+	a.op(CALLER).pushInt(0x04).op(MSTORE)
+	a.pushInt(0x120).op(MLOAD).pushInt(0x24).op(MSTORE)
+	a.call(CALL, benchToken1, 0x44)
+
+	// update the reserves with the balances
+	for i, token := range []types.Address{benchToken0, benchToken1} {
+		a.selector(balanceOfSelector)
+		a.op(ADDRESS).pushInt(0x04).op(MSTORE)
+		a.call(STATICCALL, token, 0x24)
+		a.pushInt(0x80).op(MLOAD).pushInt(uint64(i)).op(SSTORE)
+	}
+
+	// emit Sync(reserve0, reserve1)
+	a.pushInt(0).op(SLOAD).pushInt(0x140).op(MSTORE)
+	a.pushInt(1).op(SLOAD).pushInt(0x160).op(MSTORE)
+	a.push(crypto.Keccak256([]byte("Sync(uint112,uint112)"))).pushInt(0x40).pushInt(0x140).op(LOG1)
+
+	// return amountOut
+	a.pushInt(0x20).pushInt(0x120).op(RETURN)
+
+	return a.bytes()
+}
+
+// benchWorkload sets up the contracts and returns the call run by every iteration
+type benchWorkload func(t testing.TB, h *benchHost) func(i int)
+
+var benchWorkloads = []struct {
+	name     string
+	workload benchWorkload
+}{
+	{"erc20-transfer", erc20TransferWorkload},
+	{"erc20-balanceOf", erc20BalanceOfWorkload},
+	{"pair-swap", pairSwapWorkload},
+}
+
+func erc20TransferWorkload(t testing.TB, h *benchHost) func(i int) {
+	t.Helper()
+
+	h.deploy(t, benchToken0, erc20InitCode(t, new(big.Int).Lsh(big.NewInt(1), 128)))
+
+	one := big.NewInt(1)
+
+	return func(i int) {
+		recipient := types.StringToAddress(string(rune(0x4000 + i%256)))
+		h.call(t, benchHolder, benchToken0, abiCall(transferSelector, recipient, one), one)
+	}
+}
+
+func erc20BalanceOfWorkload(t testing.TB, h *benchHost) func(i int) {
+	t.Helper()
+
+	supply := new(big.Int).Lsh(big.NewInt(1), 128)
+	h.deploy(t, benchToken0, erc20InitCode(t, supply))
+
+	input := abiCall(balanceOfSelector, benchHolder)
+
+	return func(i int) {
+		h.call(t, benchHolder, benchToken0, input, supply)
+	}
+}
+
+func pairSwapWorkload(t testing.TB, h *benchHost) func(i int) {
+	t.Helper()
+
+	supply := new(big.Int).Lsh(big.NewInt(1), 128)
+	reserve := new(big.Int).Lsh(big.NewInt(1), 100)
+
+	h.deploy(t, benchToken0, erc20InitCode(t, supply))
+	h.deploy(t, benchToken1, erc20InitCode(t, supply))
+
+	pair := h.account(benchPair)
+	pair.code = pairCode()
+	pair.codeHash = types.BytesToHash(crypto.Keccak256(pair.code))
+
+	// provide the liquidity, and allow the pair to take the tokens of the holder
+	for i, token := range []types.Address{benchToken0, benchToken1} {
+		h.call(t, benchHolder, token, abiCall(transferSelector, benchPair, reserve), big.NewInt(1))
+		pair.storage[types.BytesToHash([]byte{byte(i)})] = types.BytesToHash(reserve.Bytes())
+	}
+
+	h.call(t, benchHolder, benchToken0, abiCall(approveSelector, benchPair, supply), big.NewInt(1))
+
+	amountIn := big.NewInt(1_000_000)
+	input := abiCall(swapSelector, amountIn)
+
+	return func(i int) {
+		h.call(t, benchHolder, benchPair, input, nil)
+	}
+}
+
+func TestBenchmarkWorkloads(t *testing.T) {
+	for _, w := range benchWorkloads {
+		w := w
+
+		t.Run(w.name, func(t *testing.T) {
+			h := newBenchHost(NewEVM())
+
+			run := w.workload(t, h)
+			for i := 0; i < 10; i++ {
+				run(i)
+			}
+		})
+	}
+
+	t.Run("pair-swap output", func(t *testing.T) {
+		h := newBenchHost(NewEVM())
+		run := pairSwapWorkload(t, h)
+
+		logs := h.logs
+		run(0)
+
+		// the swap emits the logs of both transfers and the sync
+		assert.Equal(t, 3, h.logs-logs)
+
+		// the reserves follow the balances of the pair
+		reserve := new(big.Int).Lsh(big.NewInt(1), 100)
+		amountIn := big.NewInt(1_000_000)
+
+		amountOut := new(big.Int).Mul(amountIn, big.NewInt(997))
+		amountOut.Mul(amountOut, reserve)
+		amountOut.Div(amountOut, new(big.Int).Add(
+			new(big.Int).Mul(reserve, big.NewInt(1000)),
+			new(big.Int).Mul(amountIn, big.NewInt(997)),
+		))
+
+		storage := h.accounts[benchPair].storage
+		reserve0 := storage[types.BytesToHash([]byte{0})]
+		reserve1 := storage[types.BytesToHash([]byte{1})]
+
+		assert.Equal(t, new(big.Int).Add(reserve, amountIn), new(big.Int).SetBytes(reserve0.Bytes()))
+		assert.Equal(t, new(big.Int).Sub(reserve, amountOut), new(big.Int).SetBytes(reserve1.Bytes()))
+	})
+}
+
+// BenchmarkWorkloads runs the workloads with and without the code analysis cache
+func BenchmarkWorkloads(b *testing.B) {
+	for _, w := range benchWorkloads {
+		w := w
+
+		b.Run(w.name, func(b *testing.B) {
+			for _, cached := range []bool{true, false} {
+				name, evm := "analysis-cache", NewEVM()
+				if !cached {
+					name, evm = "no-analysis-cache", &EVM{}
+				}
+
+				b.Run(name, func(b *testing.B) {
+					h := newBenchHost(evm)
+					run := w.workload(b, h)
+
+					b.ResetTimer()
+					b.ReportAllocs()
+
+					for i := 0; i < b.N; i++ {
+						run(i)
+					}
+				})
+			}
+		})
+	}
+}
@@ -2,19 +2,31 @@ package evm
 
 import (
 	"errors"
+
+	lru "github.com/hashicorp/golang-lru"
 	"github.com/juanidrobo/polygon-edge/chain"
 	"github.com/juanidrobo/polygon-edge/state/runtime"
+	"github.com/juanidrobo/polygon-edge/types"
 )
 
 var _ runtime.Runtime = &EVM{}
 
+// codeAnalysisCacheSize is the number of codes whose jump destinations are cached
+const codeAnalysisCacheSize = 4096
+
 // EVM is the ethereum virtual machine
 type EVM struct {
+	// analysis caches the jump destinations of the codes by their hash
+	analysis *lru.Cache
 }
 
 // NewEVM creates a new EVM
 func NewEVM() *EVM {
-	return &EVM{}
+	analysis, _ := lru.New(codeAnalysisCacheSize)
+
+	return &EVM{
+		analysis: analysis,
+	}
 }
 
 // CanRun implements the runtime interface
@@ -39,7 +51,7 @@ func (e *EVM) Run(c *runtime.Contract, host runtime.Host, config *chain.ForksInT
 	contract.host = host
 	contract.config = config
 
-	contract.bitmap.setCode(c.Code)
+	contract.bitmap = e.codeBitmap(c, &contract.codeBitmap)
 
 	ret, err := contract.Run()
 
@@ -61,3 +73,25 @@ func (e *EVM) Run(c *runtime.Contract, host runtime.Host, config *chain.ForksInT
 		Err:         err,
 	}
 }
+
+// codeBitmap returns the jump destinations of the contract code, from the analysis cache
+// if the code is stored in an account, or analyzed into the buffer otherwise
+func (e *EVM) codeBitmap(c *runtime.Contract, buf *bitmap) *bitmap {
+	if e.analysis == nil || c.CodeHash == types.ZeroHash {
+		buf.setCode(c.Code)
+
+		return buf
+	}
+
+	if b, ok := e.analysis.Get(c.CodeHash); ok {
+		//nolint:forcetypeassert
+		return b.(*bitmap)
+	}
+
+	b := &bitmap{}
+	b.setCode(c.Code)
+
+	e.analysis.Add(c.CodeHash, b)
+
+	return b
+}
@@ -87,6 +87,18 @@ func (m *mockHost) GetNonce(addr types.Address) uint64 {
 	panic("Not implemented in tests")
 }
 
+func (m *mockHost) GetTransientStorage(addr types.Address, key types.Hash) types.Hash {
+	panic("Not implemented in tests")
+}
+
+func (m *mockHost) SetTransientStorage(addr types.Address, key types.Hash, value types.Hash) {
+	panic("Not implemented in tests")
+}
+
+func (m *mockHost) Aborted() bool {
+	return false
+}
+
 func TestRun(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -164,3 +176,37 @@ func TestRun(t *testing.T) {
 		})
 	}
 }
+
+func TestRun_CodeAnalysisCache(t *testing.T) {
+	// jumps over the revert to the jump destination
+	code := []byte{PUSH1, 0x04, JUMP, REVERT, JUMPDEST, byte(STOP)}
+	codeHash := types.StringToHash("1")
+
+	// the jump destination is within the push data
+	invalidCode := []byte{PUSH1, 0x04, JUMP, PUSH1, JUMPDEST, byte(STOP)}
+
+	evm := NewEVM()
+	config := &chain.ForksInTime{}
+
+	run := func(code []byte, codeHash types.Hash) *runtime.ExecutionResult {
+		contract := newMockContract(big.NewInt(0), 5000, code)
+		contract.CodeHash = codeHash
+
+		return evm.Run(contract, &mockHost{}, config)
+	}
+
+	assert.NoError(t, run(code, codeHash).Err)
+	assert.Equal(t, 1, evm.analysis.Len())
+
+	// the codes not stored in an account are not cached
+	assert.ErrorIs(t, run(invalidCode, types.ZeroHash).Err, errInvalidJump)
+	assert.Equal(t, 1, evm.analysis.Len())
+
+	// the cached analysis is not modified by the other runs
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, run(code, codeHash).Err)
+		assert.ErrorIs(t, run(invalidCode, types.StringToHash("2")).Err, errInvalidJump)
+	}
+
+	assert.Equal(t, 2, evm.analysis.Len())
+}
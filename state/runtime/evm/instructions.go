@@ -539,6 +539,38 @@ func opSStore(c *state) {
 	}
 }
 
+func opTload(c *state) {
+	if !c.config.EIP1153 {
+		c.exit(errOpCodeNotFound)
+
+		return
+	}
+
+	loc := c.top()
+
+	val := c.host.GetTransientStorage(c.msg.Address, bigToHash(loc))
+	loc.SetBytes(val.Bytes())
+}
+
+func opTstore(c *state) {
+	if !c.config.EIP1153 {
+		c.exit(errOpCodeNotFound)
+
+		return
+	}
+
+	if c.inStaticCall() {
+		c.exit(errWriteProtection)
+
+		return
+	}
+
+	key := c.popHash()
+	val := c.popHash()
+
+	c.host.SetTransientStorage(c.msg.Address, key, val)
+}
+
 const sha3WordGas uint64 = 6
 
 func opSha3(c *state) {
@@ -802,6 +834,35 @@ func opCallDataCopy(c *state) {
 	}
 }
 
+func opMCopy(c *state) {
+	if !c.config.EIP5656 {
+		c.exit(errOpCodeNotFound)
+
+		return
+	}
+
+	dstOffset := c.pop()
+	srcOffset := c.pop()
+	length := c.pop()
+
+	// the memory is expanded to cover both the source and the destination
+	if !c.checkMemory(srcOffset, length) || !c.checkMemory(dstOffset, length) {
+		return
+	}
+
+	size := length.Uint64()
+	if !c.consumeGas(((size + 31) / 32) * copyGas) {
+		return
+	}
+
+	if size != 0 {
+		src, dst := srcOffset.Uint64(), dstOffset.Uint64()
+
+		// copy handles the overlapping regions
+		copy(c.memory[dst:dst+size], c.memory[src:src+size])
+	}
+}
+
 func opReturnDataCopy(c *state) {
 	if !c.config.Byzantium {
 		c.exit(errOpCodeNotFound)
@@ -959,6 +1020,16 @@ func opJumpi(c *state) {
 func opJumpDest(c *state) {
 }
 
+func opPush0(c *state) {
+	if !c.config.EIP3855 {
+		c.exit(errOpCodeNotFound)
+
+		return
+	}
+
+	c.push1().Set(zero)
+}
+
 func opPush(n int) instruction {
 	return func(c *state) {
 		ins := c.code
@@ -1276,6 +1347,7 @@ func (c *state) buildCallContract(op OpCode) (*runtime.Contract, uint64, uint64,
 		c.host.GetCode(addr),
 		args,
 	)
+	contract.CodeHash = c.host.GetCodeHash(addr)
 
 	if op == STATICCALL || parent.msg.Static {
 		contract.Static = true
@@ -1346,6 +1418,19 @@ func (c *state) buildCreateContract(op OpCode) (*runtime.Contract, error) {
 		}
 	}
 
+	if c.config.EIP3860 {
+		// The initcode is limited and metered by eip-3860
+		if len(input) > runtime.MaxInitCodeSize {
+			c.exit(runtime.ErrMaxInitCodeSizeExceeded)
+
+			return nil, nil
+		}
+
+		if !c.consumeGas(((uint64(len(input)) + 31) / 32) * runtime.InitCodeWordGas) {
+			return nil, nil
+		}
+	}
+
 	// Calculate and consume gas for the call
 	gas := c.gas
 
@@ -99,6 +99,41 @@ func TestMStore(t *testing.T) {
 	assert.Len(t, s.memory, 1024+32)
 }
 
+func TestMCopy(t *testing.T) {
+	t.Run("should copy overlapping regions and expand the memory", func(t *testing.T) {
+		s, closeFn := getState()
+		defer closeFn()
+
+		s.config = &chain.ForksInTime{EIP5656: true}
+		s.gas = 1000
+
+		s.push(big.NewInt(0x0102030405))
+		s.push(big.NewInt(0))
+		opMStore(s)
+
+		s.push(big.NewInt(32)) // length
+		s.push(big.NewInt(27)) // src
+		s.push(big.NewInt(28)) // dst
+		opMCopy(s)
+
+		assert.Len(t, s.memory, 64)
+		assert.Equal(t, []byte{0x01, 0x01, 0x02, 0x03, 0x04, 0x05}, s.memory[27:33])
+	})
+
+	t.Run("should throw errOpCodeNotFound when EIP5656 is disabled", func(t *testing.T) {
+		s, closeFn := getState()
+		defer closeFn()
+
+		s.config = &chain.ForksInTime{}
+		s.gas = 1000
+
+		opMCopy(s)
+
+		assert.True(t, s.stop)
+		assert.Equal(t, errOpCodeNotFound, s.err)
+	})
+}
+
 type mockHostForCreate struct {
 	mockHost
 	nonce       uint64
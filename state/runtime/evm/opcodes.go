@@ -197,6 +197,18 @@ const (
 	// JUMPDEST corresponds to a possible jump destination
 	JUMPDEST = 0x5B
 
+	// TLOAD reads a (u)int256 from the transient storage
+	TLOAD = 0x5C
+
+	// TSTORE writes a (u)int256 to the transient storage
+	TSTORE = 0x5D
+
+	// MCOPY copies memory to memory
+	MCOPY = 0x5E
+
+	// PUSH0 pushes a 0 value onto the stack
+	PUSH0 = 0x5F
+
 	// PUSH1 pushes a 1-byte value onto the stack
 	PUSH1 = 0x60
 
@@ -320,6 +332,10 @@ var opCodeToString = map[OpCode]string{
 	MSIZE:          "MSIZE",
 	GAS:            "GAS",
 	JUMPDEST:       "JUMPDEST",
+	TLOAD:          "TLOAD",
+	TSTORE:         "TSTORE",
+	MCOPY:          "MCOPY",
+	PUSH0:          "PUSH0",
 	CREATE:         "CREATE",
 	CALL:           "CALL",
 	RETURN:         "RETURN",
@@ -74,8 +74,12 @@ type state struct {
 
 	gas uint64
 
-	// bitvec bitvec
-	bitmap bitmap
+	// bitmap is the jump destinations of the code, which may be shared
+	// through the analysis cache so it is not modified
+	bitmap *bitmap
+
+	// codeBitmap holds the jump destinations of the code not cached
+	codeBitmap bitmap
 
 	returnData []byte
 	ret        []byte
@@ -90,7 +94,8 @@ func (c *state) reset() {
 	c.err = nil
 
 	// reset bitmap
-	c.bitmap.reset()
+	c.bitmap = nil
+	c.codeBitmap.reset()
 
 	// reset memory
 	for i := range c.memory {
@@ -220,6 +225,13 @@ func (c *state) Run() ([]byte, error) {
 			break
 		}
 
+		// the host can abort the execution, i.e. once the timeout of a call is reached
+		if c.host.Aborted() {
+			c.exit(runtime.ErrExecutionAborted)
+
+			break
+		}
+
 		op := OpCode(c.code[c.ip])
 
 		inst := dispatchTable[op]
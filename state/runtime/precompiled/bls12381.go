@@ -0,0 +1,429 @@
+package precompiled
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/juanidrobo/polygon-edge/chain"
+	bls12381 "github.com/kilic/bls12-381"
+)
+
+// The BLS12-381 precompiles of EIP-2537. The field elements are encoded in 64 bytes,
+// big endian and padded with 16 zero bytes, the G2 coordinates as c0 followed by c1
+const (
+	bls12381FpLength     = 64
+	bls12381G1Length     = 2 * bls12381FpLength
+	bls12381G2Length     = 4 * bls12381FpLength
+	bls12381ScalarLength = 32
+
+	// bls12381FpPadding is the number of zero bytes before a 48 bytes field element
+	bls12381FpPadding = 16
+
+	bls12381G1AddGas        = 375
+	bls12381G2AddGas        = 600
+	bls12381G1MulGas        = 12000
+	bls12381G2MulGas        = 22500
+	bls12381PairingBaseGas  = 37700
+	bls12381PairingPointGas = 32600
+	bls12381MapG1Gas        = 5500
+	bls12381MapG2Gas        = 23800
+
+	// bls12381MSMMultiplier is the denominator of the MSM discounts
+	bls12381MSMMultiplier = 1000
+)
+
+var (
+	errBLS12381InvalidInputLength = errors.New("invalid input length")
+	errBLS12381InvalidPadding     = errors.New("invalid field element padding")
+	errBLS12381G1Subgroup         = errors.New("g1 point is not in the correct subgroup")
+	errBLS12381G2Subgroup         = errors.New("g2 point is not in the correct subgroup")
+)
+
+// bls12381G1MSMDiscounts are the discounts of the G1 MSM by number of pairs,
+// the last one applies to any larger number
+var bls12381G1MSMDiscounts = [128]uint64{
+	1000, 949, 848, 797, 764, 750, 738, 728, 719, 712, 705, 698, 692, 687, 682, 677,
+	673, 669, 665, 661, 658, 654, 651, 648, 645, 642, 640, 637, 635, 632, 630, 627,
+	625, 623, 621, 619, 617, 615, 613, 611, 609, 608, 606, 604, 603, 601, 599, 598,
+	596, 595, 593, 592, 591, 589, 588, 586, 585, 584, 582, 581, 580, 579, 577, 576,
+	575, 574, 573, 572, 570, 569, 568, 567, 566, 565, 564, 563, 562, 561, 560, 559,
+	558, 557, 556, 555, 554, 553, 552, 551, 550, 549, 548, 547, 547, 546, 545, 544,
+	543, 542, 541, 540, 540, 539, 538, 537, 536, 536, 535, 534, 533, 532, 532, 531,
+	530, 529, 528, 528, 527, 526, 525, 525, 524, 523, 522, 522, 521, 520, 520, 519,
+}
+
+// bls12381G2MSMDiscounts are the discounts of the G2 MSM by number of pairs,
+// the last one applies to any larger number
+var bls12381G2MSMDiscounts = [128]uint64{
+	1000, 1000, 923, 884, 855, 832, 812, 796, 782, 770, 759, 749, 740, 732, 724, 717,
+	711, 704, 699, 693, 688, 683, 679, 674, 670, 666, 663, 659, 655, 652, 649, 646,
+	643, 640, 637, 634, 632, 629, 627, 624, 622, 620, 618, 615, 613, 611, 609, 607,
+	606, 604, 602, 600, 598, 597, 595, 593, 592, 590, 589, 587, 586, 584, 583, 582,
+	580, 579, 578, 576, 575, 574, 573, 571, 570, 569, 568, 567, 566, 565, 563, 562,
+	561, 560, 559, 558, 557, 556, 555, 554, 553, 552, 552, 551, 550, 549, 548, 547,
+	546, 545, 545, 544, 543, 542, 541, 541, 540, 539, 538, 537, 537, 536, 535, 535,
+	534, 533, 532, 532, 531, 530, 530, 529, 528, 528, 527, 526, 526, 525, 524, 524,
+}
+
+// bls12381MSMGas returns the gas of a MSM of k pairs
+func bls12381MSMGas(k int, mulGas uint64, discounts *[128]uint64) uint64 {
+	if k == 0 {
+		return 0
+	}
+
+	discount := discounts[len(discounts)-1]
+	if k <= len(discounts) {
+		discount = discounts[k-1]
+	}
+
+	return uint64(k) * mulGas * discount / bls12381MSMMultiplier
+}
+
+// decodeBLS12381Fp returns the 48 bytes of a padded field element
+func decodeBLS12381Fp(in []byte) ([]byte, error) {
+	for _, b := range in[:bls12381FpPadding] {
+		if b != 0 {
+			return nil, errBLS12381InvalidPadding
+		}
+	}
+
+	return in[bls12381FpPadding:bls12381FpLength], nil
+}
+
+// decodeBLS12381Fp2 returns the 96 bytes of a padded element of the quadratic extension,
+// in the c1, c0 order of the library
+func decodeBLS12381Fp2(in []byte) ([]byte, error) {
+	c0, err := decodeBLS12381Fp(in[:bls12381FpLength])
+	if err != nil {
+		return nil, err
+	}
+
+	c1, err := decodeBLS12381Fp(in[bls12381FpLength:])
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(c0)+len(c1))
+	out = append(out, c1...)
+
+	return append(out, c0...), nil
+}
+
+// decodeBLS12381G1 decodes a point on the G1 curve, without checking its subgroup
+func decodeBLS12381G1(g *bls12381.G1, in []byte) (*bls12381.PointG1, error) {
+	x, err := decodeBLS12381Fp(in[:bls12381FpLength])
+	if err != nil {
+		return nil, err
+	}
+
+	y, err := decodeBLS12381Fp(in[bls12381FpLength:])
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 0, len(x)+len(y))
+	buf = append(buf, x...)
+
+	return g.FromBytes(append(buf, y...))
+}
+
+// decodeBLS12381G2 decodes a point on the G2 curve, without checking its subgroup
+func decodeBLS12381G2(g *bls12381.G2, in []byte) (*bls12381.PointG2, error) {
+	x, err := decodeBLS12381Fp2(in[:2*bls12381FpLength])
+	if err != nil {
+		return nil, err
+	}
+
+	y, err := decodeBLS12381Fp2(in[2*bls12381FpLength:])
+	if err != nil {
+		return nil, err
+	}
+
+	return g.FromBytes(append(x, y...))
+}
+
+// encodeBLS12381Fp appends the padded encoding of 48 bytes field elements
+func encodeBLS12381Fp(out []byte, in ...[]byte) []byte {
+	for _, fp := range in {
+		out = append(out, make([]byte, bls12381FpPadding)...)
+		out = append(out, fp...)
+	}
+
+	return out
+}
+
+func encodeBLS12381G1(g *bls12381.G1, p *bls12381.PointG1) []byte {
+	buf := g.ToBytes(p)
+
+	return encodeBLS12381Fp(
+		make([]byte, 0, bls12381G1Length),
+		buf[:bls12381FpLength-bls12381FpPadding],
+		buf[bls12381FpLength-bls12381FpPadding:],
+	)
+}
+
+func encodeBLS12381G2(g *bls12381.G2, p *bls12381.PointG2) []byte {
+	// the library encodes the coordinates as c1 followed by c0
+	buf := g.ToBytes(p)
+	fp := bls12381FpLength - bls12381FpPadding
+
+	return encodeBLS12381Fp(
+		make([]byte, 0, bls12381G2Length),
+		buf[fp:2*fp],
+		buf[:fp],
+		buf[3*fp:],
+		buf[2*fp:3*fp],
+	)
+}
+
+type bls12381G1Add struct{}
+
+func (b *bls12381G1Add) gas(input []byte, config *chain.ForksInTime) uint64 {
+	return bls12381G1AddGas
+}
+
+func (b *bls12381G1Add) run(input []byte) ([]byte, error) {
+	if len(input) != 2*bls12381G1Length {
+		return nil, errBLS12381InvalidInputLength
+	}
+
+	g := bls12381.NewG1()
+
+	p0, err := decodeBLS12381G1(g, input[:bls12381G1Length])
+	if err != nil {
+		return nil, err
+	}
+
+	p1, err := decodeBLS12381G1(g, input[bls12381G1Length:])
+	if err != nil {
+		return nil, err
+	}
+
+	r := g.New()
+	g.Add(r, p0, p1)
+
+	return encodeBLS12381G1(g, r), nil
+}
+
+type bls12381G1MSM struct{}
+
+func (b *bls12381G1MSM) gas(input []byte, config *chain.ForksInTime) uint64 {
+	k := len(input) / (bls12381G1Length + bls12381ScalarLength)
+
+	return bls12381MSMGas(k, bls12381G1MulGas, &bls12381G1MSMDiscounts)
+}
+
+func (b *bls12381G1MSM) run(input []byte) ([]byte, error) {
+	size := bls12381G1Length + bls12381ScalarLength
+	if len(input) == 0 || len(input)%size != 0 {
+		return nil, errBLS12381InvalidInputLength
+	}
+
+	g := bls12381.NewG1()
+
+	k := len(input) / size
+	points := make([]*bls12381.PointG1, k)
+	scalars := make([]*big.Int, k)
+
+	for i := 0; i < k; i++ {
+		buf := input[i*size : (i+1)*size]
+
+		p, err := decodeBLS12381G1(g, buf[:bls12381G1Length])
+		if err != nil {
+			return nil, err
+		}
+
+		if !g.InCorrectSubgroup(p) {
+			return nil, errBLS12381G1Subgroup
+		}
+
+		points[i] = p
+		scalars[i] = new(big.Int).SetBytes(buf[bls12381G1Length:])
+	}
+
+	r := g.New()
+
+	if k == 1 {
+		g.MulScalarBig(r, points[0], scalars[0])
+	} else if _, err := g.MultiExpBig(r, points, scalars); err != nil {
+		return nil, err
+	}
+
+	return encodeBLS12381G1(g, r), nil
+}
+
+type bls12381G2Add struct{}
+
+func (b *bls12381G2Add) gas(input []byte, config *chain.ForksInTime) uint64 {
+	return bls12381G2AddGas
+}
+
+func (b *bls12381G2Add) run(input []byte) ([]byte, error) {
+	if len(input) != 2*bls12381G2Length {
+		return nil, errBLS12381InvalidInputLength
+	}
+
+	g := bls12381.NewG2()
+
+	p0, err := decodeBLS12381G2(g, input[:bls12381G2Length])
+	if err != nil {
+		return nil, err
+	}
+
+	p1, err := decodeBLS12381G2(g, input[bls12381G2Length:])
+	if err != nil {
+		return nil, err
+	}
+
+	r := g.New()
+	g.Add(r, p0, p1)
+
+	return encodeBLS12381G2(g, r), nil
+}
+
+type bls12381G2MSM struct{}
+
+func (b *bls12381G2MSM) gas(input []byte, config *chain.ForksInTime) uint64 {
+	k := len(input) / (bls12381G2Length + bls12381ScalarLength)
+
+	return bls12381MSMGas(k, bls12381G2MulGas, &bls12381G2MSMDiscounts)
+}
+
+func (b *bls12381G2MSM) run(input []byte) ([]byte, error) {
+	size := bls12381G2Length + bls12381ScalarLength
+	if len(input) == 0 || len(input)%size != 0 {
+		return nil, errBLS12381InvalidInputLength
+	}
+
+	g := bls12381.NewG2()
+
+	k := len(input) / size
+	points := make([]*bls12381.PointG2, k)
+	scalars := make([]*big.Int, k)
+
+	for i := 0; i < k; i++ {
+		buf := input[i*size : (i+1)*size]
+
+		p, err := decodeBLS12381G2(g, buf[:bls12381G2Length])
+		if err != nil {
+			return nil, err
+		}
+
+		if !g.InCorrectSubgroup(p) {
+			return nil, errBLS12381G2Subgroup
+		}
+
+		points[i] = p
+		scalars[i] = new(big.Int).SetBytes(buf[bls12381G2Length:])
+	}
+
+	r := g.New()
+
+	if k == 1 {
+		g.MulScalarBig(r, points[0], scalars[0])
+	} else if _, err := g.MultiExpBig(r, points, scalars); err != nil {
+		return nil, err
+	}
+
+	return encodeBLS12381G2(g, r), nil
+}
+
+type bls12381Pairing struct{}
+
+func (b *bls12381Pairing) gas(input []byte, config *chain.ForksInTime) uint64 {
+	k := uint64(len(input) / (bls12381G1Length + bls12381G2Length))
+
+	return bls12381PairingBaseGas + bls12381PairingPointGas*k
+}
+
+func (b *bls12381Pairing) run(input []byte) ([]byte, error) {
+	size := bls12381G1Length + bls12381G2Length
+	if len(input) == 0 || len(input)%size != 0 {
+		return nil, errBLS12381InvalidInputLength
+	}
+
+	e := bls12381.NewEngine()
+
+	for i := 0; i < len(input)/size; i++ {
+		buf := input[i*size : (i+1)*size]
+
+		p1, err := decodeBLS12381G1(e.G1, buf[:bls12381G1Length])
+		if err != nil {
+			return nil, err
+		}
+
+		p2, err := decodeBLS12381G2(e.G2, buf[bls12381G1Length:])
+		if err != nil {
+			return nil, err
+		}
+
+		if !e.G1.InCorrectSubgroup(p1) {
+			return nil, errBLS12381G1Subgroup
+		}
+
+		if !e.G2.InCorrectSubgroup(p2) {
+			return nil, errBLS12381G2Subgroup
+		}
+
+		// the pairs with a point at infinity are skipped
+		e.AddPair(p1, p2)
+	}
+
+	if e.Check() {
+		return trueBytes, nil
+	}
+
+	return falseBytes, nil
+}
+
+type bls12381MapG1 struct{}
+
+func (b *bls12381MapG1) gas(input []byte, config *chain.ForksInTime) uint64 {
+	return bls12381MapG1Gas
+}
+
+func (b *bls12381MapG1) run(input []byte) ([]byte, error) {
+	if len(input) != bls12381FpLength {
+		return nil, errBLS12381InvalidInputLength
+	}
+
+	fp, err := decodeBLS12381Fp(input)
+	if err != nil {
+		return nil, err
+	}
+
+	g := bls12381.NewG1()
+
+	r, err := g.MapToCurve(fp)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeBLS12381G1(g, r), nil
+}
+
+type bls12381MapG2 struct{}
+
+func (b *bls12381MapG2) gas(input []byte, config *chain.ForksInTime) uint64 {
+	return bls12381MapG2Gas
+}
+
+func (b *bls12381MapG2) run(input []byte) ([]byte, error) {
+	if len(input) != 2*bls12381FpLength {
+		return nil, errBLS12381InvalidInputLength
+	}
+
+	fp2, err := decodeBLS12381Fp2(input)
+	if err != nil {
+		return nil, err
+	}
+
+	g := bls12381.NewG2()
+
+	r, err := g.MapToCurve(fp2)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeBLS12381G2(g, r), nil
+}
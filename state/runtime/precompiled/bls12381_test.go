@@ -0,0 +1,166 @@
+//nolint:lll
+package precompiled
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/juanidrobo/polygon-edge/chain"
+	"github.com/juanidrobo/polygon-edge/helper/hex"
+	"github.com/stretchr/testify/assert"
+)
+
+// The fixtures are the EIP-2537 reference vectors, as shipped in the precompile testdata of go-ethereum.
+// The multiplications are single pair MSMs, and every fail-* vector must be rejected
+func TestBLS12381(t *testing.T) {
+	config := &chain.ForksInTime{EIP2537: true}
+
+	cases := []struct {
+		fixture  string
+		contract contract
+	}{
+		{"blsG1Add.json", &bls12381G1Add{}},
+		{"blsG1Mul.json", &bls12381G1MSM{}},
+		{"blsG1MultiExp.json", &bls12381G1MSM{}},
+		{"blsG2Add.json", &bls12381G2Add{}},
+		{"blsG2Mul.json", &bls12381G2MSM{}},
+		{"blsG2MultiExp.json", &bls12381G2MSM{}},
+		{"blsPairing.json", &bls12381Pairing{}},
+		{"blsMapG1.json", &bls12381MapG1{}},
+		{"blsMapG2.json", &bls12381MapG2{}},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.fixture, func(t *testing.T) {
+			ReadTestCase(t, c.fixture, func(t *testing.T, tc *TestCase) {
+				t.Helper()
+
+				out, err := c.contract.run(tc.Input)
+				assert.NoError(t, err)
+				assert.Equal(t, hex.EncodeToHex(tc.Expected), hex.EncodeToHex(out))
+				assert.Equal(t, tc.Gas, c.contract.gas(tc.Input, config))
+			})
+		})
+
+		t.Run("fail-"+c.fixture, func(t *testing.T) {
+			ReadTestCase(t, "fail-"+c.fixture, func(t *testing.T, tc *TestCase) {
+				t.Helper()
+
+				out, err := c.contract.run(tc.Input)
+				assert.Nil(t, out)
+				assert.Error(t, err, tc.ExpectedError)
+			})
+		})
+	}
+}
+
+var (
+	// bls12381G1Generator is the generator of G1
+	bls12381G1Generator = "0000000000000000000000000000000017f1d3a73197d7942695638c4fa9ac0fc3688c4f9774b905a14e3a3f171bac586c55e83ff97a1aeffb3af00adb22c6bb0000000000000000000000000000000008b3f481e3aaa0f1a09e30ed741d8ae4fcf5e095d5d00af600db18cb2c04b3edd03cc744a2888ae40caa232946c5e7e1"
+
+	// bls12381G2Generator is the generator of G2
+	bls12381G2Generator = "00000000000000000000000000000000024aa2b2f08f0a91260805272dc51051c6e47ad4fa403b02b4510b647ae3d1770bac0326a805bbefd48056c8c121bdb80000000000000000000000000000000013e02b6052719f607dacd3a088274f65596bd0d09920b61ab5da61bbdc7f5049334cf11213945d57e5ac7d055d042b7e000000000000000000000000000000000ce5d527727d6e118cc9cdc6da2e351aadfd9baa8cbdd3a76d429a695160d12c923ac9cc3baca289e193548608b82801000000000000000000000000000000000606c4a02ea734cc32acd2b02bc28b99cb3e287e85a763af267492ab572e99ab3f370d275cec1da1aaa9075ff05f79be"
+
+	// bls12381G1NotInSubgroup is a point of the G1 curve outside of the subgroup
+	bls12381G1NotInSubgroup = "00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000004000000000000000000000000000000000a989badd40d6212b33cffc3f3763e9bc760f988c9926b26da9dd85e928483446346b8ed00e1de5d5ea93e354abe706c"
+
+	// bls12381G2NotInSubgroup is a point of the G2 curve outside of the subgroup
+	bls12381G2NotInSubgroup = "00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000018c6b864ae17dc9da64203ffefb966306425a7bc6aeb7c75247438372716284a4173830420cd476ba1a365b95bfcec3800000000000000000000000000000000172e93db764a8400a7d5071b6b6f5de0da2f0f4a063119abca014006b7c40a2cfe291a1924e65db0d6d0fcfbf3bf3d5c"
+
+	// bls12381G1NotOnCurve is the (1, 1) point
+	bls12381G1NotOnCurve = "0000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000100000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000001"
+
+	// bls12381Modulus is the modulus of the field, which is not a valid element
+	bls12381Modulus = "000000000000000000000000000000001a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaab"
+
+	bls12381Scalar = "0000000000000000000000000000000000000000000000000000000000000002"
+)
+
+func TestBLS12381_Errors(t *testing.T) {
+	// a field element with a non zero padding
+	badPadding := "01" + bls12381G1Generator[2:]
+
+	cases := []struct {
+		name     string
+		contract contract
+		input    string
+		err      error
+	}{
+		{"g1add empty input", &bls12381G1Add{}, "", errBLS12381InvalidInputLength},
+		{"g1add short input", &bls12381G1Add{}, bls12381G1Generator, errBLS12381InvalidInputLength},
+		{"g1add bad padding", &bls12381G1Add{}, badPadding + bls12381G1Generator, errBLS12381InvalidPadding},
+		{"g1add not on curve", &bls12381G1Add{}, bls12381G1Generator + bls12381G1NotOnCurve, nil},
+		{"g1msm empty input", &bls12381G1MSM{}, "", errBLS12381InvalidInputLength},
+		{"g1msm short input", &bls12381G1MSM{}, bls12381G1Generator, errBLS12381InvalidInputLength},
+		{"g1msm not in subgroup", &bls12381G1MSM{}, bls12381G1NotInSubgroup + bls12381Scalar, errBLS12381G1Subgroup},
+		{"g2add short input", &bls12381G2Add{}, bls12381G2Generator, errBLS12381InvalidInputLength},
+		{"g2msm empty input", &bls12381G2MSM{}, "", errBLS12381InvalidInputLength},
+		{"g2msm not in subgroup", &bls12381G2MSM{}, bls12381G2NotInSubgroup + bls12381Scalar, errBLS12381G2Subgroup},
+		{"pairing empty input", &bls12381Pairing{}, "", errBLS12381InvalidInputLength},
+		{"pairing g1 not in subgroup", &bls12381Pairing{}, bls12381G1NotInSubgroup + bls12381G2Generator, errBLS12381G1Subgroup},
+		{"pairing g2 not in subgroup", &bls12381Pairing{}, bls12381G1Generator + bls12381G2NotInSubgroup, errBLS12381G2Subgroup},
+		{"mapg1 short input", &bls12381MapG1{}, bls12381Modulus[2:], errBLS12381InvalidInputLength},
+		{"mapg1 bad padding", &bls12381MapG1{}, badPadding[:128], errBLS12381InvalidPadding},
+		{"mapg1 not a field element", &bls12381MapG1{}, bls12381Modulus, nil},
+		{"mapg2 not a field element", &bls12381MapG2{}, bls12381Modulus + bls12381G1Generator[:128], nil},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.name, func(t *testing.T) {
+			out, err := c.contract.run(decodeHex(t, "0x"+c.input))
+			assert.Nil(t, out)
+
+			if c.err != nil {
+				assert.ErrorIs(t, err, c.err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestBLS12381_NoSubgroupCheckOnAdd(t *testing.T) {
+	// the additions only require the points to be on the curves
+	for _, c := range []struct {
+		contract contract
+		input    string
+	}{
+		{&bls12381G1Add{}, bls12381G1NotInSubgroup + bls12381G1Generator},
+		{&bls12381G2Add{}, bls12381G2NotInSubgroup + bls12381G2Generator},
+	} {
+		out, err := c.contract.run(decodeHex(t, "0x"+c.input))
+		assert.NoError(t, err)
+		assert.Len(t, out, len(c.input)/4)
+	}
+}
+
+func TestBLS12381_MSMGas(t *testing.T) {
+	g1Pair := strings.Repeat("00", bls12381G1Length+bls12381ScalarLength)
+	g2Pair := strings.Repeat("00", bls12381G2Length+bls12381ScalarLength)
+
+	cases := []struct {
+		contract contract
+		pair     string
+		k        int
+		gas      uint64
+	}{
+		{&bls12381G1MSM{}, g1Pair, 0, 0},
+		{&bls12381G1MSM{}, g1Pair, 1, 12000},
+		{&bls12381G1MSM{}, g1Pair, 2, 2 * 12000 * 949 / 1000},
+		{&bls12381G1MSM{}, g1Pair, 128, 128 * 12000 * 519 / 1000},
+		{&bls12381G1MSM{}, g1Pair, 200, 200 * 12000 * 519 / 1000},
+		{&bls12381G2MSM{}, g2Pair, 1, 22500},
+		{&bls12381G2MSM{}, g2Pair, 2, 2 * 22500},
+		{&bls12381G2MSM{}, g2Pair, 128, 128 * 22500 * 524 / 1000},
+		{&bls12381G2MSM{}, g2Pair, 200, 200 * 22500 * 524 / 1000},
+	}
+
+	for _, c := range cases {
+		input := decodeHex(t, "0x"+strings.Repeat(c.pair, c.k))
+		assert.Equal(t, c.gas, c.contract.gas(input, &chain.ForksInTime{}))
+	}
+}
@@ -2,7 +2,12 @@
 package precompiled
 
 import (
+	"encoding/hex"
+	"sync"
 	"testing"
+
+	"github.com/juanidrobo/polygon-edge/chain"
+	"github.com/stretchr/testify/assert"
 )
 
 var modExpTests = []precompiledTest{
@@ -91,3 +96,32 @@ func TestModExp(t *testing.T) {
 	p := &Precompiled{}
 	testPrecompiled(t, &modExp{p}, modExpTests)
 }
+
+func TestModExp_Concurrent(t *testing.T) {
+	// the contracts share the runtime, as the transactions of a block may run in parallel
+	m := &modExp{&Precompiled{}}
+	config := &chain.ForksInTime{Byzantium: true}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 8; i++ {
+		for _, c := range modExpTests {
+			c := c
+
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				input, _ := hex.DecodeString(c.Input)
+				m.gas(input, config)
+				found, err := m.run(input)
+
+				assert.NoError(t, err)
+				assert.Equal(t, c.Expected, hex.EncodeToString(found), c.Name)
+			}()
+		}
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,52 @@
+package precompiled
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"math/big"
+
+	"github.com/juanidrobo/polygon-edge/chain"
+)
+
+const (
+	// p256VerifyInputLength is the length of the hash, the r and s signature values
+	// and the x and y coordinates of the public key
+	p256VerifyInputLength = 160
+
+	p256VerifyGas = 3450
+)
+
+// p256Verify is the secp256r1 signature verification precompile of RIP-7212.
+// It returns 1 in 32 bytes if the signature is valid, and nothing otherwise
+type p256Verify struct{}
+
+func (p *p256Verify) gas(input []byte, config *chain.ForksInTime) uint64 {
+	return p256VerifyGas
+}
+
+func (p *p256Verify) run(input []byte) ([]byte, error) {
+	if len(input) != p256VerifyInputLength {
+		return nil, nil
+	}
+
+	hash := input[:32]
+	r := new(big.Int).SetBytes(input[32:64])
+	s := new(big.Int).SetBytes(input[64:96])
+	x := new(big.Int).SetBytes(input[96:128])
+	y := new(big.Int).SetBytes(input[128:160])
+
+	curve := elliptic.P256()
+	params := curve.Params()
+
+	// the coordinates must be reduced, and the point at infinity is not a valid key
+	if x.Cmp(params.P) >= 0 || y.Cmp(params.P) >= 0 || !curve.IsOnCurve(x, y) {
+		return nil, nil
+	}
+
+	// the verification checks that r and s are in [1, n-1]
+	if !ecdsa.Verify(&ecdsa.PublicKey{Curve: curve, X: x, Y: y}, hash, r, s) {
+		return nil, nil
+	}
+
+	return trueBytes, nil
+}
@@ -0,0 +1,77 @@
+package precompiled
+
+import (
+	"testing"
+
+	"github.com/juanidrobo/polygon-edge/chain"
+	"github.com/juanidrobo/polygon-edge/helper/hex"
+	"github.com/stretchr/testify/assert"
+)
+
+// The vectors in the fixtures are the P-256 ones of the NIST signature verification
+// vectors, with the leftmost 256 bits of the message digest as the hash
+func TestP256Verify(t *testing.T) {
+	p := &p256Verify{}
+
+	ReadTestCase(t, "p256Verify.json", func(t *testing.T, c *TestCase) {
+		t.Helper()
+
+		out, err := p.run(c.Input)
+		assert.NoError(t, err)
+		assert.Equal(t, hex.EncodeToHex(c.Expected), hex.EncodeToHex(out))
+		assert.Equal(t, c.Gas, p.gas(c.Input, &chain.ForksInTime{RIP7212: true}))
+	})
+}
+
+func TestP256Verify_InvalidInput(t *testing.T) {
+	p := &p256Verify{}
+
+	valid := decodeHex(t, "0x"+
+		"d1b8ef21eb4182ee270638061063a3f3c16c114e33937f69fb232cc833965a94"+
+		"bf96b99aa49c705c910be33142017c642ff540c76349b9dab72f981fd9347f4f"+
+		"17c55095819089c2e03b9cd415abdf12444e323075d98f31920b9e0f57ec871c"+
+		"e424dc61d4bb3cb7ef4344a7f8957a0c5134e16f7a67c074f82e6e12f49abf3c"+
+		"970eed7aa2bc48651545949de1dddaf0127e5965ac85d1243d6f60e7dfaee927")
+
+	out, err := p.run(valid)
+	assert.NoError(t, err)
+	assert.Equal(t, trueBytes, out)
+
+	cases := map[string]func([]byte) []byte{
+		"short input": func(b []byte) []byte {
+			return b[:len(b)-1]
+		},
+		"long input": func(b []byte) []byte {
+			return append(b, 0)
+		},
+		"zero r": func(b []byte) []byte {
+			copy(b[32:64], make([]byte, 32))
+
+			return b
+		},
+		"point at infinity": func(b []byte) []byte {
+			copy(b[96:160], make([]byte, 64))
+
+			return b
+		},
+		"x above the field modulus": func(b []byte) []byte {
+			for i := 96; i < 128; i++ {
+				b[i] = 0xff
+			}
+
+			return b
+		},
+	}
+
+	for name, mutate := range cases {
+		mutate := mutate
+
+		t.Run(name, func(t *testing.T) {
+			input := mutate(append([]byte{}, valid...))
+
+			out, err := p.run(input)
+			assert.NoError(t, err)
+			assert.Empty(t, out)
+		})
+	}
+}
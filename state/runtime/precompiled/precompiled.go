@@ -17,8 +17,8 @@ type contract interface {
 
 // Precompiled is the runtime for the precompiled contracts
 type Precompiled struct {
-	buf       []byte
 	contracts map[types.Address]contract
+	stateful  map[types.Address]*statefulContract
 }
 
 // NewPrecompiled creates a new runtime for the precompiled contracts
@@ -43,6 +43,18 @@ func (p *Precompiled) setupContracts() {
 
 	// Istanbul fork
 	p.register("9", &blake2f{p})
+
+	// EIP-2537 fork
+	p.register("0b", &bls12381G1Add{})
+	p.register("0c", &bls12381G1MSM{})
+	p.register("0d", &bls12381G2Add{})
+	p.register("0e", &bls12381G2MSM{})
+	p.register("0f", &bls12381Pairing{})
+	p.register("10", &bls12381MapG1{})
+	p.register("11", &bls12381MapG2{})
+
+	// RIP-7212 fork
+	p.register("0100", &p256Verify{})
 }
 
 func (p *Precompiled) register(addrStr string, b contract) {
@@ -59,10 +71,24 @@ var (
 	seven = types.StringToAddress("7")
 	eight = types.StringToAddress("8")
 	nine  = types.StringToAddress("9")
+
+	bls12381G1AddAddr   = types.StringToAddress("0b")
+	bls12381G1MSMAddr   = types.StringToAddress("0c")
+	bls12381G2AddAddr   = types.StringToAddress("0d")
+	bls12381G2MSMAddr   = types.StringToAddress("0e")
+	bls12381PairingAddr = types.StringToAddress("0f")
+	bls12381MapG1Addr   = types.StringToAddress("10")
+	bls12381MapG2Addr   = types.StringToAddress("11")
+
+	p256VerifyAddr = types.StringToAddress("0100")
 )
 
 // CanRun implements the runtime interface
-func (p *Precompiled) CanRun(c *runtime.Contract, _ runtime.Host, config *chain.ForksInTime) bool {
+func (p *Precompiled) CanRun(c *runtime.Contract, host runtime.Host, config *chain.ForksInTime) bool {
+	if s, ok := p.stateful[c.CodeAddress]; ok {
+		return s.block.Active(uint64(host.GetTxContext().Number))
+	}
+
 	if _, ok := p.contracts[c.CodeAddress]; !ok {
 		return false
 	}
@@ -85,6 +111,19 @@ func (p *Precompiled) CanRun(c *runtime.Contract, _ runtime.Host, config *chain.
 		return config.Istanbul
 	}
 
+	// bls12-381 precompiles
+	switch c.CodeAddress {
+	case bls12381G1AddAddr, bls12381G1MSMAddr, bls12381G2AddAddr, bls12381G2MSMAddr,
+		bls12381PairingAddr, bls12381MapG1Addr, bls12381MapG2Addr:
+		return config.EIP2537
+	}
+
+	// secp256r1 precompile
+	switch c.CodeAddress {
+	case p256VerifyAddr:
+		return config.RIP7212
+	}
+
 	return true
 }
 
@@ -94,7 +133,11 @@ func (p *Precompiled) Name() string {
 }
 
 // Run runs an execution
-func (p *Precompiled) Run(c *runtime.Contract, _ runtime.Host, config *chain.ForksInTime) *runtime.ExecutionResult {
+func (p *Precompiled) Run(c *runtime.Contract, host runtime.Host, config *chain.ForksInTime) *runtime.ExecutionResult {
+	if s, ok := p.stateful[c.CodeAddress]; ok {
+		return p.runStateful(s, c, host, config)
+	}
+
 	contract := p.contracts[c.CodeAddress]
 	gasCost := contract.gas(c.Input, config)
 
@@ -123,8 +166,6 @@ func (p *Precompiled) Run(c *runtime.Contract, _ runtime.Host, config *chain.For
 	return result
 }
 
-var zeroPadding = make([]byte, 64)
-
 func (p *Precompiled) leftPad(buf []byte, n int) []byte {
 	// TODO, avoid buffer allocation
 	l := len(buf)
@@ -138,42 +179,18 @@ func (p *Precompiled) leftPad(buf []byte, n int) []byte {
 	return tmp
 }
 
+// get returns the next size bytes of the input, right padded with zeros, and the rest of it.
+// Every call gets its own buffer, as the transactions of a block may run concurrently
 func (p *Precompiled) get(input []byte, size int) ([]byte, []byte) {
-	p.buf = extendByteSlice(p.buf, size)
-	n := size
-
-	if len(input) < n {
-		n = len(input)
-	}
-
-	// copy the part from the input
-	copy(p.buf[0:], input[:n])
-
-	// copy empty values
-	if n < size {
-		rest := size - n
-		if rest < 64 {
-			copy(p.buf[n:], zeroPadding[0:size-n])
-		} else {
-			copy(p.buf[n:], make([]byte, rest))
-		}
-	}
+	buf := make([]byte, size)
+	n := copy(buf, input)
 
-	return p.buf, input[n:]
+	return buf, input[n:]
 }
 
 func (p *Precompiled) getUint64(input []byte) (uint64, []byte) {
-	p.buf, input = p.get(input, 32)
-	num := binary.BigEndian.Uint64(p.buf[24:32])
+	buf, input := p.get(input, 32)
+	num := binary.BigEndian.Uint64(buf[24:32])
 
 	return num, input
 }
-
-func extendByteSlice(b []byte, needLen int) []byte {
-	b = b[:cap(b)]
-	if n := needLen - cap(b); n > 0 {
-		b = append(b, make([]byte, n)...)
-	}
-
-	return b[:needLen]
-}
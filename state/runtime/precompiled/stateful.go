@@ -0,0 +1,136 @@
+package precompiled
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/juanidrobo/polygon-edge/chain"
+	"github.com/juanidrobo/polygon-edge/state/runtime"
+	"github.com/juanidrobo/polygon-edge/types"
+)
+
+var (
+	errUnknownStatefulContract = errors.New("unknown stateful precompiled contract")
+	errAddressInUse            = errors.New("address already used by a precompiled contract")
+	errDelegatedCall           = errors.New("stateful precompiled contracts can't be delegated to")
+)
+
+// StatefulContract is a precompiled contract with access to the state, through the host
+type StatefulContract interface {
+	// Run executes the contract, charging its gas out of the supplied one.
+	// It returns the output and the gas left, the value has already been transferred to the contract
+	Run(
+		host runtime.Host,
+		config *chain.ForksInTime,
+		caller types.Address,
+		value *big.Int,
+		input []byte,
+		gas uint64,
+		readOnly bool,
+	) ([]byte, uint64, error)
+}
+
+// StatefulFactory creates a stateful contract at the address, with the config from the genesis
+type StatefulFactory func(addr types.Address, config json.RawMessage) (StatefulContract, error)
+
+var (
+	statefulFactoriesLock sync.RWMutex
+	statefulFactories     = map[string]StatefulFactory{}
+)
+
+// RegisterStatefulContract registers the factory of a stateful contract under the name
+// used by the genesis. It panics if the name is already registered
+func RegisterStatefulContract(name string, factory StatefulFactory) {
+	statefulFactoriesLock.Lock()
+	defer statefulFactoriesLock.Unlock()
+
+	if _, ok := statefulFactories[name]; ok {
+		panic(fmt.Sprintf("stateful precompiled contract %s already registered", name))
+	}
+
+	statefulFactories[name] = factory
+}
+
+func getStatefulFactory(name string) (StatefulFactory, bool) {
+	statefulFactoriesLock.RLock()
+	defer statefulFactoriesLock.RUnlock()
+
+	factory, ok := statefulFactories[name]
+
+	return factory, ok
+}
+
+// statefulContract is a stateful contract enabled from a block
+type statefulContract struct {
+	contract StatefulContract
+	block    chain.Fork
+}
+
+// SetupStatefulContracts creates the stateful contracts enabled by the chain params
+func (p *Precompiled) SetupStatefulContracts(configs []*chain.StatefulPrecompile) error {
+	for _, config := range configs {
+		factory, ok := getStatefulFactory(config.Name)
+		if !ok {
+			return fmt.Errorf("%w: %s", errUnknownStatefulContract, config.Name)
+		}
+
+		if _, ok := p.contracts[config.Address]; ok {
+			return fmt.Errorf("%w: %s", errAddressInUse, config.Address)
+		}
+
+		if _, ok := p.stateful[config.Address]; ok {
+			return fmt.Errorf("%w: %s", errAddressInUse, config.Address)
+		}
+
+		contract, err := factory(config.Address, config.Config)
+		if err != nil {
+			return fmt.Errorf("failed to create the stateful precompiled contract %s: %w", config.Name, err)
+		}
+
+		if p.stateful == nil {
+			p.stateful = map[types.Address]*statefulContract{}
+		}
+
+		p.stateful[config.Address] = &statefulContract{
+			contract: contract,
+			block:    config.Block,
+		}
+	}
+
+	return nil
+}
+
+// runStateful runs a stateful contract, only called through a regular or a static call
+func (p *Precompiled) runStateful(
+	s *statefulContract,
+	c *runtime.Contract,
+	host runtime.Host,
+	config *chain.ForksInTime,
+) *runtime.ExecutionResult {
+	// the caller of a delegated call is not the one of the contract, which could be impersonated
+	if c.Type == runtime.DelegateCall || c.Type == runtime.CallCode {
+		return &runtime.ExecutionResult{
+			GasLeft: 0,
+			Err:     errDelegatedCall,
+		}
+	}
+
+	returnValue, gasLeft, err := s.contract.Run(host, config, c.Caller, c.Value, c.Input, c.Gas, c.Static)
+
+	result := &runtime.ExecutionResult{
+		ReturnValue: returnValue,
+		GasLeft:     gasLeft,
+		Err:         err,
+	}
+
+	// a revert keeps the gas left and the revert reason
+	if result.Failed() && !errors.Is(err, runtime.ErrExecutionReverted) {
+		result.GasLeft = 0
+		result.ReturnValue = nil
+	}
+
+	return result
+}
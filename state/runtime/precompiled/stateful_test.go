@@ -0,0 +1,228 @@
+package precompiled
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/juanidrobo/polygon-edge/chain"
+	"github.com/juanidrobo/polygon-edge/state/runtime"
+	"github.com/juanidrobo/polygon-edge/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	counterAddr = types.StringToAddress("0x0300000000000000000000000000000000000000")
+	callerAddr  = types.StringToAddress("0x1000000000000000000000000000000000000001")
+	counterGas  = uint64(5000)
+	errReadOnly = errors.New("read only")
+)
+
+// counter is a stateful contract incrementing the slot 0 by its config step, returning the new value
+type counter struct {
+	addr types.Address
+	step int64
+}
+
+func (c *counter) Run(
+	host runtime.Host,
+	config *chain.ForksInTime,
+	_ types.Address,
+	_ *big.Int,
+	input []byte,
+	gas uint64,
+	readOnly bool,
+) ([]byte, uint64, error) {
+	if gas < counterGas {
+		return nil, 0, runtime.ErrOutOfGas
+	}
+
+	gas -= counterGas
+
+	if len(input) != 0 {
+		return []byte("reverted"), gas, runtime.ErrExecutionReverted
+	}
+
+	if readOnly {
+		return nil, gas, errReadOnly
+	}
+
+	value := new(big.Int).SetBytes(host.GetStorage(c.addr, types.Hash{}).Bytes())
+	value.Add(value, big.NewInt(c.step))
+
+	host.SetStorage(c.addr, types.Hash{}, types.BytesToHash(value.Bytes()), config)
+
+	return value.Bytes(), gas, nil
+}
+
+func init() {
+	RegisterStatefulContract("counter", func(addr types.Address, config json.RawMessage) (StatefulContract, error) {
+		var cfg struct {
+			Step int64 `json:"step"`
+		}
+
+		if err := json.Unmarshal(config, &cfg); err != nil {
+			return nil, err
+		}
+
+		return &counter{addr: addr, step: cfg.Step}, nil
+	})
+}
+
+// mockStatefulHost is a host with an in-memory storage, at the given block
+type mockStatefulHost struct {
+	runtime.Host
+
+	number  int64
+	storage map[types.Address]map[types.Hash]types.Hash
+}
+
+func newMockStatefulHost(number int64) *mockStatefulHost {
+	return &mockStatefulHost{
+		number:  number,
+		storage: map[types.Address]map[types.Hash]types.Hash{},
+	}
+}
+
+func (m *mockStatefulHost) GetTxContext() runtime.TxContext {
+	return runtime.TxContext{Number: m.number}
+}
+
+func (m *mockStatefulHost) GetStorage(addr types.Address, key types.Hash) types.Hash {
+	return m.storage[addr][key]
+}
+
+func (m *mockStatefulHost) SetStorage(
+	addr types.Address,
+	key types.Hash,
+	value types.Hash,
+	_ *chain.ForksInTime,
+) runtime.StorageStatus {
+	if _, ok := m.storage[addr]; !ok {
+		m.storage[addr] = map[types.Hash]types.Hash{}
+	}
+
+	m.storage[addr][key] = value
+
+	return runtime.StorageModified
+}
+
+func counterPrecompile(block uint64, config string) *chain.StatefulPrecompile {
+	return &chain.StatefulPrecompile{
+		Name:    "counter",
+		Address: counterAddr,
+		Block:   chain.Fork(block),
+		Config:  json.RawMessage(config),
+	}
+}
+
+func TestSetupStatefulContracts(t *testing.T) {
+	testCases := []struct {
+		name    string
+		configs []*chain.StatefulPrecompile
+		err     error
+	}{
+		{
+			"should register the contracts",
+			[]*chain.StatefulPrecompile{counterPrecompile(0, `{"step": 1}`)},
+			nil,
+		},
+		{
+			"should fail for an unknown contract",
+			[]*chain.StatefulPrecompile{{Name: "unknown", Address: counterAddr}},
+			errUnknownStatefulContract,
+		},
+		{
+			"should fail for the address of a builtin contract",
+			[]*chain.StatefulPrecompile{{Name: "counter", Address: types.StringToAddress("1"), Config: []byte(`{}`)}},
+			errAddressInUse,
+		},
+		{
+			"should fail for an address used twice",
+			[]*chain.StatefulPrecompile{counterPrecompile(0, `{}`), counterPrecompile(10, `{}`)},
+			errAddressInUse,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			p := NewPrecompiled()
+
+			err := p.SetupStatefulContracts(tc.configs)
+			if tc.err == nil {
+				assert.NoError(t, err)
+				assert.Len(t, p.stateful, len(tc.configs))
+			} else {
+				assert.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+
+	t.Run("should fail for an invalid config", func(t *testing.T) {
+		p := NewPrecompiled()
+
+		assert.Error(t, p.SetupStatefulContracts([]*chain.StatefulPrecompile{counterPrecompile(0, `{"step": "a"}`)}))
+	})
+}
+
+func TestStatefulContract(t *testing.T) {
+	p := NewPrecompiled()
+	assert.NoError(t, p.SetupStatefulContracts([]*chain.StatefulPrecompile{counterPrecompile(10, `{"step": 2}`)}))
+
+	config := &chain.ForksInTime{}
+
+	call := func(callType runtime.CallType, input []byte) *runtime.Contract {
+		c := runtime.NewContractCall(1, callerAddr, callerAddr, counterAddr, big.NewInt(0), 10000, nil, input)
+		c.Type = callType
+		c.Static = callType == runtime.StaticCall
+
+		return c
+	}
+
+	t.Run("should be enabled from its block", func(t *testing.T) {
+		assert.False(t, p.CanRun(call(runtime.Call, nil), newMockStatefulHost(9), config))
+		assert.True(t, p.CanRun(call(runtime.Call, nil), newMockStatefulHost(10), config))
+	})
+
+	t.Run("should read and write the state", func(t *testing.T) {
+		host := newMockStatefulHost(10)
+
+		for _, expected := range []int64{2, 4} {
+			result := p.Run(call(runtime.Call, nil), host, config)
+
+			assert.NoError(t, result.Err)
+			assert.Equal(t, big.NewInt(expected).Bytes(), result.ReturnValue)
+			assert.Equal(t, uint64(10000)-counterGas, result.GasLeft)
+		}
+
+		assert.Equal(t, types.BytesToHash([]byte{4}), host.GetStorage(counterAddr, types.Hash{}))
+	})
+
+	t.Run("should be read only in a static call", func(t *testing.T) {
+		result := p.Run(call(runtime.StaticCall, nil), newMockStatefulHost(10), config)
+
+		assert.ErrorIs(t, result.Err, errReadOnly)
+		assert.Equal(t, uint64(0), result.GasLeft)
+	})
+
+	t.Run("should keep the gas left and the output on revert", func(t *testing.T) {
+		result := p.Run(call(runtime.Call, []byte{0x1}), newMockStatefulHost(10), config)
+
+		assert.ErrorIs(t, result.Err, runtime.ErrExecutionReverted)
+		assert.Equal(t, []byte("reverted"), result.ReturnValue)
+		assert.Equal(t, uint64(10000)-counterGas, result.GasLeft)
+	})
+
+	t.Run("should not be delegated to", func(t *testing.T) {
+		for _, callType := range []runtime.CallType{runtime.DelegateCall, runtime.CallCode} {
+			host := newMockStatefulHost(10)
+			result := p.Run(call(callType, nil), host, config)
+
+			assert.ErrorIs(t, result.Err, errDelegatedCall)
+			assert.Empty(t, host.storage)
+		}
+	})
+}
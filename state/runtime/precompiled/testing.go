@@ -11,10 +11,11 @@ import (
 )
 
 type TestCase struct {
-	Name     string
-	Input    []byte
-	Expected []byte
-	Gas      uint64
+	Name          string
+	Input         []byte
+	Expected      []byte
+	ExpectedError string
+	Gas           uint64
 }
 
 // decodeHex is a helper function for decoding a hex string
@@ -38,10 +39,11 @@ func ReadTestCase(t *testing.T, path string, f func(t *testing.T, c *TestCase))
 	}
 
 	type testCase struct {
-		Name     string
-		Input    string
-		Expected string
-		Gas      uint64
+		Name          string
+		Input         string
+		Expected      string
+		ExpectedError string
+		Gas           uint64
 	}
 
 	var cases []*testCase
@@ -55,10 +57,11 @@ func ReadTestCase(t *testing.T, path string, f func(t *testing.T, c *TestCase))
 		expectedDecode := decodeHex(t, fmt.Sprintf("0x%s", i.Expected))
 
 		c := &TestCase{
-			Name:     i.Name,
-			Gas:      i.Gas,
-			Input:    inputDecode,
-			Expected: expectedDecode,
+			Name:          i.Name,
+			Gas:           i.Gas,
+			Input:         inputDecode,
+			Expected:      expectedDecode,
+			ExpectedError: i.ExpectedError,
 		}
 
 		t.Run(i.Name, func(t *testing.T) {
@@ -58,6 +58,8 @@ type Host interface {
 	AccountExists(addr types.Address) bool
 	GetStorage(addr types.Address, key types.Hash) types.Hash
 	SetStorage(addr types.Address, key types.Hash, value types.Hash, config *chain.ForksInTime) StorageStatus
+	GetTransientStorage(addr types.Address, key types.Hash) types.Hash
+	SetTransientStorage(addr types.Address, key types.Hash, value types.Hash)
 	GetBalance(addr types.Address) *big.Int
 	GetCodeSize(addr types.Address) int
 	GetCodeHash(addr types.Address) types.Hash
@@ -69,6 +71,7 @@ type Host interface {
 	Callx(*Contract, Host) *ExecutionResult
 	Empty(addr types.Address) bool
 	GetNonce(addr types.Address) uint64
+	Aborted() bool
 }
 
 // ExecutionResult includes all output after executing given evm
@@ -84,11 +87,28 @@ func (r *ExecutionResult) Succeeded() bool { return r.Err == nil }
 func (r *ExecutionResult) Failed() bool    { return r.Err != nil }
 func (r *ExecutionResult) Reverted() bool  { return errors.Is(r.Err, ErrExecutionReverted) }
 
-func (r *ExecutionResult) UpdateGasUsed(gasLimit uint64, refund uint64) {
+const (
+	// MaxInitCodeSize is the maximum size of the initcode, introduced by eip-3860
+	MaxInitCodeSize = 2 * 24576
+
+	// InitCodeWordGas is the gas paid for every word of the initcode, introduced by eip-3860
+	InitCodeWordGas uint64 = 2
+)
+
+const (
+	// RefundQuotient is the maximum refund quotient, the refund can go up to half the gas used
+	RefundQuotient uint64 = 2
+
+	// RefundQuotientEIP3529 is the maximum refund quotient after eip-3529,
+	// the refund can go up to a fifth of the gas used
+	RefundQuotientEIP3529 uint64 = 5
+)
+
+func (r *ExecutionResult) UpdateGasUsed(gasLimit uint64, refund uint64, refundQuotient uint64) {
 	r.GasUsed = gasLimit - r.GasLeft
 
-	// Refund can go up to half the gas used
-	if maxRefund := r.GasUsed / 2; refund > maxRefund {
+	// Refund can go up to a fraction of the gas used
+	if maxRefund := r.GasUsed / refundQuotient; refund > maxRefund {
 		refund = maxRefund
 	}
 
@@ -103,10 +123,13 @@ var (
 	ErrNotEnoughFunds           = errors.New("not enough funds")
 	ErrInsufficientBalance      = errors.New("insufficient balance for transfer")
 	ErrMaxCodeSizeExceeded      = errors.New("evm: max code size exceeded")
+	ErrMaxInitCodeSizeExceeded  = errors.New("evm: max initcode size exceeded")
+	ErrInvalidCodePrefix        = errors.New("evm: invalid code, it must not begin with 0xEF")
 	ErrContractAddressCollision = errors.New("contract address collision")
 	ErrDepth                    = errors.New("max call depth exceeded")
 	ErrExecutionReverted        = errors.New("execution was reverted")
 	ErrCodeStoreOutOfGas        = errors.New("contract creation code storage out of gas")
+	ErrExecutionAborted         = errors.New("execution aborted")
 )
 
 type CallType int
@@ -140,6 +163,9 @@ type Contract struct {
 	Input       []byte
 	Gas         uint64
 	Static      bool
+
+	// CodeHash is the hash of the code if it is stored in an account, zero otherwise
+	CodeHash types.Hash
 }
 
 func NewContract(
@@ -23,6 +23,21 @@ type Snapshot interface {
 	Commit(objs []*Object) (Snapshot, []byte)
 }
 
+// StorageReader reads the storage of an account by the hashed slots
+type StorageReader interface {
+	Get(k []byte) ([]byte, bool)
+}
+
+// StorageSnapshot is a snapshot able to read the storage of its accounts
+// without walking their storage tries
+type StorageSnapshot interface {
+	Snapshot
+
+	// Storage returns the reader of the storage with the given root
+	// of the account with the hashed address
+	Storage(addrHash types.Hash, root types.Hash) (StorageReader, error)
+}
+
 // account trie
 type accountTrie interface {
 	Get(k []byte) ([]byte, bool)
@@ -118,6 +133,9 @@ type StateObject struct {
 	Deleted   bool
 	DirtyCode bool
 	Txn       *iradix.Txn
+
+	// readerStorage is set if the storage not written by the Txn is read through its reader
+	readerStorage bool
 }
 
 func (s *StateObject) Empty() bool {
@@ -159,6 +177,7 @@ func (s *StateObject) Copy() *StateObject {
 	ss.Deleted = s.Deleted
 	ss.DirtyCode = s.DirtyCode
 	ss.Code = s.Code
+	ss.readerStorage = s.readerStorage
 
 	if s.Txn != nil {
 		ss.Txn = s.Txn.CommitOnly().Txn()
@@ -1,10 +1,15 @@
 package state
 
 import (
+	"context"
+	"math"
 	"math/big"
 	"testing"
+	"time"
 
+	"github.com/juanidrobo/polygon-edge/chain"
 	"github.com/juanidrobo/polygon-edge/state/runtime"
+	"github.com/juanidrobo/polygon-edge/state/runtime/evm"
 	"github.com/juanidrobo/polygon-edge/types"
 	"github.com/hashicorp/go-hclog"
 	"github.com/stretchr/testify/assert"
@@ -149,3 +154,104 @@ func TestTransfer(t *testing.T) {
 		})
 	}
 }
+
+func TestTransition_WithStateOverride(t *testing.T) {
+	t.Run("should override the account fields", func(t *testing.T) {
+		transition := newTestTransition(nil)
+		transition.state.SetState(addr1, hash1, hash1)
+		transition.state.SetState(addr2, hash1, hash1)
+
+		nonce := uint64(10)
+		code := []byte{0x1}
+
+		assert.NoError(t, transition.WithStateOverride(types.StateOverride{
+			addr1: {
+				Nonce:   &nonce,
+				Balance: big.NewInt(100),
+				Code:    code,
+				State:   map[types.Hash]types.Hash{hash2: hash2},
+			},
+			addr2: {
+				StateDiff: map[types.Hash]types.Hash{hash2: hash2},
+			},
+		}))
+
+		assert.Equal(t, nonce, transition.state.GetNonce(addr1))
+		assert.Equal(t, big.NewInt(100), transition.state.GetBalance(addr1))
+		assert.Equal(t, code, transition.state.GetCode(addr1))
+
+		// the whole storage is replaced
+		assert.Equal(t, types.Hash{}, transition.state.GetState(addr1, hash1))
+		assert.Equal(t, hash2, transition.state.GetState(addr1, hash2))
+
+		// only the given slots are replaced
+		assert.Equal(t, hash1, transition.state.GetState(addr2, hash1))
+		assert.Equal(t, hash2, transition.state.GetState(addr2, hash2))
+	})
+
+	t.Run("should fail if both state and stateDiff are overridden", func(t *testing.T) {
+		transition := newTestTransition(nil)
+
+		assert.ErrorIs(t, transition.WithStateOverride(types.StateOverride{
+			addr1: {
+				State:     map[types.Hash]types.Hash{},
+				StateDiff: map[types.Hash]types.Hash{},
+			},
+		}), ErrStateOverrideConflict)
+	})
+}
+
+func TestTransition_WithBlockOverride(t *testing.T) {
+	transition := newTestTransition(nil)
+	transition.ctx = runtime.TxContext{Number: 1, Timestamp: 1, GasLimit: 1}
+
+	number, gasLimit := uint64(100), uint64(5000)
+
+	transition.WithBlockOverride(&types.BlockOverride{
+		Number:     &number,
+		GasLimit:   &gasLimit,
+		Coinbase:   &addr1,
+		Difficulty: big.NewInt(2),
+	})
+
+	assert.Equal(t, runtime.TxContext{
+		Number:     100,
+		Timestamp:  1,
+		GasLimit:   5000,
+		Coinbase:   addr1,
+		Difficulty: types.BytesToHash([]byte{2}),
+	}, transition.ctx)
+	assert.Equal(t, gasLimit, transition.gasPool)
+}
+
+func TestTransition_WithContext(t *testing.T) {
+	executor := NewExecutor(&chain.Params{}, nil, hclog.NewNullLogger())
+	executor.SetRuntime(evm.NewEVM())
+
+	transition := newTestTransition(map[types.Address]*PreState{
+		addr1: {
+			Balance: 1000,
+		},
+	})
+	transition.r = executor
+	transition.gasPool = math.MaxUint64
+
+	// JUMPDEST, PUSH1 0x00, JUMP loops until the gas is exhausted
+	transition.state.SetCode(addr2, []byte{0x5b, 0x60, 0x00, 0x56})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	transition.WithContext(ctx)
+
+	_, err := transition.Apply(&types.Transaction{
+		From:     addr1,
+		To:       &addr2,
+		Gas:      math.MaxInt64,
+		GasPrice: big.NewInt(0),
+		Value:    big.NewInt(0),
+	})
+
+	assert.ErrorIs(t, err, runtime.ErrExecutionAborted)
+	assert.True(t, transition.Aborted())
+}
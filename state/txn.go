@@ -21,12 +21,23 @@ var (
 
 	// refundIndex is the index of the refund
 	refundIndex = types.BytesToHash([]byte{3}).Bytes()
+
+	// transientIndex is the prefix of the transient storage (eip-1153) slots
+	transientIndex = types.BytesToHash([]byte{4}).Bytes()
 )
 
+// stateReader reads the accounts and the storage slots missing from the Txn in place of its snapshot.
+// It is used by the parallel execution, to read the state written by the previous transactions of the block
+type stateReader interface {
+	getStateObject(addr types.Address) (*StateObject, bool)
+	getState(addr types.Address, key types.Hash) types.Hash
+}
+
 // Txn is a reference of the state
 type Txn struct {
 	snapshot  Snapshot
 	state     State
+	reader    stateReader
 	snapshots []*iradix.Tree
 	txn       *iradix.Txn
 	codeCache *lru.Cache
@@ -104,6 +115,10 @@ func (txn *Txn) getStateObject(addr types.Address) (*StateObject, bool) {
 		return obj.Copy(), true
 	}
 
+	if txn.reader != nil {
+		return txn.reader.getStateObject(addr)
+	}
+
 	data, ok := txn.snapshot.Get(txn.hashit(addr.Bytes()))
 	if !ok {
 		return nil, false
@@ -119,6 +134,11 @@ func (txn *Txn) getStateObject(addr types.Address) (*StateObject, bool) {
 	// Load trie from memory if there is some state
 	if account.Root == emptyStateHash {
 		account.Trie = txn.state.NewSnapshot()
+	} else if snap, ok := txn.snapshot.(StorageSnapshot); ok {
+		account.Trie, err = snap.Storage(types.BytesToHash(txn.hashit(addr.Bytes())), account.Root)
+		if err != nil {
+			return nil, false
+		}
 	} else {
 		account.Trie, err = txn.state.NewSnapshotAt(account.Root)
 		if err != nil {
@@ -266,11 +286,17 @@ func (txn *Txn) SetStorage(
 
 	legacyGasMetering := !config.Istanbul && (config.Petersburg || !config.Constantinople)
 
+	// the refund for clearing a slot is reduced by eip-3529
+	clearRefund := uint64(15000)
+	if config.EIP3529 {
+		clearRefund = 4800
+	}
+
 	if legacyGasMetering {
 		if oldValue == zeroHash {
 			return runtime.StorageAdded
 		} else if value == zeroHash {
-			txn.AddRefund(15000)
+			txn.AddRefund(clearRefund)
 
 			return runtime.StorageDeleted
 		}
@@ -284,7 +310,7 @@ func (txn *Txn) SetStorage(
 		}
 
 		if value == zeroHash { // delete slot (2.1.2b)
-			txn.AddRefund(15000)
+			txn.AddRefund(clearRefund)
 
 			return runtime.StorageDeleted
 		}
@@ -294,9 +320,9 @@ func (txn *Txn) SetStorage(
 
 	if original != zeroHash { // Storage slot was populated before this transaction started
 		if current == zeroHash { // recreate slot (2.2.1.1)
-			txn.SubRefund(15000)
+			txn.SubRefund(clearRefund)
 		} else if value == zeroHash { // delete slot (2.2.1.2)
-			txn.AddRefund(15000)
+			txn.AddRefund(clearRefund)
 		}
 	}
 
@@ -339,6 +365,22 @@ func (txn *Txn) SetState(
 	})
 }
 
+// SetFullStorage replaces the whole storage of the address with the given slots
+func (txn *Txn) SetFullStorage(addr types.Address, storage map[types.Hash]types.Hash) {
+	txn.upsertAccount(addr, true, func(object *StateObject) {
+		object.Account.Root = emptyStateHash
+		object.Account.Trie = txn.state.NewSnapshot()
+		object.Txn = iradix.New().Txn()
+		object.readerStorage = false
+
+		for key, value := range storage {
+			if value != zeroHash {
+				object.Txn.Insert(key.Bytes(), value.Bytes())
+			}
+		}
+	})
+}
+
 // GetState returns the state of the address at a given key
 func (txn *Txn) GetState(addr types.Address, key types.Hash) types.Hash {
 	object, exists := txn.getStateObject(addr)
@@ -359,6 +401,10 @@ func (txn *Txn) GetState(addr types.Address, key types.Hash) types.Hash {
 		}
 	}
 
+	if object.readerStorage {
+		return txn.reader.getState(addr, key)
+	}
+
 	// If the object was not found in the radix trie due to no state update, we fetch it from the trie tre
 	k := txn.hashit(key.Bytes())
 
@@ -411,16 +457,19 @@ func (txn *Txn) GetCode(addr types.Address) []byte {
 	if object.DirtyCode {
 		return object.Code
 	}
+	// the cache is keyed by the code hash, as the account may be destroyed and created again
+	codeHash := types.BytesToHash(object.Account.CodeHash)
+
 	// TODO; Should we move this to state?
-	v, ok := txn.codeCache.Get(addr)
+	v, ok := txn.codeCache.Get(codeHash)
 
 	if ok {
 		//nolint:forcetypeassert
 		return v.([]byte)
 	}
 
-	code, _ := txn.state.GetCode(types.BytesToHash(object.Account.CodeHash))
-	txn.codeCache.Add(addr, code)
+	code, _ := txn.state.GetCode(codeHash)
+	txn.codeCache.Add(codeHash, code)
 
 	return code
 }
@@ -464,6 +513,39 @@ func (txn *Txn) HasSuicided(addr types.Address) bool {
 	return exists && object.Suicide
 }
 
+// transientKey returns the key of the transient storage slot in the radix tree
+func transientKey(addr types.Address, key types.Hash) []byte {
+	k := make([]byte, 0, len(transientIndex)+types.AddressLength+types.HashLength)
+	k = append(k, transientIndex...)
+	k = append(k, addr.Bytes()...)
+
+	return append(k, key.Bytes()...)
+}
+
+// GetTransientState returns the value of the transient storage slot (eip-1153)
+func (txn *Txn) GetTransientState(addr types.Address, key types.Hash) types.Hash {
+	data, exists := txn.txn.Get(transientKey(addr, key))
+	if !exists {
+		return types.Hash{}
+	}
+
+	//nolint:forcetypeassert
+	return data.(types.Hash)
+}
+
+// SetTransientState sets the value of the transient storage slot (eip-1153).
+// The transient storage is discarded at the end of the transaction,
+// and it is reverted along with the snapshots as it is kept in the radix tree
+func (txn *Txn) SetTransientState(addr types.Address, key types.Hash, value types.Hash) {
+	if value == zeroHash {
+		txn.txn.Delete(transientKey(addr, key))
+
+		return
+	}
+
+	txn.txn.Insert(transientKey(addr, key), value)
+}
+
 // Refund
 func (txn *Txn) AddRefund(gas uint64) {
 	refund := txn.GetRefund() + gas
@@ -592,6 +674,9 @@ func (txn *Txn) CleanDeleteObjects(deleteEmptyObjects bool) {
 
 	// delete refunds
 	txn.txn.Delete(refundIndex)
+
+	// delete the transient storage
+	txn.txn.DeletePrefix(transientIndex)
 }
 
 func (txn *Txn) Commit(deleteEmptyObjects bool) (Snapshot, []byte) {
@@ -139,6 +139,29 @@ func TestSnapshotUpdateData(t *testing.T) {
 	assert.Equal(t, hash1, txn.GetState(addr1, hash1))
 }
 
+func TestTransientStorage(t *testing.T) {
+	txn := newTestTxn(defaultPreState)
+
+	txn.SetTransientState(addr1, hash1, hash1)
+	assert.Equal(t, hash1, txn.GetTransientState(addr1, hash1))
+
+	// the transient storage is not the persistent one
+	assert.Equal(t, types.Hash{}, txn.GetState(addr1, hash1))
+
+	ss := txn.Snapshot()
+	txn.SetTransientState(addr1, hash1, hash2)
+	txn.SetTransientState(addr1, hash2, hash2)
+	assert.Equal(t, hash2, txn.GetTransientState(addr1, hash1))
+
+	txn.RevertToSnapshot(ss)
+	assert.Equal(t, hash1, txn.GetTransientState(addr1, hash1))
+	assert.Equal(t, types.Hash{}, txn.GetTransientState(addr1, hash2))
+
+	// the transient storage is discarded at the end of the transaction
+	txn.CleanDeleteObjects(true)
+	assert.Equal(t, types.Hash{}, txn.GetTransientState(addr1, hash1))
+}
+
 func hashit(k []byte) []byte {
 	h := sha3.NewLegacyKeccak256()
 	h.Write(k)
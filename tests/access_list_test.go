@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/juanidrobo/polygon-edge/chain"
+	"github.com/juanidrobo/polygon-edge/types"
+)
+
+var (
+	accessedBalance  = types.StringToAddress("0x4000000000000000000000000000000000000004")
+	accessedCodeSize = types.StringToAddress("0x5000000000000000000000000000000000000005")
+)
+
+// accessingCode loads the slot 1, reads the balance and the code size of two accounts,
+// calls the identity precompile and stores 5 in the slot 2
+func accessingCode() []byte {
+	// PUSH1 1, SLOAD, POP
+	code := []byte{0x60, 0x01, 0x54, 0x50}
+
+	// PUSH20 address, BALANCE, POP
+	code = append(code, 0x73)
+	code = append(code, accessedBalance.Bytes()...)
+	code = append(code, 0x31, 0x50)
+
+	// PUSH20 address, EXTCODESIZE, POP
+	code = append(code, 0x73)
+	code = append(code, accessedCodeSize.Bytes()...)
+	code = append(code, 0x3b, 0x50)
+
+	// CALL of 0x04 without any value, input and output, POP
+	code = append(code, 0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x60, 0x04, 0x5a, 0xf1, 0x50)
+
+	// PUSH1 5, PUSH1 2, SSTORE, STOP
+	code = append(code, 0x60, 0x05, 0x60, 0x02, 0x55, 0x00)
+
+	return code
+}
+
+func TestAccessList_Call(t *testing.T) {
+	c := &eipStateCase{
+		forks: istanbulWith(nil),
+		pre: map[types.Address]*chain.GenesisAccount{
+			eipContract: {Balance: big.NewInt(0), Code: accessingCode()},
+		},
+		msg: callContract(100000),
+	}
+
+	// the sender and the precompile are left out, the recipient is only listed for its storage
+	expected := types.AccessList{
+		{
+			Address:     eipContract,
+			StorageKeys: []types.Hash{types.BytesToHash([]byte{1}), types.BytesToHash([]byte{2})},
+		},
+		{Address: accessedBalance, StorageKeys: []types.Hash{}},
+		{Address: accessedCodeSize, StorageKeys: []types.Hash{}},
+	}
+
+	transition, msg := c.begin(t)
+
+	list, result, err := transition.ApplyWithAccessList(msg, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, result.Err)
+	assert.Equal(t, expected, list)
+
+	t.Run("is stable", func(t *testing.T) {
+		transition, msg := c.begin(t)
+
+		again, againResult, err := transition.ApplyWithAccessList(msg, list)
+		assert.NoError(t, err)
+		assert.True(t, list.Equal(again))
+
+		// the list is not charged
+		assert.Equal(t, result.GasUsed, againResult.GasUsed)
+	})
+
+	t.Run("keeps the given list", func(t *testing.T) {
+		transition, msg := c.begin(t)
+
+		other := types.AccessTuple{
+			Address:     types.StringToAddress("0x6000000000000000000000000000000000000006"),
+			StorageKeys: []types.Hash{types.BytesToHash([]byte{9})},
+		}
+
+		extended, _, err := transition.ApplyWithAccessList(msg, types.AccessList{other})
+		assert.NoError(t, err)
+		assert.Equal(t, append(expected[:3:3], other), extended)
+	})
+}
+
+func TestAccessList_Create(t *testing.T) {
+	// the init code reads the balance of an account, and returns an empty contract
+	initCode := append(append([]byte{0x73}, accessedBalance.Bytes()...), 0x31, 0x00)
+
+	c := &eipStateCase{
+		forks: istanbulWith(nil),
+		msg:   &types.Transaction{Gas: 100000, Input: initCode},
+	}
+
+	transition, msg := c.begin(t)
+
+	list, result, err := transition.ApplyWithAccessList(msg, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, result.Err)
+
+	// the created contract is left out
+	assert.Equal(t, types.AccessList{{Address: accessedBalance, StorageKeys: []types.Hash{}}}, list)
+}
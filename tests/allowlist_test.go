@@ -0,0 +1,189 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/juanidrobo/polygon-edge/chain"
+	"github.com/juanidrobo/polygon-edge/crypto"
+	"github.com/juanidrobo/polygon-edge/state"
+	"github.com/juanidrobo/polygon-edge/state/runtime/allowlist"
+	"github.com/juanidrobo/polygon-edge/types"
+)
+
+var eipOther = types.StringToAddress("0x4000000000000000000000000000000000000004")
+
+// allowlistPrecompile enables the allowlist with the given name at genesis, with the initial roles
+func allowlistPrecompile(name string, addr types.Address, admins, enabled []types.Address) *chain.StatefulPrecompile {
+	config, _ := json.Marshal(&allowlist.Config{Admins: admins, Enabled: enabled})
+
+	return &chain.StatefulPrecompile{Name: name, Address: addr, Config: config}
+}
+
+// allowlistInput returns the input of a call to the allowlist method, with the address argument
+func allowlistInput(method string, addr types.Address) []byte {
+	input := crypto.Keccak256([]byte(fmt.Sprintf("%s(address)", method)))[:4]
+
+	return append(input, types.BytesToHash(addr.Bytes()).Bytes()...)
+}
+
+// assertNotAllowed asserts the transaction has been rejected by an allowlist
+func assertNotAllowed(t *testing.T, err error, expected error) {
+	t.Helper()
+
+	var applyErr *state.TransitionApplicationError
+	if assert.ErrorAs(t, err, &applyErr) {
+		assert.ErrorIs(t, applyErr.Err, expected)
+		assert.False(t, applyErr.IsRecoverable)
+	}
+}
+
+func TestTxAllowList(t *testing.T) {
+	testCases := []struct {
+		name    string
+		admins  []types.Address
+		enabled []types.Address
+		allowed bool
+	}{
+		{"rejects the senders without a role", []types.Address{eipOther}, nil, false},
+		{"accepts the enabled senders", nil, []types.Address{eipSender}, true},
+		{"accepts the admin senders", []types.Address{eipSender}, nil, true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			c := &eipStateCase{
+				forks: istanbulWith(nil),
+				precompiles: []*chain.StatefulPrecompile{
+					allowlistPrecompile(allowlist.TxAllowListName, allowlist.TxAllowListAddr, tc.admins, tc.enabled),
+				},
+				msg: &types.Transaction{To: &eipOther, Gas: 21000},
+			}
+
+			result, _, err := c.run(t)
+			if tc.allowed {
+				assert.NoError(t, err)
+				assert.NoError(t, result.Err)
+			} else {
+				assertNotAllowed(t, err, allowlist.ErrSenderNotAllowed)
+			}
+		})
+	}
+}
+
+func TestContractDeployerAllowList(t *testing.T) {
+	// CREATE(0, 0, 0), PUSH1 0, SSTORE: stores the address of an empty contract in the slot 0
+	factory := []byte{0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0xf0, 0x60, 0x00, 0x55}
+
+	deployer := func(enabled ...types.Address) []*chain.StatefulPrecompile {
+		return []*chain.StatefulPrecompile{
+			allowlistPrecompile(allowlist.DeployerAllowListName, allowlist.DeployerAllowListAddr, nil, enabled),
+		}
+	}
+
+	t.Run("rejects the contract creations of the senders without a role", func(t *testing.T) {
+		c := &eipStateCase{
+			forks:       istanbulWith(nil),
+			precompiles: deployer(eipOther),
+			msg:         &types.Transaction{Gas: 100000},
+		}
+
+		_, _, err := c.run(t)
+		assertNotAllowed(t, err, allowlist.ErrDeployerNotAllowed)
+
+		c.precompiles = deployer(eipSender)
+
+		result, _, err := c.run(t)
+		assert.NoError(t, err)
+		assert.NoError(t, result.Err)
+	})
+
+	t.Run("restricts the contracts created by the contracts to the allowed senders", func(t *testing.T) {
+		c := &eipStateCase{
+			forks: istanbulWith(nil),
+			pre: map[types.Address]*chain.GenesisAccount{
+				eipContract: {Balance: big.NewInt(0), Code: factory},
+			},
+			msg: callContract(1000000),
+		}
+
+		for _, enabled := range []bool{false, true} {
+			c.precompiles = deployer(eipOther)
+			if enabled {
+				c.precompiles = deployer(eipSender)
+			}
+
+			result, txn, err := c.run(t)
+			assert.NoError(t, err)
+			assert.NoError(t, result.Err)
+
+			created := txn.GetState(eipContract, types.Hash{}) != types.Hash{}
+			assert.Equal(t, enabled, created)
+		}
+	})
+}
+
+func TestAllowListContract(t *testing.T) {
+	roleOf := func(txn *state.Txn, addr types.Address) allowlist.Role {
+		return allowlist.Role(txn.GetState(allowlist.TxAllowListAddr, types.BytesToHash(addr.Bytes())).Bytes()[31])
+	}
+
+	newCase := func(input []byte, admins, enabled []types.Address) *eipStateCase {
+		return &eipStateCase{
+			forks: istanbulWith(nil),
+			precompiles: []*chain.StatefulPrecompile{
+				allowlistPrecompile(allowlist.TxAllowListName, allowlist.TxAllowListAddr, admins, enabled),
+			},
+			msg: &types.Transaction{To: &allowlist.TxAllowListAddr, Gas: 100000, Input: input},
+		}
+	}
+
+	t.Run("sets the initial roles once enabled", func(t *testing.T) {
+		c := newCase(allowlistInput("readAllowList", eipOther), []types.Address{eipSender}, []types.Address{eipOther})
+
+		result, txn, err := c.run(t)
+		assert.NoError(t, err)
+		assert.NoError(t, result.Err)
+
+		assert.Equal(t, types.BytesToHash([]byte{byte(allowlist.EnabledRole)}).Bytes(), result.ReturnValue)
+		assert.Equal(t, allowlist.AdminRole, roleOf(txn, eipSender))
+		assert.Equal(t, uint64(1), txn.GetNonce(allowlist.TxAllowListAddr))
+	})
+
+	testCases := []struct {
+		method string
+		role   allowlist.Role
+	}{
+		{"setAdmin", allowlist.AdminRole},
+		{"setEnabled", allowlist.EnabledRole},
+		{"setNone", allowlist.NoRole},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(fmt.Sprintf("lets the admins change the roles with %s", tc.method), func(t *testing.T) {
+			c := newCase(allowlistInput(tc.method, eipOther), []types.Address{eipSender}, []types.Address{eipOther})
+
+			result, txn, err := c.run(t)
+			assert.NoError(t, err)
+			assert.NoError(t, result.Err)
+			assert.Equal(t, tc.role, roleOf(txn, eipOther))
+		})
+	}
+
+	t.Run("doesn't let the enabled senders change the roles", func(t *testing.T) {
+		c := newCase(allowlistInput("setAdmin", eipSender), nil, []types.Address{eipSender})
+
+		result, txn, err := c.run(t)
+		assert.NoError(t, err)
+		assert.Error(t, result.Err)
+		assert.Equal(t, allowlist.EnabledRole, roleOf(txn, eipSender))
+	})
+}
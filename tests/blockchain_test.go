@@ -0,0 +1,543 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/juanidrobo/polygon-edge/blockchain"
+	"github.com/juanidrobo/polygon-edge/chain"
+	"github.com/juanidrobo/polygon-edge/consensus/dummy"
+	"github.com/juanidrobo/polygon-edge/helper/hex"
+	"github.com/juanidrobo/polygon-edge/state"
+	"github.com/juanidrobo/polygon-edge/state/runtime/evm"
+	"github.com/juanidrobo/polygon-edge/state/runtime/precompiled"
+	"github.com/juanidrobo/polygon-edge/types"
+)
+
+var (
+	blockchainTests       = "BlockchainTests"
+	legacyBlockchainTests = "LegacyTests/Constantinople/BlockchainTests"
+)
+
+type blockchainCase struct {
+	Info          *info                                   `json:"_info"`
+	Network       string                                  `json:"network"`
+	GenesisRLP    string                                  `json:"genesisRLP"`
+	Blocks        []*blockchainBlock                      `json:"blocks"`
+	LastBlockHash types.Hash                              `json:"lastblockhash"`
+	Pre           map[types.Address]*chain.GenesisAccount `json:"pre"`
+	PostState     map[types.Address]*chain.GenesisAccount `json:"postState"`
+	PostStateHash *types.Hash                             `json:"postStateHash"`
+}
+
+type blockchainBlock struct {
+	RLP string `json:"rlp"`
+
+	// BlockHeader is only set for the valid blocks
+	BlockHeader *struct {
+		Hash types.Hash `json:"hash"`
+	} `json:"blockHeader"`
+}
+
+var (
+	frontierBlockReward       = big.NewInt(5e18)
+	byzantiumBlockReward      = big.NewInt(3e18)
+	constantinopleBlockReward = big.NewInt(2e18)
+)
+
+const (
+	// maxExtraDataSize is the maximum size of the extra data of a header
+	maxExtraDataSize = 32
+
+	// minGasLimit and maxGasLimit are the bounds of the gas limit of a header
+	minGasLimit = 5000
+	maxGasLimit = math.MaxInt64
+
+	// gasLimitBoundDivisor bounds the change of the gas limit from the parent to a fraction of it
+	gasLimitBoundDivisor = 1024
+
+	// maxUncles is the maximum number of uncles of a block
+	maxUncles = 2
+
+	// maxUncleDepth is the number of the ancestors of a block the uncles can be children of
+	maxUncleDepth = 7
+)
+
+var (
+	errExtraDataTooLong   = errors.New("extra data too long")
+	errInvalidTimestamp   = errors.New("timestamp not after the parent one")
+	errInvalidBlockNumber = errors.New("block number not following the parent one")
+	errInvalidGasLimit    = errors.New("invalid gas limit")
+	errGasUsedAboveLimit  = errors.New("gas used above the gas limit")
+	errTooManyUncles      = errors.New("too many uncles")
+	errDuplicateUncle     = errors.New("duplicate uncle")
+	errUncleIsAncestor    = errors.New("uncle is an ancestor")
+	errDanglingUncle      = errors.New("uncle's parent is not an ancestor")
+)
+
+// headerGetter returns the headers written to the chain, by hash
+type headerGetter interface {
+	GetHeaderByHash(hash types.Hash) (*types.Header, bool)
+}
+
+// ethashVerifier verifies the headers and the uncles with the rules of ethash, apart from the proof
+// of work and the difficulty, and pays the block and uncle rewards of ethash expected by the tests
+type ethashVerifier struct {
+	*dummy.Dummy
+
+	forks *chain.Forks
+	chain headerGetter
+
+	// uncles are the uncles of the blocks being written, by hash
+	uncles map[types.Hash][]*types.Header
+}
+
+func (v *ethashVerifier) VerifyHeader(parent, header *types.Header) error {
+	if err := verifyEthashHeader(parent, header); err != nil {
+		return err
+	}
+
+	return v.verifyUncles(header)
+}
+
+// verifyEthashHeader verifies the header against its parent
+func verifyEthashHeader(parent, header *types.Header) error {
+	if len(header.ExtraData) > maxExtraDataSize {
+		return fmt.Errorf("%w: %d > %d", errExtraDataTooLong, len(header.ExtraData), maxExtraDataSize)
+	}
+
+	if header.Timestamp <= parent.Timestamp {
+		return errInvalidTimestamp
+	}
+
+	if header.Number != parent.Number+1 {
+		return errInvalidBlockNumber
+	}
+
+	if header.GasLimit > maxGasLimit {
+		return fmt.Errorf("%w: %d > %d", errInvalidGasLimit, header.GasLimit, uint64(maxGasLimit))
+	}
+
+	if header.GasUsed > header.GasLimit {
+		return fmt.Errorf("%w: %d > %d", errGasUsedAboveLimit, header.GasUsed, header.GasLimit)
+	}
+
+	diff := header.GasLimit - parent.GasLimit
+	if header.GasLimit < parent.GasLimit {
+		diff = parent.GasLimit - header.GasLimit
+	}
+
+	if limit := parent.GasLimit / gasLimitBoundDivisor; diff >= limit || header.GasLimit < minGasLimit {
+		return fmt.Errorf("%w: have %d, want %d +- %d", errInvalidGasLimit, header.GasLimit, parent.GasLimit, limit-1)
+	}
+
+	return nil
+}
+
+// verifyUncles verifies the uncles of the block are distinct children of its recent ancestors,
+// neither ancestors nor uncles of them themselves
+func (v *ethashVerifier) verifyUncles(header *types.Header) error {
+	uncles := v.uncles[header.Hash]
+	if len(uncles) == 0 {
+		return nil
+	}
+
+	if len(uncles) > maxUncles {
+		return fmt.Errorf("%w: %d > %d", errTooManyUncles, len(uncles), maxUncles)
+	}
+
+	ancestors := map[types.Hash]*types.Header{}
+	included := map[types.Hash]bool{}
+
+	for i, hash := 0, header.ParentHash; i < maxUncleDepth; i++ {
+		ancestor, ok := v.chain.GetHeaderByHash(hash)
+		if !ok {
+			break
+		}
+
+		ancestors[hash] = ancestor
+
+		for _, uncle := range v.uncles[hash] {
+			included[uncle.Hash] = true
+		}
+
+		hash = ancestor.ParentHash
+	}
+
+	ancestors[header.Hash] = header
+	included[header.Hash] = true
+
+	for _, uncle := range uncles {
+		if included[uncle.Hash] {
+			return errDuplicateUncle
+		}
+
+		included[uncle.Hash] = true
+
+		if ancestors[uncle.Hash] != nil {
+			return errUncleIsAncestor
+		}
+
+		parent := ancestors[uncle.ParentHash]
+		if parent == nil || uncle.ParentHash == header.ParentHash {
+			return errDanglingUncle
+		}
+
+		if err := verifyEthashHeader(parent, uncle); err != nil {
+			return fmt.Errorf("invalid uncle %s: %w", uncle.Hash, err)
+		}
+	}
+
+	return nil
+}
+
+func (v *ethashVerifier) PreStateCommit(header *types.Header, txn *state.Transition) error {
+	reward := frontierBlockReward
+	if v.forks.IsConstantinople(header.Number) {
+		reward = constantinopleBlockReward
+	} else if v.forks.IsByzantium(header.Number) {
+		reward = byzantiumBlockReward
+	}
+
+	minerReward := new(big.Int).Set(reward)
+
+	for _, uncle := range v.uncles[header.Hash] {
+		uncleReward := new(big.Int).SetUint64(uncle.Number + 8 - header.Number)
+		uncleReward.Mul(uncleReward, reward)
+		uncleReward.Div(uncleReward, big.NewInt(8))
+
+		txn.Txn().AddBalance(uncle.Miner, uncleReward)
+		minerReward.Add(minerReward, new(big.Int).Div(reward, big.NewInt(32)))
+	}
+
+	txn.Txn().AddBalance(header.Miner, minerReward)
+
+	return nil
+}
+
+func RunBlockchainTest(t *testing.T, file, name string, c *blockchainCase) {
+	t.Helper()
+
+	config, ok := Forks[c.Network]
+	if !ok {
+		t.Skipf("network %s not supported", c.Network)
+	}
+
+	genesisBlock := decodeBlock(t, c.GenesisRLP)
+	if genesisBlock == nil {
+		t.Fatalf("failed to decode the genesis of %s (%s)", name, file)
+	}
+
+	s, _, root := buildState(c.Pre)
+	params := &chain.Params{Forks: config, ChainID: 1}
+
+	head := genesisBlock.Header
+	genesis := &chain.Genesis{
+		Config:     params,
+		Nonce:      head.Nonce,
+		Timestamp:  head.Timestamp,
+		ExtraData:  head.ExtraData,
+		GasLimit:   head.GasLimit,
+		Difficulty: head.Difficulty,
+		Mixhash:    head.MixHash,
+		Coinbase:   head.Miner,
+		StateRoot:  root,
+		Number:     head.Number,
+		GasUsed:    head.GasUsed,
+		ParentHash: head.ParentHash,
+	}
+
+	if hash := genesis.Hash(); hash != head.Hash {
+		t.Fatalf("genesis mismatch (%s %s): expected %s but found %s", file, name, head.Hash, hash)
+	}
+
+	executor := state.NewExecutor(params, s, hclog.NewNullLogger())
+	executor.SetRuntime(precompiled.NewPrecompiled())
+	executor.SetRuntime(evm.NewEVM())
+
+	verifier := &ethashVerifier{
+		Dummy:  &dummy.Dummy{},
+		forks:  config,
+		uncles: map[types.Hash][]*types.Header{},
+	}
+
+	b, err := blockchain.NewBlockchain(
+		hclog.NewNullLogger(),
+		"",
+		&chain.Chain{Genesis: genesis, Params: params},
+		verifier,
+		executor,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verifier.chain = b
+	executor.GetHash = b.GetHashHelper
+
+	if err := b.ComputeGenesis(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, e := range c.Blocks {
+		block := decodeBlock(t, e.RLP)
+
+		if block != nil {
+			verifier.uncles[block.Hash()] = block.Uncles
+			err = b.WriteBlock(block)
+		}
+
+		if e.BlockHeader == nil {
+			// the block is invalid, it must be rejected either on decoding or on writing
+			if block != nil && err == nil {
+				t.Fatalf("invalid block %d accepted (%s %s)", i, file, name)
+			}
+
+			continue
+		}
+
+		if block == nil {
+			t.Fatalf("failed to decode the block %d (%s %s)", i, file, name)
+		}
+
+		if err != nil {
+			t.Fatalf("failed to write the block %d (%s %s): %v", i, file, name, err)
+		}
+	}
+
+	if hash := b.Header().Hash; hash != c.LastBlockHash {
+		t.Fatalf("last block mismatch (%s %s): expected %s but found %s", file, name, c.LastBlockHash, hash)
+	}
+
+	expected := c.PostStateHash
+	if expected == nil {
+		_, _, root := buildState(c.PostState)
+		expected = &root
+	}
+
+	if root := b.Header().StateRoot; root != *expected {
+		t.Fatalf("post state mismatch (%s %s): expected %s but found %s", file, name, *expected, root)
+	}
+}
+
+// decodeBlock decodes the RLP block, it returns nil for the malformed blocks
+func decodeBlock(t *testing.T, str string) *types.Block {
+	t.Helper()
+
+	data, err := hex.DecodeHex(str)
+	if err != nil {
+		return nil
+	}
+
+	block := &types.Block{}
+	if err := block.UnmarshalRLP(data); err != nil {
+		return nil
+	}
+
+	return block
+}
+
+func TestBlockchain(t *testing.T) {
+	long := []string{
+		"bcExploitTest",
+		"bcWalletTest",
+	}
+
+	// The proof of work and the difficulty are left to the consensus, so the blocks breaking them
+	// are not rejected, and the fork choice by total difficulty isn't exercised on its own
+	skip := []string{
+		"DifficultyIsZero",
+		"wrongDifficulty",
+		"wrongMixHash",
+		"wrongNonce",
+		"diffTooHigh",
+		"diffTooLow",
+		"bcTotalDifficultyTest",
+
+		// the irregular state change of the DAO fork is not supported
+		"DaoTransactions",
+	}
+
+	folders, err := listFolders(blockchainTests, legacyBlockchainTests)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, folder := range folders {
+		if strings.HasSuffix(folder, "GeneralStateTests") {
+			// the state tests filled as blockchain tests are already run by TestState
+			continue
+		}
+
+		t.Run(folder, func(t *testing.T) {
+			files, err := listFiles(folder)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, file := range files {
+				if !strings.HasSuffix(file, ".json") {
+					continue
+				}
+
+				if contains(long, file) && testing.Short() {
+					continue
+				}
+
+				if contains(skip, file) {
+					continue
+				}
+
+				data, err := ioutil.ReadFile(file)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				var c map[string]*blockchainCase
+				if err := json.Unmarshal(data, &c); err != nil {
+					t.Fatal(err)
+				}
+
+				for name, i := range c {
+					i := i
+
+					t.Run(name, func(t *testing.T) {
+						RunBlockchainTest(t, file, name, i)
+					})
+				}
+			}
+		})
+	}
+}
+
+// headerMap is a chain of headers by hash
+type headerMap map[types.Hash]*types.Header
+
+func (m headerMap) GetHeaderByHash(hash types.Hash) (*types.Header, bool) {
+	header, ok := m[hash]
+
+	return header, ok
+}
+
+func TestEthashVerifier(t *testing.T) {
+	newHeader := func(parent *types.Header, miner byte) *types.Header {
+		header := &types.Header{
+			ParentHash: parent.Hash,
+			Number:     parent.Number + 1,
+			Timestamp:  parent.Timestamp + 10,
+			GasLimit:   parent.GasLimit,
+			Miner:      types.BytesToAddress([]byte{miner}),
+		}
+
+		header.ComputeHash()
+
+		return header
+	}
+
+	genesis := &types.Header{GasLimit: 1_024_000}
+	genesis.ComputeHash()
+
+	// the canonical chain, and the side blocks which can be included as uncles
+	chain := []*types.Header{genesis}
+	for i := 0; i < 9; i++ {
+		chain = append(chain, newHeader(chain[i], 0))
+	}
+
+	side := make([]*types.Header, len(chain))
+	for i := 1; i < len(chain); i++ {
+		side[i] = newHeader(chain[i-1], 1)
+	}
+
+	headers := headerMap{}
+	for _, header := range chain {
+		headers[header.Hash] = header
+	}
+
+	t.Run("header", func(t *testing.T) {
+		parent := chain[0]
+
+		cases := []struct {
+			name   string
+			modify func(h *types.Header)
+			err    error
+		}{
+			{"valid", func(h *types.Header) {}, nil},
+			{"extra data too long", func(h *types.Header) { h.ExtraData = make([]byte, 33) }, errExtraDataTooLong},
+			{"same timestamp as the parent", func(h *types.Header) { h.Timestamp = 0 }, errInvalidTimestamp},
+			{"wrong number", func(h *types.Header) { h.Number = 2 }, errInvalidBlockNumber},
+			{"gas used above the limit", func(h *types.Header) { h.GasUsed = h.GasLimit + 1 }, errGasUsedAboveLimit},
+			{"gas limit within the bound", func(h *types.Header) { h.GasLimit += 999 }, nil},
+			{"gas limit on the upper bound", func(h *types.Header) { h.GasLimit += 1000 }, errInvalidGasLimit},
+			{"gas limit on the lower bound", func(h *types.Header) { h.GasLimit -= 1000 }, errInvalidGasLimit},
+			{"gas limit above 2^63-1", func(h *types.Header) { h.GasLimit = math.MaxInt64 + 1 }, errInvalidGasLimit},
+		}
+
+		for _, c := range cases {
+			c := c
+
+			t.Run(c.name, func(t *testing.T) {
+				header := newHeader(parent, 0)
+				c.modify(header)
+
+				assert.ErrorIs(t, verifyEthashHeader(parent, header), c.err)
+			})
+		}
+
+		t.Run("gas limit below the minimum", func(t *testing.T) {
+			parent := &types.Header{GasLimit: minGasLimit}
+
+			header := newHeader(parent, 0)
+			header.GasLimit--
+
+			assert.ErrorIs(t, verifyEthashHeader(parent, header), errInvalidGasLimit)
+		})
+	})
+
+	t.Run("uncles", func(t *testing.T) {
+		block := newHeader(chain[9], 0)
+
+		invalidUncle := newHeader(chain[7], 2)
+		invalidUncle.Timestamp = chain[7].Timestamp
+		invalidUncle.ComputeHash()
+
+		cases := []struct {
+			name   string
+			uncles []*types.Header
+			err    error
+		}{
+			{"valid", []*types.Header{side[9], side[4]}, nil},
+			{"too many", []*types.Header{side[9], side[8], side[7]}, errTooManyUncles},
+			{"duplicate", []*types.Header{side[8], side[8]}, errDuplicateUncle},
+			{"already included by an ancestor", []*types.Header{side[7]}, errDuplicateUncle},
+			{"ancestor", []*types.Header{chain[8]}, errUncleIsAncestor},
+			{"too deep", []*types.Header{side[3]}, errDanglingUncle},
+			{"sibling", []*types.Header{newHeader(chain[9], 2)}, errDanglingUncle},
+			{"invalid header", []*types.Header{invalidUncle}, errInvalidTimestamp},
+		}
+
+		for _, c := range cases {
+			c := c
+
+			t.Run(c.name, func(t *testing.T) {
+				verifier := &ethashVerifier{
+					chain: headers,
+					uncles: map[types.Hash][]*types.Header{
+						chain[8].Hash: {side[7]},
+						block.Hash:    c.uncles,
+					},
+				}
+
+				assert.ErrorIs(t, verifier.verifyUncles(block), c.err)
+			})
+		}
+	})
+}
@@ -0,0 +1,488 @@
+package tests
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/juanidrobo/polygon-edge/chain"
+	"github.com/juanidrobo/polygon-edge/crypto"
+	"github.com/juanidrobo/polygon-edge/helper/hex"
+	"github.com/juanidrobo/polygon-edge/state"
+	"github.com/juanidrobo/polygon-edge/state/runtime"
+	"github.com/juanidrobo/polygon-edge/state/runtime/allowlist"
+	"github.com/juanidrobo/polygon-edge/state/runtime/evm"
+	"github.com/juanidrobo/polygon-edge/state/runtime/precompiled"
+	"github.com/juanidrobo/polygon-edge/types"
+)
+
+var (
+	eipSender   = types.StringToAddress("0x1000000000000000000000000000000000000001")
+	eipContract = types.StringToAddress("0x2000000000000000000000000000000000000002")
+	eipCoinbase = types.StringToAddress("0x3000000000000000000000000000000000000003")
+)
+
+// istanbulWith returns the Istanbul forks, with the given EIPs enabled on top of them
+func istanbulWith(enable func(f *chain.Forks)) *chain.Forks {
+	forks := *Forks["Istanbul"]
+
+	if enable != nil {
+		enable(&forks)
+	}
+
+	return &forks
+}
+
+// eipStateCase is a state test of an EIP, applying a transaction
+// from a funded sender on the given pre state
+type eipStateCase struct {
+	forks       *chain.Forks
+	precompiles []*chain.StatefulPrecompile
+	pre         map[types.Address]*chain.GenesisAccount
+	msg         *types.Transaction
+}
+
+// run applies the transaction, returning its result and the post state
+func (c *eipStateCase) run(t *testing.T) (*runtime.ExecutionResult, *state.Txn, error) {
+	t.Helper()
+
+	transition, msg := c.begin(t)
+
+	result, err := transition.Apply(msg)
+
+	return result, transition.Txn(), err
+}
+
+// begin begins the transition on the pre state, returning it along with the transaction to apply
+func (c *eipStateCase) begin(t *testing.T) (*state.Transition, *types.Transaction) {
+	t.Helper()
+
+	pre := map[types.Address]*chain.GenesisAccount{
+		eipSender: {Balance: big.NewInt(1e18)},
+	}
+
+	for addr, account := range c.pre {
+		pre[addr] = account
+	}
+
+	s, _, root := buildState(pre)
+
+	params := &chain.Params{Forks: c.forks, ChainID: 1, StatefulPrecompiles: c.precompiles}
+
+	precompiledRuntime := precompiled.NewPrecompiled()
+	if err := precompiledRuntime.SetupStatefulContracts(c.precompiles); err != nil {
+		t.Fatal(err)
+	}
+
+	allowlists, err := allowlist.NewPolicy(c.precompiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	executor := state.NewExecutor(params, s, hclog.NewNullLogger())
+	executor.SetRuntime(precompiledRuntime)
+	executor.SetAllowlists(allowlists)
+	executor.SetRuntime(evm.NewEVM())
+	executor.GetHash = func(*types.Header) func(i uint64) types.Hash {
+		return vmTestBlockHash
+	}
+
+	transition, err := executor.BeginTxn(root, &types.Header{Number: 1, GasLimit: 10000000}, eipCoinbase)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := c.msg.Copy()
+	msg.From = eipSender
+	msg.GasPrice = big.NewInt(1)
+
+	if msg.Value == nil {
+		msg.Value = big.NewInt(0)
+	}
+
+	return transition, msg
+}
+
+func callContract(gas uint64) *types.Transaction {
+	return &types.Transaction{To: &eipContract, Gas: gas}
+}
+
+func TestEIP3855_Push0(t *testing.T) {
+	// PUSH1 0x2a, PUSH0, SSTORE: stores 0x2a in the slot 0
+	code := []byte{0x60, 0x2a, 0x5f, 0x55}
+
+	c := &eipStateCase{
+		pre: map[types.Address]*chain.GenesisAccount{
+			eipContract: {Balance: big.NewInt(0), Code: code},
+		},
+		msg: callContract(100000),
+	}
+
+	t.Run("pushes zero once enabled", func(t *testing.T) {
+		c.forks = istanbulWith(func(f *chain.Forks) { f.EIP3855 = chain.NewFork(0) })
+
+		result, txn, err := c.run(t)
+		assert.NoError(t, err)
+		assert.NoError(t, result.Err)
+
+		// intrinsic gas + PUSH1 + PUSH0 + SSTORE of a new slot
+		assert.Equal(t, uint64(21000+3+2+20000), result.GasUsed)
+		assert.Equal(t, types.BytesToHash([]byte{0x2a}), txn.GetState(eipContract, types.Hash{}))
+	})
+
+	t.Run("is an invalid opcode before the fork", func(t *testing.T) {
+		c.forks = istanbulWith(nil)
+
+		result, txn, err := c.run(t)
+		assert.NoError(t, err)
+		assert.Error(t, result.Err)
+		assert.Equal(t, uint64(100000), result.GasUsed)
+		assert.Equal(t, types.Hash{}, txn.GetState(eipContract, types.Hash{}))
+	})
+}
+
+func TestEIP3529_ReducedRefunds(t *testing.T) {
+	testCases := []struct {
+		name string
+		code []byte
+		// the gas used with the refunds before and after the fork
+		legacyGasUsed uint64
+		gasUsed       uint64
+	}{
+		{
+			// PUSH1 0, PUSH1 0, SSTORE: clears the slot 0
+			name: "clearing a slot",
+			code: []byte{0x60, 0x00, 0x60, 0x00, 0x55},
+			// 21000 + 3 + 3 + 5000 gas used before the refund
+			legacyGasUsed: 26006 - 26006/2, // 15000 refund, capped at half the gas used
+			gasUsed:       26006 - 4800,    // 4800 refund, below a fifth of the gas used
+		},
+		{
+			// CALLER, SELFDESTRUCT
+			name: "self destructing",
+			code: []byte{0x33, 0xff},
+			// 21000 + 2 + 5000 gas used before the refund
+			legacyGasUsed: 26002 - 26002/2, // 24000 refund, capped at half the gas used
+			gasUsed:       26002,           // no refund
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			c := &eipStateCase{
+				pre: map[types.Address]*chain.GenesisAccount{
+					eipContract: {
+						Balance: big.NewInt(0),
+						Code:    tc.code,
+						Storage: map[types.Hash]types.Hash{
+							{}: types.BytesToHash([]byte{0x1}),
+						},
+					},
+				},
+				msg: callContract(100000),
+			}
+
+			c.forks = istanbulWith(nil)
+
+			result, _, err := c.run(t)
+			assert.NoError(t, err)
+			assert.NoError(t, result.Err)
+			assert.Equal(t, tc.legacyGasUsed, result.GasUsed)
+
+			c.forks = istanbulWith(func(f *chain.Forks) { f.EIP3529 = chain.NewFork(0) })
+
+			result, _, err = c.run(t)
+			assert.NoError(t, err)
+			assert.NoError(t, result.Err)
+			assert.Equal(t, tc.gasUsed, result.GasUsed)
+		})
+	}
+}
+
+func TestEIP3541_RejectEFCode(t *testing.T) {
+	// returns the code 0xEF: PUSH1 0xef, PUSH1 0, MSTORE8, PUSH1 1, PUSH1 0, RETURN
+	initCode := []byte{0x60, 0xef, 0x60, 0x00, 0x53, 0x60, 0x01, 0x60, 0x00, 0xf3}
+	created := crypto.CreateAddress(eipSender, 0)
+
+	c := &eipStateCase{
+		msg: &types.Transaction{Gas: 100000, Input: initCode},
+	}
+
+	t.Run("deploys the code before the fork", func(t *testing.T) {
+		c.forks = istanbulWith(nil)
+
+		result, txn, err := c.run(t)
+		assert.NoError(t, err)
+		assert.NoError(t, result.Err)
+		assert.Equal(t, []byte{0xef}, txn.GetCode(created))
+	})
+
+	t.Run("rejects the code once enabled", func(t *testing.T) {
+		c.forks = istanbulWith(func(f *chain.Forks) { f.EIP3541 = chain.NewFork(0) })
+
+		result, txn, err := c.run(t)
+		assert.NoError(t, err)
+		assert.ErrorIs(t, result.Err, runtime.ErrInvalidCodePrefix)
+		assert.Equal(t, uint64(100000), result.GasUsed)
+		assert.Empty(t, txn.GetCode(created))
+	})
+}
+
+func TestEIP3860_InitCodeLimit(t *testing.T) {
+	enabled := istanbulWith(func(f *chain.Forks) { f.EIP3860 = chain.NewFork(0) })
+
+	t.Run("meters the initcode words", func(t *testing.T) {
+		// 64 STOP bytes, 2 words
+		c := &eipStateCase{
+			msg: &types.Transaction{Gas: 100000, Input: make([]byte, 64)},
+		}
+
+		c.forks = istanbulWith(nil)
+
+		result, _, err := c.run(t)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(53000+64*4), result.GasUsed)
+
+		c.forks = enabled
+
+		result, _, err = c.run(t)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(53000+64*4+2*runtime.InitCodeWordGas), result.GasUsed)
+	})
+
+	t.Run("rejects the transactions exceeding the limit", func(t *testing.T) {
+		c := &eipStateCase{
+			forks: enabled,
+			msg:   &types.Transaction{Gas: 1000000, Input: make([]byte, runtime.MaxInitCodeSize+1)},
+		}
+
+		_, _, err := c.run(t)
+
+		var applyErr *state.TransitionApplicationError
+		if assert.ErrorAs(t, err, &applyErr) {
+			assert.ErrorIs(t, applyErr.Err, runtime.ErrMaxInitCodeSizeExceeded)
+			assert.False(t, applyErr.IsRecoverable)
+		}
+
+		c.msg.Input = make([]byte, runtime.MaxInitCodeSize)
+
+		result, _, err := c.run(t)
+		assert.NoError(t, err)
+		assert.NoError(t, result.Err)
+	})
+
+	t.Run("halts CREATE exceeding the limit", func(t *testing.T) {
+		// PUSH2 size, PUSH1 0, PUSH1 0, CREATE, PUSH1 0, SSTORE: stores the created address in the slot 0
+		size := big.NewInt(runtime.MaxInitCodeSize + 1).Bytes()
+		code := bytes.Join([][]byte{
+			{0x61}, size,
+			{0x60, 0x00, 0x60, 0x00, 0xf0, 0x60, 0x00, 0x55},
+		}, nil)
+
+		c := &eipStateCase{
+			pre: map[types.Address]*chain.GenesisAccount{
+				eipContract: {Balance: big.NewInt(0), Code: code},
+			},
+			msg: callContract(1000000),
+		}
+
+		c.forks = istanbulWith(nil)
+
+		result, txn, err := c.run(t)
+		assert.NoError(t, err)
+		assert.NoError(t, result.Err)
+		assert.Equal(
+			t,
+			types.BytesToHash(crypto.CreateAddress(eipContract, 0).Bytes()),
+			txn.GetState(eipContract, types.Hash{}),
+		)
+
+		c.forks = enabled
+
+		result, txn, err = c.run(t)
+		assert.NoError(t, err)
+		assert.ErrorIs(t, result.Err, runtime.ErrMaxInitCodeSizeExceeded)
+		assert.Equal(t, uint64(1000000), result.GasUsed)
+		assert.Equal(t, types.Hash{}, txn.GetState(eipContract, types.Hash{}))
+	})
+}
+
+func TestEIP1153_TransientStorage(t *testing.T) {
+	enabled := istanbulWith(func(f *chain.Forks) { f.EIP1153 = chain.NewFork(0) })
+
+	t.Run("stores and loads within the transaction", func(t *testing.T) {
+		// PUSH1 0x2a, PUSH1 1, TSTORE, PUSH1 1, TLOAD, PUSH1 0, SSTORE: copies the transient slot 1 to the slot 0
+		code := []byte{0x60, 0x2a, 0x60, 0x01, 0x5d, 0x60, 0x01, 0x5c, 0x60, 0x00, 0x55}
+
+		c := &eipStateCase{
+			forks: enabled,
+			pre: map[types.Address]*chain.GenesisAccount{
+				eipContract: {Balance: big.NewInt(0), Code: code},
+			},
+			msg: callContract(100000),
+		}
+
+		result, txn, err := c.run(t)
+		assert.NoError(t, err)
+		assert.NoError(t, result.Err)
+
+		// intrinsic gas + 4 PUSH1 + TSTORE + TLOAD + SSTORE of a new slot
+		assert.Equal(t, uint64(21000+4*3+100+100+20000), result.GasUsed)
+		assert.Equal(t, types.BytesToHash([]byte{0x2a}), txn.GetState(eipContract, types.Hash{}))
+
+		// the transient storage is discarded at the end of the transaction
+		txn.CleanDeleteObjects(true)
+		assert.Equal(t, types.Hash{}, txn.GetTransientState(eipContract, types.BytesToHash([]byte{0x1})))
+
+		c.forks = istanbulWith(nil)
+
+		result, _, err = c.run(t)
+		assert.NoError(t, err)
+		assert.Error(t, result.Err)
+	})
+
+	// the contract calls itself with some calldata, the inner call stores 0x2a in the transient slot 1
+	// and halts with the given instructions, then the outer call copies the transient slot 1 to the slot 0
+	selfCall := func(halt ...byte) []byte {
+		return append([]byte{
+			0x36, 0x60, 0x19, 0x57, // CALLDATASIZE, PUSH1 25, JUMPI
+			0x60, 0x00, 0x60, 0x00, 0x60, 0x01, 0x60, 0x00, 0x60, 0x00, 0x30, 0x5a, 0xf1, 0x50, // CALL(gas, self, 0, 0, 1, 0, 0)
+			0x60, 0x01, 0x5c, 0x60, 0x00, 0x55, 0x00, // PUSH1 1, TLOAD, PUSH1 0, SSTORE, STOP
+			0x5b, 0x60, 0x2a, 0x60, 0x01, 0x5d, // JUMPDEST, PUSH1 0x2a, PUSH1 1, TSTORE
+		}, halt...)
+	}
+
+	testCases := []struct {
+		name     string
+		code     []byte
+		expected types.Hash
+	}{
+		{
+			"is shared by the calls of the same contract",
+			selfCall(0x00), // STOP
+			types.BytesToHash([]byte{0x2a}),
+		},
+		{
+			"is reverted along with the call",
+			selfCall(0x60, 0x00, 0x60, 0x00, 0xfd), // PUSH1 0, PUSH1 0, REVERT
+			types.Hash{},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			c := &eipStateCase{
+				forks: enabled,
+				pre: map[types.Address]*chain.GenesisAccount{
+					eipContract: {Balance: big.NewInt(0), Code: tc.code},
+				},
+				msg: callContract(200000),
+			}
+
+			result, txn, err := c.run(t)
+			assert.NoError(t, err)
+			assert.NoError(t, result.Err)
+			assert.Equal(t, tc.expected, txn.GetState(eipContract, types.Hash{}))
+		})
+	}
+}
+
+func TestEIP5656_MCopy(t *testing.T) {
+	// PUSH1 0x2a, PUSH1 0, MSTORE, PUSH1 32, PUSH1 0, PUSH1 32, MCOPY, PUSH1 32, MLOAD, PUSH1 0, SSTORE:
+	// copies the first memory word to the second one, and stores it in the slot 0
+	code := []byte{
+		0x60, 0x2a, 0x60, 0x00, 0x52,
+		0x60, 0x20, 0x60, 0x00, 0x60, 0x20, 0x5e,
+		0x60, 0x20, 0x51, 0x60, 0x00, 0x55,
+	}
+
+	c := &eipStateCase{
+		pre: map[types.Address]*chain.GenesisAccount{
+			eipContract: {Balance: big.NewInt(0), Code: code},
+		},
+		msg: callContract(100000),
+	}
+
+	t.Run("copies the memory once enabled", func(t *testing.T) {
+		c.forks = istanbulWith(func(f *chain.Forks) { f.EIP5656 = chain.NewFork(0) })
+
+		result, txn, err := c.run(t)
+		assert.NoError(t, err)
+		assert.NoError(t, result.Err)
+
+		// MSTORE: 3 + 3 of memory, MCOPY: 3 + 3 per word + 3 of memory, SSTORE of a new slot
+		assert.Equal(t, uint64(21000+7*3+(3+3)+(3+3+3)+3+20000), result.GasUsed)
+		assert.Equal(t, types.BytesToHash([]byte{0x2a}), txn.GetState(eipContract, types.Hash{}))
+	})
+
+	t.Run("is an invalid opcode before the fork", func(t *testing.T) {
+		c.forks = istanbulWith(nil)
+
+		result, _, err := c.run(t)
+		assert.NoError(t, err)
+		assert.Error(t, result.Err)
+		assert.Equal(t, uint64(100000), result.GasUsed)
+	})
+}
+
+func TestEIP2537_BLS12381Precompiles(t *testing.T) {
+	// G1ADD of two points at infinity
+	g1Add := types.StringToAddress("0x0b")
+
+	c := &eipStateCase{
+		msg: &types.Transaction{To: &g1Add, Gas: 100000, Input: make([]byte, 256)},
+	}
+
+	c.forks = istanbulWith(nil)
+
+	before, _, err := c.run(t)
+	assert.NoError(t, err)
+	assert.NoError(t, before.Err)
+	assert.Empty(t, before.ReturnValue)
+
+	c.forks = istanbulWith(func(f *chain.Forks) { f.EIP2537 = chain.NewFork(0) })
+
+	after, _, err := c.run(t)
+	assert.NoError(t, err)
+	assert.NoError(t, after.Err)
+	assert.Equal(t, make([]byte, 128), after.ReturnValue)
+	assert.Equal(t, before.GasUsed+375, after.GasUsed)
+}
+
+func TestRIP7212_P256Verify(t *testing.T) {
+	p256Verify := types.StringToAddress("0x0100")
+
+	// a valid signature of the NIST verification vectors
+	input := hex.MustDecodeHex("0x" +
+		"d1b8ef21eb4182ee270638061063a3f3c16c114e33937f69fb232cc833965a94" +
+		"bf96b99aa49c705c910be33142017c642ff540c76349b9dab72f981fd9347f4f" +
+		"17c55095819089c2e03b9cd415abdf12444e323075d98f31920b9e0f57ec871c" +
+		"e424dc61d4bb3cb7ef4344a7f8957a0c5134e16f7a67c074f82e6e12f49abf3c" +
+		"970eed7aa2bc48651545949de1dddaf0127e5965ac85d1243d6f60e7dfaee927")
+
+	c := &eipStateCase{
+		msg: &types.Transaction{To: &p256Verify, Gas: 100000, Input: input},
+	}
+
+	c.forks = istanbulWith(nil)
+
+	before, _, err := c.run(t)
+	assert.NoError(t, err)
+	assert.NoError(t, before.Err)
+	assert.Empty(t, before.ReturnValue)
+
+	c.forks = istanbulWith(func(f *chain.Forks) { f.RIP7212 = chain.NewFork(0) })
+
+	after, _, err := c.run(t)
+	assert.NoError(t, err)
+	assert.NoError(t, after.Err)
+	assert.Equal(t, types.BytesToHash([]byte{1}).Bytes(), after.ReturnValue)
+	assert.Equal(t, before.GasUsed+3450, after.GasUsed)
+}
@@ -0,0 +1,379 @@
+package tests
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/juanidrobo/polygon-edge/chain"
+	"github.com/juanidrobo/polygon-edge/crypto"
+	"github.com/juanidrobo/polygon-edge/state"
+	"github.com/juanidrobo/polygon-edge/state/runtime/evm"
+	"github.com/juanidrobo/polygon-edge/state/runtime/precompiled"
+	"github.com/juanidrobo/polygon-edge/types"
+)
+
+const parallelBlockGasLimit = 30000000
+
+var (
+	parallelCoinbase = types.StringToAddress("0x5000000000000000000000000000000000000005")
+
+	// counterCode increments the slot 0, all its callers conflict
+	counterCode = []byte{0x60, 0x00, 0x54, 0x60, 0x01, 0x01, 0x60, 0x00, 0x55}
+
+	// callerCounterCode increments the slot of the caller, and emits a log
+	callerCounterCode = []byte{0x33, 0x54, 0x60, 0x01, 0x01, 0x33, 0x55, 0x60, 0x00, 0x60, 0x00, 0xa0}
+
+	// coinbaseReaderCode stores the balance of the coinbase in the slot 0
+	coinbaseReaderCode = []byte{0x41, 0x31, 0x60, 0x00, 0x55}
+
+	// coinbaseForwarderCode forwards the value received to the coinbase
+	coinbaseForwarderCode = []byte{
+		0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x34, 0x41, 0x5a, 0xf1, 0x50,
+	}
+
+	// revertingCode writes the slot 0, and reverts
+	revertingCode = []byte{0x60, 0x01, 0x60, 0x00, 0x55, 0x60, 0x00, 0x60, 0x00, 0xfd}
+
+	// killableCode self destructs to the caller
+	killableCode = []byte{0x33, 0xff}
+
+	// factoryCode creates an empty contract, and stores its address in the slot 0
+	factoryCode = []byte{0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0xf0, 0x60, 0x00, 0x55}
+
+	// counterInitCode deploys the counter
+	counterInitCode = append(
+		[]byte{0x60, 0x09, 0x60, 0x0c, 0x60, 0x00, 0x39, 0x60, 0x09, 0x60, 0x00, 0xf3},
+		counterCode...,
+	)
+)
+
+// parallelContracts are the contracts of the differential tests
+var parallelContracts = map[types.Address][]byte{
+	types.StringToAddress("0x6000000000000000000000000000000000000001"): counterCode,
+	types.StringToAddress("0x6000000000000000000000000000000000000002"): callerCounterCode,
+	types.StringToAddress("0x6000000000000000000000000000000000000003"): coinbaseReaderCode,
+	types.StringToAddress("0x6000000000000000000000000000000000000004"): coinbaseForwarderCode,
+	types.StringToAddress("0x6000000000000000000000000000000000000005"): revertingCode,
+	types.StringToAddress("0x6000000000000000000000000000000000000006"): killableCode,
+	types.StringToAddress("0x6000000000000000000000000000000000000007"): factoryCode,
+	types.StringToAddress("0x6000000000000000000000000000000000000008"): proxyCode(
+		types.StringToAddress("0x6000000000000000000000000000000000000001"),
+	),
+}
+
+// proxyCode calls the contract
+func proxyCode(addr types.Address) []byte {
+	code := []byte{0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x73}
+	code = append(code, addr.Bytes()...)
+
+	return append(code, 0x5a, 0xf1, 0x50)
+}
+
+// parallelGenerator generates random blocks of transactions with a tunable amount of conflicts
+type parallelGenerator struct {
+	rand    *rand.Rand
+	senders []types.Address
+	nonces  map[types.Address]uint64
+	count   int
+
+	// shared is the share of the transactions calling the contracts, or paying the shared targets.
+	// The others are payments to new accounts
+	shared float64
+
+	// targets are the addresses receiving value, created contracts included
+	targets []types.Address
+}
+
+func newParallelGenerator(seed int64, senders int, shared float64) *parallelGenerator {
+	g := &parallelGenerator{
+		rand:   rand.New(rand.NewSource(seed)), //nolint:gosec
+		nonces: map[types.Address]uint64{},
+		shared: shared,
+	}
+
+	for i := 0; i < senders; i++ {
+		g.senders = append(g.senders, types.StringToAddress(fmt.Sprintf("0x7%039x", i+1)))
+	}
+
+	// the coinbase sends transactions too
+	g.senders = append(g.senders, parallelCoinbase)
+
+	g.targets = append(g.targets, parallelCoinbase, types.StringToAddress("0x8000000000000000000000000000000000000001"))
+	g.targets = append(g.targets, g.senders[:len(g.senders)/2]...)
+
+	return g
+}
+
+// genesis funds the senders, and deploys the contracts
+func (g *parallelGenerator) genesis() map[types.Address]*chain.GenesisAccount {
+	alloc := map[types.Address]*chain.GenesisAccount{}
+
+	for _, sender := range g.senders {
+		alloc[sender] = &chain.GenesisAccount{Balance: big.NewInt(1e18)}
+	}
+
+	for addr, code := range parallelContracts {
+		alloc[addr] = &chain.GenesisAccount{
+			Balance: big.NewInt(0),
+			Code:    code,
+			Storage: map[types.Hash]types.Hash{
+				types.StringToHash("0x0"): types.StringToHash("0x5"),
+			},
+		}
+	}
+
+	return alloc
+}
+
+// block generates a block of transactions, the conflicts come from the shared contracts and senders
+func (g *parallelGenerator) block(size int) []*types.Transaction {
+	// the stateful precompiled contract is enabled in the block
+	contracts := []types.Address{lastCallerAddr}
+	for addr := range parallelContracts {
+		contracts = append(contracts, addr)
+	}
+
+	txs := make([]*types.Transaction, 0, size)
+
+	for i := 0; i < size; i++ {
+		from := g.senders[g.rand.Intn(len(g.senders))]
+
+		tx := &types.Transaction{
+			From:     from,
+			Nonce:    g.nonces[from],
+			GasPrice: big.NewInt(int64(g.rand.Intn(3))),
+			Gas:      200000,
+			Value:    big.NewInt(int64(g.rand.Intn(1000))),
+		}
+
+		switch n := g.rand.Intn(10); {
+		case g.rand.Float64() >= g.shared:
+			// a payment to a new account, independent from the other transactions
+			to := types.BytesToAddress(big.NewInt(int64(g.count + 1)).Bytes())
+			tx.To = &to
+			tx.Gas = 21000
+		case n < 4:
+			// a transfer, the most common transaction of a payment chain
+			to := g.targets[g.rand.Intn(len(g.targets))]
+			tx.To = &to
+			tx.Gas = 21000
+		case n < 8:
+			to := contracts[g.rand.Intn(len(contracts))]
+			tx.To = &to
+		case n < 9:
+			// a contract creation, called by the next transactions
+			tx.Input = counterInitCode
+			g.targets = append(g.targets, crypto.CreateAddress(from, tx.Nonce))
+		default:
+			// a call to a created contract, or to an empty account
+			to := g.targets[g.rand.Intn(len(g.targets))]
+			tx.To = &to
+		}
+
+		g.count++
+		g.nonces[from]++
+		tx.Hash = types.BytesToHash(big.NewInt(int64(g.count)).Bytes())
+
+		txs = append(txs, tx)
+	}
+
+	return txs
+}
+
+// parallelChain executes the same blocks sequentially and in parallel
+type parallelChain struct {
+	t          *testing.T
+	sequential *state.Executor
+	parallel   *state.Executor
+	root       types.Hash
+	number     uint64
+}
+
+func newParallelChain(
+	t *testing.T,
+	forks *chain.Forks,
+	workers int,
+	alloc map[types.Address]*chain.GenesisAccount,
+) *parallelChain {
+	t.Helper()
+
+	s, _, root := buildState(alloc)
+
+	precompiles := []*chain.StatefulPrecompile{
+		{Name: "lastCaller", Address: lastCallerAddr, Block: chain.Fork(1)},
+	}
+
+	newExecutor := func() *state.Executor {
+		params := &chain.Params{Forks: forks, ChainID: 1, StatefulPrecompiles: precompiles}
+
+		precompiledRuntime := precompiled.NewPrecompiled()
+		if err := precompiledRuntime.SetupStatefulContracts(precompiles); err != nil {
+			t.Fatal(err)
+		}
+
+		executor := state.NewExecutor(params, s, hclog.NewNullLogger())
+		executor.SetRuntime(precompiledRuntime)
+		executor.SetRuntime(evm.NewEVM())
+		executor.GetHash = func(*types.Header) func(i uint64) types.Hash {
+			return vmTestBlockHash
+		}
+
+		return executor
+	}
+
+	c := &parallelChain{
+		t:          t,
+		sequential: newExecutor(),
+		parallel:   newExecutor(),
+		root:       root,
+	}
+
+	c.parallel.SetParallelExecution(workers)
+
+	return c
+}
+
+// process processes the block with both executors, and asserts the results are the same
+func (c *parallelChain) process(txs []*types.Transaction) {
+	c.t.Helper()
+
+	c.number++
+
+	block := &types.Block{
+		Header: &types.Header{
+			Number:   c.number,
+			GasLimit: parallelBlockGasLimit,
+		},
+		Transactions: txs,
+	}
+
+	sequential, seqErr := c.sequential.ProcessBlock(c.root, block, parallelCoinbase)
+	parallel, parErr := c.parallel.ProcessBlock(c.root, block, parallelCoinbase)
+
+	if seqErr != nil {
+		assert.EqualError(c.t, parErr, seqErr.Error())
+
+		return
+	}
+
+	if !assert.NoError(c.t, parErr) {
+		return
+	}
+
+	_, seqRoot := sequential.Commit()
+	_, parRoot := parallel.Commit()
+
+	assert.Equal(c.t, seqRoot, parRoot)
+	assert.Equal(c.t, sequential.TotalGas(), parallel.TotalGas())
+	assert.Equal(c.t, sequential.Receipts(), parallel.Receipts())
+
+	c.root = seqRoot
+}
+
+func TestParallelExecution(t *testing.T) {
+	testCases := []struct {
+		name    string
+		senders int
+		shared  float64
+		blocks  int
+		size    int
+		workers int
+	}{
+		{"payments", 2000, 0.02, 3, 300, 8},
+		{"few conflicts", 500, 0.2, 3, 300, 8},
+		{"many conflicts", 10, 1, 3, 200, 8},
+		{"a single sender", 1, 1, 2, 50, 4},
+		{"more workers than transactions", 20, 1, 2, 5, 16},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			for seed := int64(0); seed < 3; seed++ {
+				g := newParallelGenerator(seed, tc.senders, tc.shared)
+				c := newParallelChain(t, istanbulWith(nil), tc.workers, g.genesis())
+
+				for i := 0; i < tc.blocks; i++ {
+					c.process(g.block(tc.size))
+				}
+			}
+		})
+	}
+}
+
+func TestParallelExecution_Fallback(t *testing.T) {
+	g := newParallelGenerator(1, 20, 1)
+
+	t.Run("executes the blocks before byzantium sequentially", func(t *testing.T) {
+		c := newParallelChain(t, Forks["Homestead"], 4, g.genesis())
+
+		c.process(g.block(50))
+	})
+
+	testCases := []struct {
+		name   string
+		modify func(txs []*types.Transaction)
+	}{
+		{
+			"writes failed receipts for the transactions exceeding the block gas limit",
+			func(txs []*types.Transaction) {
+				txs[5].Gas = parallelBlockGasLimit + 1
+			},
+		},
+		{
+			"fails the blocks with an invalid transaction",
+			func(txs []*types.Transaction) {
+				txs[10].Nonce += 100
+			},
+		},
+		{
+			"fails the blocks exceeding the block gas limit",
+			func(txs []*types.Transaction) {
+				for _, tx := range txs {
+					tx.Gas = parallelBlockGasLimit
+				}
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			g := newParallelGenerator(2, 20, 1)
+			c := newParallelChain(t, istanbulWith(nil), 4, g.genesis())
+
+			txs := g.block(20)
+			tc.modify(txs)
+
+			c.process(txs)
+		})
+	}
+}
+
+func TestParallelExecution_Coinbase(t *testing.T) {
+	// the fee of a transaction destroying the coinbase is lost, so it can't be deferred
+	g := newParallelGenerator(3, 50, 1)
+
+	alloc := g.genesis()
+	alloc[parallelCoinbase] = &chain.GenesisAccount{Balance: big.NewInt(1e18), Code: killableCode}
+
+	c := newParallelChain(t, istanbulWith(nil), 8, alloc)
+
+	for i := 0; i < 3; i++ {
+		txs := g.block(100)
+
+		for _, tx := range txs[:10] {
+			tx.To = &parallelCoinbase
+			tx.Gas = 100000
+		}
+
+		c.process(txs)
+	}
+}
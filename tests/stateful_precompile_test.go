@@ -0,0 +1,128 @@
+package tests
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/juanidrobo/polygon-edge/chain"
+	"github.com/juanidrobo/polygon-edge/state/runtime"
+	"github.com/juanidrobo/polygon-edge/state/runtime/precompiled"
+	"github.com/juanidrobo/polygon-edge/types"
+)
+
+var lastCallerAddr = types.StringToAddress("0x0300000000000000000000000000000000000000")
+
+// lastCaller is a stateful contract storing its last caller in the slot 0
+type lastCaller struct {
+	addr types.Address
+}
+
+func (l *lastCaller) Run(
+	host runtime.Host,
+	config *chain.ForksInTime,
+	caller types.Address,
+	_ *big.Int,
+	_ []byte,
+	gas uint64,
+	readOnly bool,
+) ([]byte, uint64, error) {
+	if readOnly {
+		return host.GetStorage(l.addr, types.Hash{}).Bytes(), gas, nil
+	}
+
+	host.SetStorage(l.addr, types.Hash{}, types.BytesToHash(caller.Bytes()), config)
+
+	return nil, gas, nil
+}
+
+func init() {
+	precompiled.RegisterStatefulContract(
+		"lastCaller",
+		func(addr types.Address, _ json.RawMessage) (precompiled.StatefulContract, error) {
+			return &lastCaller{addr: addr}, nil
+		},
+	)
+}
+
+// callLastCaller calls the lastCaller contract with the given call opcode,
+// and stores its success in the slot 0
+func callLastCaller(op byte) []byte {
+	code := []byte{0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x60, 0x00} // the output and input of the call
+
+	if op == 0xf1 {
+		code = append(code, 0x60, 0x00) // the value of the call
+	}
+
+	code = append(code, 0x73)
+	code = append(code, lastCallerAddr.Bytes()...)
+
+	return append(code, 0x5a, op, 0x60, 0x00, 0x55) // GAS, op, PUSH1 0, SSTORE
+}
+
+func TestStatefulPrecompile(t *testing.T) {
+	testCases := []struct {
+		name    string
+		block   uint64
+		op      byte
+		success bool
+		caller  types.Hash
+		nonce   uint64
+	}{
+		{
+			"stores the state once enabled",
+			1,
+			0xf1, // CALL
+			true,
+			types.BytesToHash(eipContract.Bytes()),
+			1,
+		},
+		{
+			"is an empty account before its block",
+			2,
+			0xf1, // CALL
+			true,
+			types.Hash{},
+			0,
+		},
+		{
+			"can't be delegated to",
+			1,
+			0xf4, // DELEGATECALL
+			false,
+			types.Hash{},
+			1,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			c := &eipStateCase{
+				forks: istanbulWith(nil),
+				precompiles: []*chain.StatefulPrecompile{
+					{Name: "lastCaller", Address: lastCallerAddr, Block: chain.Fork(tc.block)},
+				},
+				pre: map[types.Address]*chain.GenesisAccount{
+					eipContract: {Balance: big.NewInt(0), Code: callLastCaller(tc.op)},
+				},
+				msg: callContract(200000),
+			}
+
+			result, txn, err := c.run(t)
+			assert.NoError(t, err)
+			assert.NoError(t, result.Err)
+
+			success := txn.GetState(eipContract, types.Hash{}) == types.BytesToHash([]byte{0x1})
+			assert.Equal(t, tc.success, success)
+
+			// the storage of the contract is kept, as its account is not empty once enabled
+			txn.CleanDeleteObjects(true)
+			assert.Equal(t, tc.caller, txn.GetState(lastCallerAddr, types.Hash{}))
+			assert.Equal(t, tc.nonce, txn.GetNonce(lastCallerAddr))
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math/big"
 
+	"github.com/juanidrobo/polygon-edge/state/runtime/allowlist"
 	"github.com/juanidrobo/polygon-edge/types"
 )
 
@@ -31,6 +32,10 @@ func (m defaultMockStore) GetNonce(types.Hash, types.Address) uint64 {
 	return 0
 }
 
+func (m defaultMockStore) GetStorage(types.Hash, types.Address, types.Hash) types.Hash {
+	return types.Hash{}
+}
+
 func (m defaultMockStore) GetBlockByHash(types.Hash, bool) (*types.Block, bool) {
 	return nil, false
 }
@@ -41,6 +46,24 @@ func (m defaultMockStore) GetBalance(types.Hash, types.Address) (*big.Int, error
 	return balance, nil
 }
 
+// allowlistMockStore is a store in which the allowlists are set up, and their storage slots hold the given role
+type allowlistMockStore struct {
+	defaultMockStore
+	role allowlist.Role
+}
+
+func (m allowlistMockStore) GetNonce(_ types.Hash, addr types.Address) uint64 {
+	if addr == allowlist.TxAllowListAddr || addr == allowlist.DeployerAllowListAddr {
+		return 1
+	}
+
+	return 0
+}
+
+func (m allowlistMockStore) GetStorage(types.Hash, types.Address, types.Hash) types.Hash {
+	return types.BytesToHash([]byte{byte(m.role)})
+}
+
 type faultyMockStore struct {
 }
 
@@ -52,6 +75,10 @@ func (fms faultyMockStore) GetNonce(root types.Hash, addr types.Address) uint64
 	return 99999
 }
 
+func (fms faultyMockStore) GetStorage(root types.Hash, addr types.Address, key types.Hash) types.Hash {
+	return types.Hash{}
+}
+
 func (fms faultyMockStore) GetBlockByHash(hash types.Hash, b bool) (*types.Block, bool) {
 	return nil, false
 }
@@ -78,3 +78,15 @@ func (p *TxPool) Subscribe(
 		}
 	}
 }
+
+// TxPoolSubscribe subscribes to new events in the tx pool, for in-process listeners.
+// The returned function cancels the subscription
+func (p *TxPool) TxPoolSubscribe(request *proto.SubscribeRequest) (<-chan *proto.TxPoolEvent, func()) {
+	subscription := p.eventManager.subscribe(request.Types)
+
+	cancel := func() {
+		p.eventManager.cancelSubscription(subscription.subscriptionID)
+	}
+
+	return subscription.subscriptionChannel, cancel
+}
@@ -0,0 +1,476 @@
+package txpool
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/hashicorp/go-hclog"
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/juanidrobo/polygon-edge/network"
+	peerEvent "github.com/juanidrobo/polygon-edge/network/event"
+	libp2pGrpc "github.com/juanidrobo/polygon-edge/network/grpc"
+	"github.com/juanidrobo/polygon-edge/txpool/proto"
+	"github.com/juanidrobo/polygon-edge/types"
+)
+
+const (
+	propagationProtoV1 = "/txpool/propagation/0.1"
+
+	// maxAnnounceBatch is the maximum number of hashes sent in a single announcement
+	maxAnnounceBatch = 256
+
+	// announceInterval is how often the pending hashes are announced, if the batch is not full
+	announceInterval = 100 * time.Millisecond
+
+	// maxFetchBatch is the maximum number of transactions requested from (or served to) a peer at once
+	maxFetchBatch = 256
+
+	// fetchTimeout is the time limit for fetching announced transactions from a peer
+	fetchTimeout = 5 * time.Second
+
+	// maxKnownTxs is the number of transaction hashes remembered
+	// per peer, and for the recently announced transactions
+	maxKnownTxs = 8192
+
+	// announceQueueSize is the size of the buffer of hashes waiting to be announced
+	announceQueueSize = 4096
+
+	// maxAnnouncers is the number of other peers remembered per hash being fetched,
+	// to fetch the transaction from the next one if the fetch fails
+	maxAnnouncers = 4
+)
+
+// txPeer is a peer supporting the transaction propagation protocol
+type txPeer struct {
+	id     peer.ID
+	conn   *grpc.ClientConn
+	client proto.TxnPropagationClient
+
+	// hashes the peer is known to have
+	known *lru.Cache
+}
+
+// markKnown marks the hashes as known by the peer, and returns the ones that were not known before
+func (p *txPeer) markKnown(hashes ...types.Hash) []types.Hash {
+	unknown := make([]types.Hash, 0, len(hashes))
+
+	for _, hash := range hashes {
+		if ok, _ := p.known.ContainsOrAdd(hash, struct{}{}); !ok {
+			unknown = append(unknown, hash)
+		}
+	}
+
+	return unknown
+}
+
+// propagation announces the hashes of new pool transactions to the peers in batches,
+// and fetches the announced transactions the pool doesn't have yet.
+// Only sealing nodes add the fetched transactions to their pool. The others keep
+// them to serve their peers, and announce them in turn, so that the transactions
+// reach the validators which are only connected to non-validator nodes
+type propagation struct {
+	proto.UnimplementedTxnPropagationServer
+
+	logger  hclog.Logger
+	pool    *TxPool
+	network *network.Server
+
+	peers sync.Map // peer.ID -> *txPeer
+
+	// hashes waiting to be announced
+	announceCh chan types.Hash
+
+	// recently announced hashes, to avoid announcing the same transaction twice
+	announced *lru.Cache
+
+	// relayed holds the transactions fetched by a node which is not sealing, to serve them to its peers
+	relayed *lru.Cache
+
+	// hashes currently being fetched from some peer, with the other peers which announced them
+	inFlight     map[types.Hash][]*txPeer
+	inFlightLock sync.Mutex
+
+	closeCh chan struct{}
+}
+
+func newPropagation(logger hclog.Logger, pool *TxPool, network *network.Server) *propagation {
+	announced, _ := lru.New(maxKnownTxs)
+	relayed, _ := lru.New(maxKnownTxs)
+
+	return &propagation{
+		logger:     logger.Named("propagation"),
+		pool:       pool,
+		network:    network,
+		announceCh: make(chan types.Hash, announceQueueSize),
+		announced:  announced,
+		relayed:    relayed,
+		inFlight:   make(map[types.Hash][]*txPeer),
+		closeCh:    make(chan struct{}),
+	}
+}
+
+// start registers the propagation protocol and starts the announcement loop
+func (t *propagation) start() {
+	grpcStream := libp2pGrpc.NewGrpcStream()
+	proto.RegisterTxnPropagationServer(grpcStream.GrpcServer(), t)
+	grpcStream.Serve()
+	t.network.RegisterProtocol(propagationProtoV1, grpcStream)
+
+	for _, p := range t.network.Peers() {
+		t.addPeer(p.Info.ID)
+	}
+
+	if err := t.network.SubscribeFn(func(evnt *peerEvent.PeerEvent) {
+		switch evnt.Type {
+		case peerEvent.PeerConnected:
+			t.addPeer(evnt.PeerID)
+		case peerEvent.PeerDisconnected:
+			t.removePeer(evnt.PeerID)
+		}
+	}); err != nil {
+		t.logger.Error("failed to subscribe to peer events", "err", err)
+	}
+
+	go t.announceLoop()
+}
+
+// close stops the announcement loop
+func (t *propagation) close() {
+	close(t.closeCh)
+}
+
+// addPeer opens a propagation stream to the peer. Peers that don't support
+// the protocol are skipped, they still receive the transactions through gossip
+func (t *propagation) addPeer(peerID peer.ID) {
+	if _, ok := t.peers.Load(peerID); ok {
+		return
+	}
+
+	stream, err := t.network.NewStream(propagationProtoV1, peerID)
+	if err != nil {
+		t.logger.Debug("peer does not support transaction propagation", "peer", peerID, "err", err)
+
+		return
+	}
+
+	conn := libp2pGrpc.WrapClient(stream)
+	known, _ := lru.New(maxKnownTxs)
+
+	t.peers.Store(peerID, &txPeer{
+		id:     peerID,
+		conn:   conn,
+		client: proto.NewTxnPropagationClient(conn),
+		known:  known,
+	})
+}
+
+// removePeer closes the propagation stream to the peer
+func (t *propagation) removePeer(peerID peer.ID) {
+	p, ok := t.peers.LoadAndDelete(peerID)
+	if !ok {
+		return
+	}
+
+	if txPeer, ok := p.(*txPeer); ok {
+		if err := txPeer.conn.Close(); err != nil {
+			t.logger.Debug("failed to close propagation stream", "peer", peerID, "err", err)
+		}
+	}
+}
+
+// getPeer returns the propagation peer with the given ID, if any
+func (t *propagation) getPeer(peerID peer.ID) *txPeer {
+	p, ok := t.peers.Load(peerID)
+	if !ok {
+		return nil
+	}
+
+	txPeer, _ := p.(*txPeer)
+
+	return txPeer
+}
+
+// announce queues the transaction hash for the next announcement
+func (t *propagation) announce(hash types.Hash) {
+	if ok, _ := t.announced.ContainsOrAdd(hash, struct{}{}); ok {
+		return
+	}
+
+	select {
+	case t.announceCh <- hash:
+	default:
+		t.logger.Debug("announcement queue is full, dropping hash", "hash", hash)
+	}
+}
+
+// announceLoop sends the queued hashes to the peers, once the batch
+// is full or the announcement interval elapses
+func (t *propagation) announceLoop() {
+	ticker := time.NewTicker(announceInterval)
+	defer ticker.Stop()
+
+	batch := make([]types.Hash, 0, maxAnnounceBatch)
+
+	for {
+		select {
+		case hash := <-t.announceCh:
+			batch = append(batch, hash)
+
+			if len(batch) < maxAnnounceBatch {
+				continue
+			}
+		case <-ticker.C:
+			if len(batch) == 0 {
+				continue
+			}
+		case <-t.closeCh:
+			return
+		}
+
+		t.broadcastHashes(batch)
+
+		batch = make([]types.Hash, 0, maxAnnounceBatch)
+	}
+}
+
+// broadcastHashes announces the hashes to every peer not known to have them
+func (t *propagation) broadcastHashes(hashes []types.Hash) {
+	t.peers.Range(func(_, p interface{}) bool {
+		txPeer, ok := p.(*txPeer)
+		if !ok {
+			return true
+		}
+
+		unknown := txPeer.markKnown(hashes...)
+		if len(unknown) == 0 {
+			return true
+		}
+
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+			defer cancel()
+
+			if _, err := txPeer.client.AnnounceTxs(ctx, hashesToProto(unknown)); err != nil {
+				t.logger.Debug("failed to announce transactions", "peer", txPeer.id, "err", err)
+			}
+		}()
+
+		return true
+	})
+}
+
+// AnnounceTxs implements the TxnPropagationServer interface
+func (t *propagation) AnnounceTxs(ctx context.Context, req *proto.TxnHashes) (*emptypb.Empty, error) {
+	grpcCtx, ok := ctx.(*libp2pGrpc.Context)
+	if !ok {
+		return &emptypb.Empty{}, nil
+	}
+
+	hashes := hashesFromProto(req)
+
+	txPeer := t.getPeer(grpcCtx.PeerID)
+	if txPeer == nil {
+		return &emptypb.Empty{}, nil
+	}
+
+	txPeer.markKnown(hashes...)
+
+	// request only the transactions that are neither known nor being fetched
+	// from another peer, in which case the peer is remembered for a retry
+	missing := make([]types.Hash, 0, len(hashes))
+
+	t.inFlightLock.Lock()
+
+	for _, hash := range hashes {
+		if t.hasTx(hash) {
+			continue
+		}
+
+		if announcers, ok := t.inFlight[hash]; ok {
+			if len(announcers) < maxAnnouncers {
+				t.inFlight[hash] = append(announcers, txPeer)
+			}
+
+			continue
+		}
+
+		t.inFlight[hash] = nil
+
+		missing = append(missing, hash)
+	}
+
+	t.inFlightLock.Unlock()
+
+	if len(missing) != 0 {
+		go t.fetchTxs(txPeer, missing)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// hasTx returns whether the transaction is in the pool, or relayed by the node
+func (t *propagation) hasTx(hash types.Hash) bool {
+	if _, ok := t.pool.index.get(hash); ok {
+		return true
+	}
+
+	return t.relayed.Contains(hash)
+}
+
+// getTx returns the transaction from the pool, or from the relayed ones
+func (t *propagation) getTx(hash types.Hash) (*types.Transaction, bool) {
+	if tx, ok := t.pool.index.get(hash); ok {
+		return tx, true
+	}
+
+	if tx, ok := t.relayed.Get(hash); ok {
+		relayedTx, ok := tx.(*types.Transaction)
+
+		return relayedTx, ok
+	}
+
+	return nil, false
+}
+
+// fetchTxs requests the transactions from the peer and adds them to the pool, or to the relayed ones
+// if the node is not sealing. The transactions the peer didn't send are requested from the next peer
+// which announced them, if any
+func (t *propagation) fetchTxs(txPeer *txPeer, hashes []types.Hash) {
+	requested := make(map[types.Hash]bool, len(hashes))
+	for _, hash := range hashes {
+		requested[hash] = false
+	}
+
+	defer t.fetchDone(requested)
+
+	for start := 0; start < len(hashes); start += maxFetchBatch {
+		end := start + maxFetchBatch
+		if end > len(hashes) {
+			end = len(hashes)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+		resp, err := txPeer.client.GetTxs(ctx, hashesToProto(hashes[start:end]))
+
+		cancel()
+
+		if err != nil {
+			t.logger.Debug("failed to fetch announced transactions", "peer", txPeer.id, "err", err)
+
+			return
+		}
+
+		for _, raw := range resp.Txs {
+			tx := new(types.Transaction)
+			if err := tx.UnmarshalRLP(raw.Raw.Value); err != nil {
+				t.logger.Error("failed to decode fetched tx", "peer", txPeer.id, "err", err)
+
+				continue
+			}
+
+			if fetched, ok := requested[tx.Hash]; !ok || fetched {
+				t.logger.Debug("dropping unrequested tx", "peer", txPeer.id, "hash", tx.Hash)
+
+				continue
+			}
+
+			requested[tx.Hash] = true
+
+			if !t.pool.sealing {
+				// same as gossiped transactions, fetched ones are only accepted by sealers
+				t.relayed.Add(tx.Hash, tx)
+			} else if err := t.pool.addTx(gossip, tx); err != nil {
+				t.logger.Error("failed to add fetched txn", "err", err)
+
+				continue
+			}
+
+			t.announce(tx.Hash)
+		}
+	}
+}
+
+// fetchDone releases the requested hashes which are fetched or known by now,
+// and requests the others from the next peer which announced them, if any
+func (t *propagation) fetchDone(requested map[types.Hash]bool) {
+	retries := make(map[*txPeer][]types.Hash)
+
+	t.inFlightLock.Lock()
+
+	for hash, fetched := range requested {
+		announcers := t.inFlight[hash]
+
+		if fetched || len(announcers) == 0 || t.hasTx(hash) {
+			delete(t.inFlight, hash)
+
+			continue
+		}
+
+		t.inFlight[hash] = announcers[1:]
+		retries[announcers[0]] = append(retries[announcers[0]], hash)
+	}
+
+	t.inFlightLock.Unlock()
+
+	for txPeer, hashes := range retries {
+		go t.fetchTxs(txPeer, hashes)
+	}
+}
+
+// GetTxs implements the TxnPropagationServer interface
+func (t *propagation) GetTxs(_ context.Context, req *proto.TxnHashes) (*proto.Txns, error) {
+	hashes := hashesFromProto(req)
+
+	if len(hashes) > maxFetchBatch {
+		hashes = hashes[:maxFetchBatch]
+	}
+
+	resp := &proto.Txns{
+		Txs: make([]*proto.Txn, 0, len(hashes)),
+	}
+
+	for _, hash := range hashes {
+		tx, ok := t.getTx(hash)
+		if !ok {
+			continue
+		}
+
+		resp.Txs = append(resp.Txs, &proto.Txn{
+			Raw: &any.Any{
+				Value: tx.MarshalRLP(),
+			},
+		})
+	}
+
+	return resp, nil
+}
+
+// hashesToProto converts the hashes to a proto.TxnHashes
+func hashesToProto(hashes []types.Hash) *proto.TxnHashes {
+	raw := make([]string, 0, len(hashes))
+
+	for _, hash := range hashes {
+		raw = append(raw, hash.String())
+	}
+
+	return &proto.TxnHashes{
+		Hashes: raw,
+	}
+}
+
+// hashesFromProto extracts the hashes from a proto.TxnHashes
+func hashesFromProto(req *proto.TxnHashes) []types.Hash {
+	hashes := make([]types.Hash, 0, len(req.Hashes))
+
+	for _, raw := range req.Hashes {
+		hashes = append(hashes, types.StringToHash(raw))
+	}
+
+	return hashes
+}
@@ -0,0 +1,146 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.19.4
+// source: txpool/proto/v1.proto
+
+package proto
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// TxnPropagationClient is the client API for TxnPropagation service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type TxnPropagationClient interface {
+	// Announces the hashes of transactions the peer has in its pool
+	AnnounceTxs(ctx context.Context, in *TxnHashes, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Returns the transactions with the given hashes, if present in the pool
+	GetTxs(ctx context.Context, in *TxnHashes, opts ...grpc.CallOption) (*Txns, error)
+}
+
+type txnPropagationClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTxnPropagationClient(cc grpc.ClientConnInterface) TxnPropagationClient {
+	return &txnPropagationClient{cc}
+}
+
+func (c *txnPropagationClient) AnnounceTxs(ctx context.Context, in *TxnHashes, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/v1.TxnPropagation/AnnounceTxs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *txnPropagationClient) GetTxs(ctx context.Context, in *TxnHashes, opts ...grpc.CallOption) (*Txns, error) {
+	out := new(Txns)
+	err := c.cc.Invoke(ctx, "/v1.TxnPropagation/GetTxs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TxnPropagationServer is the server API for TxnPropagation service.
+// All implementations must embed UnimplementedTxnPropagationServer
+// for forward compatibility
+type TxnPropagationServer interface {
+	// Announces the hashes of transactions the peer has in its pool
+	AnnounceTxs(context.Context, *TxnHashes) (*emptypb.Empty, error)
+	// Returns the transactions with the given hashes, if present in the pool
+	GetTxs(context.Context, *TxnHashes) (*Txns, error)
+	mustEmbedUnimplementedTxnPropagationServer()
+}
+
+// UnimplementedTxnPropagationServer must be embedded to have forward compatible implementations.
+type UnimplementedTxnPropagationServer struct {
+}
+
+func (UnimplementedTxnPropagationServer) AnnounceTxs(context.Context, *TxnHashes) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AnnounceTxs not implemented")
+}
+func (UnimplementedTxnPropagationServer) GetTxs(context.Context, *TxnHashes) (*Txns, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTxs not implemented")
+}
+func (UnimplementedTxnPropagationServer) mustEmbedUnimplementedTxnPropagationServer() {}
+
+// UnsafeTxnPropagationServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TxnPropagationServer will
+// result in compilation errors.
+type UnsafeTxnPropagationServer interface {
+	mustEmbedUnimplementedTxnPropagationServer()
+}
+
+func RegisterTxnPropagationServer(s grpc.ServiceRegistrar, srv TxnPropagationServer) {
+	s.RegisterService(&TxnPropagation_ServiceDesc, srv)
+}
+
+func _TxnPropagation_AnnounceTxs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxnHashes)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxnPropagationServer).AnnounceTxs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.TxnPropagation/AnnounceTxs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxnPropagationServer).AnnounceTxs(ctx, req.(*TxnHashes))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TxnPropagation_GetTxs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxnHashes)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxnPropagationServer).GetTxs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.TxnPropagation/GetTxs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxnPropagationServer).GetTxs(ctx, req.(*TxnHashes))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// TxnPropagation_ServiceDesc is the grpc.ServiceDesc for TxnPropagation service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TxnPropagation_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "v1.TxnPropagation",
+	HandlerType: (*TxnPropagationServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AnnounceTxs",
+			Handler:    _TxnPropagation_AnnounceTxs_Handler,
+		},
+		{
+			MethodName: "GetTxs",
+			Handler:    _TxnPropagation_GetTxs_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "txpool/proto/v1.proto",
+}
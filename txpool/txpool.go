@@ -13,6 +13,8 @@ import (
 	"github.com/juanidrobo/polygon-edge/chain"
 	"github.com/juanidrobo/polygon-edge/network"
 	"github.com/juanidrobo/polygon-edge/state"
+	"github.com/juanidrobo/polygon-edge/state/runtime"
+	"github.com/juanidrobo/polygon-edge/state/runtime/allowlist"
 	"github.com/juanidrobo/polygon-edge/txpool/proto"
 	"github.com/juanidrobo/polygon-edge/types"
 )
@@ -37,6 +39,7 @@ var (
 	ErrInvalidAccountState = errors.New("invalid account state")
 	ErrAlreadyKnown        = errors.New("already known")
 	ErrOversizedData       = errors.New("oversized data")
+	ErrMaxInitCodeSize     = errors.New("max initcode size exceeded")
 )
 
 // indicates origin of a transaction
@@ -66,6 +69,7 @@ type store interface {
 	Header() *types.Header
 	GetNonce(root types.Hash, addr types.Address) uint64
 	GetBalance(root types.Hash, addr types.Address) (*big.Int, error)
+	GetStorage(root types.Hash, addr types.Address, key types.Hash) types.Hash
 	GetBlockByHash(types.Hash, bool) (*types.Block, bool)
 }
 
@@ -77,6 +81,7 @@ type Config struct {
 	PriceLimit uint64
 	MaxSlots   uint64
 	Sealing    bool
+	NoGossip   bool // stop publishing full transactions on the gossip topic
 }
 
 /* All requests are passed to the main loop
@@ -122,6 +127,9 @@ type TxPool struct {
 	forks  chain.ForksInTime
 	store  store
 
+	// allowlists restricts the transaction senders and the contract deployers
+	allowlists *allowlist.Policy
+
 	// map of all accounts registered by the pool
 	accounts accountsMap
 
@@ -135,6 +143,13 @@ type TxPool struct {
 	// networking stack
 	topic *network.Topic
 
+	// hash announcement based transaction propagation
+	propagation *propagation
+
+	// flag indicating if full transactions should
+	// still be published on the gossip topic
+	gossip bool
+
 	// gauge for measuring pool capacity
 	gauge slotGauge
 
@@ -184,6 +199,7 @@ func NewTxPool(
 		gauge:       slotGauge{height: 0, max: config.MaxSlots},
 		priceLimit:  config.PriceLimit,
 		sealing:     config.Sealing,
+		gossip:      !config.NoGossip,
 	}
 
 	// Attach the event manager
@@ -201,6 +217,7 @@ func NewTxPool(
 		}
 
 		pool.topic = topic
+		pool.propagation = newPropagation(pool.logger, pool, network)
 	}
 
 	if grpcServer != nil {
@@ -222,6 +239,10 @@ func (p *TxPool) Start() {
 	// set default value of txpool pending transactions gauge
 	p.metrics.PendingTxs.Set(0)
 
+	if p.propagation != nil {
+		p.propagation.start()
+	}
+
 	go func() {
 		for {
 			select {
@@ -238,6 +259,10 @@ func (p *TxPool) Start() {
 
 // Close shuts down the pool's main loop.
 func (p *TxPool) Close() {
+	if p.propagation != nil {
+		p.propagation.close()
+	}
+
 	p.eventManager.Close()
 	p.shutdownCh <- struct{}{}
 }
@@ -248,6 +273,12 @@ func (p *TxPool) SetSigner(s signer) {
 	p.signer = s
 }
 
+// SetAllowlists sets the policy of the allowlists
+// the pool will use to reject the transactions of the disallowed senders.
+func (p *TxPool) SetAllowlists(policy *allowlist.Policy) {
+	p.allowlists = policy
+}
+
 // AddTx adds a new transaction to the pool (sent from json-RPC/gRPC endpoints)
 // and broadcasts it to the network (if enabled).
 func (p *TxPool) AddTx(tx *types.Transaction) error {
@@ -257,9 +288,14 @@ func (p *TxPool) AddTx(tx *types.Transaction) error {
 		return err
 	}
 
-	// broadcast the transaction only if a topic
-	// subscription is present
-	if p.topic != nil {
+	// announce the transaction hash to the peers
+	if p.propagation != nil {
+		p.propagation.announce(tx.Hash)
+	}
+
+	// broadcast the full transaction only if a topic
+	// subscription is present and gossip is not disabled
+	if p.topic != nil && p.gossip {
 		tx := &proto.Txn{
 			Raw: &any.Any{
 				Value: tx.MarshalRLP(),
@@ -472,6 +508,11 @@ func (p *TxPool) validateTx(tx *types.Transaction) error {
 		return ErrOversizedData
 	}
 
+	// Check the initcode size limit of contract creations (eip-3860)
+	if p.forks.EIP3860 && tx.IsContractCreation() && len(tx.Input) > runtime.MaxInitCodeSize {
+		return ErrMaxInitCodeSize
+	}
+
 	// Check if the transaction has a strictly positive value
 	if tx.Value.Sign() < 0 {
 		return ErrNegativeValue
@@ -505,6 +546,20 @@ func (p *TxPool) validateTx(tx *types.Transaction) error {
 	// Grab the state root for the latest block
 	stateRoot := p.store.Header().StateRoot
 
+	// Check the sender is allowed by the allowlists of the next block
+	allowlistState := &stateAt{store: p.store, root: stateRoot}
+	nextBlock := p.store.Header().Number + 1
+
+	if err := p.allowlists.CheckSender(allowlistState, nextBlock, tx.From); err != nil {
+		return err
+	}
+
+	if tx.IsContractCreation() {
+		if err := p.allowlists.CheckDeployer(allowlistState, nextBlock, tx.From); err != nil {
+			return err
+		}
+	}
+
 	// Check nonce ordering
 	if p.store.GetNonce(stateRoot, tx.From) > tx.Nonce {
 		return ErrNonceTooLow
@@ -521,7 +576,7 @@ func (p *TxPool) validateTx(tx *types.Transaction) error {
 	}
 
 	// Make sure the transaction has more gas than the basic transaction fee
-	intrinsicGas, err := state.TransactionGasCost(tx, p.forks.Homestead, p.forks.Istanbul)
+	intrinsicGas, err := state.TransactionGasCost(tx, p.forks.Homestead, p.forks.Istanbul, p.forks.EIP3860)
 	if err != nil {
 		return err
 	}
@@ -540,6 +595,20 @@ func (p *TxPool) validateTx(tx *types.Transaction) error {
 	return nil
 }
 
+// stateAt reads the storage and the nonce of the accounts at a state root
+type stateAt struct {
+	store store
+	root  types.Hash
+}
+
+func (s *stateAt) GetState(addr types.Address, key types.Hash) types.Hash {
+	return s.store.GetStorage(s.root, addr, key)
+}
+
+func (s *stateAt) GetNonce(addr types.Address) uint64 {
+	return s.store.GetNonce(s.root, addr)
+}
+
 // addTx is the main entry point to the pool
 // for all new transactions. If the call is
 // successful, an account is created for this address
@@ -661,6 +730,14 @@ func (p *TxPool) addGossipTx(obj interface{}) {
 	// add tx
 	if err := p.addTx(gossip, tx); err != nil {
 		p.logger.Error("failed to add broadcasted txn", "err", err)
+
+		return
+	}
+
+	// announce it to the peers that only
+	// support hash based propagation
+	if p.propagation != nil {
+		p.propagation.announce(tx.Hash)
 	}
 }
 
@@ -3,6 +3,7 @@ package txpool
 import (
 	"context"
 	"crypto/rand"
+	"errors"
 	"math/big"
 	"testing"
 	"time"
@@ -10,11 +11,17 @@ import (
 	"github.com/juanidrobo/polygon-edge/chain"
 	"github.com/juanidrobo/polygon-edge/crypto"
 	"github.com/juanidrobo/polygon-edge/helper/tests"
+	"github.com/juanidrobo/polygon-edge/network"
+	libp2pGrpc "github.com/juanidrobo/polygon-edge/network/grpc"
+	"github.com/juanidrobo/polygon-edge/state/runtime/allowlist"
 	"github.com/juanidrobo/polygon-edge/txpool/proto"
 	"github.com/juanidrobo/polygon-edge/types"
 	"github.com/golang/protobuf/ptypes/any"
 	"github.com/hashicorp/go-hclog"
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
 )
 
 const (
@@ -143,6 +150,81 @@ func TestAddTxErrors(t *testing.T) {
 		)
 	})
 
+	t.Run("ErrSenderNotAllowed", func(t *testing.T) {
+		policy, err := allowlist.NewPolicy([]*chain.StatefulPrecompile{
+			{Name: allowlist.TxAllowListName, Address: allowlist.TxAllowListAddr},
+		})
+		assert.NoError(t, err)
+
+		for _, role := range []allowlist.Role{allowlist.NoRole, allowlist.EnabledRole, allowlist.AdminRole} {
+			pool, err := newTestPool(allowlistMockStore{NewDefaultMockStore(mockHeader), role})
+			assert.NoError(t, err)
+
+			pool.SetSigner(poolSigner)
+			pool.SetAllowlists(policy)
+
+			err = pool.validateTx(signTx(newTx(defaultAddr, 0, 1)))
+			if role.IsEnabled() {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, allowlist.ErrSenderNotAllowed)
+			}
+		}
+	})
+
+	t.Run("ErrSenderNotAllowed at the block enabling the allowlist", func(t *testing.T) {
+		// the head is the parent of the block enabling the allowlist, its state has no roles yet
+		head := &types.Header{Number: 4, GasLimit: mockHeader.GasLimit}
+
+		for _, admin := range []types.Address{defaultAddr, addr1} {
+			policy, err := allowlist.NewPolicy([]*chain.StatefulPrecompile{
+				{
+					Name:    allowlist.TxAllowListName,
+					Address: allowlist.TxAllowListAddr,
+					Block:   chain.Fork(5),
+					Config:  []byte(`{"admins": ["` + admin.String() + `"]}`),
+				},
+			})
+			assert.NoError(t, err)
+
+			pool, err := newTestPool(NewDefaultMockStore(head))
+			assert.NoError(t, err)
+
+			pool.SetSigner(poolSigner)
+			pool.SetAllowlists(policy)
+
+			err = pool.validateTx(signTx(newTx(defaultAddr, 0, 1)))
+			if admin == defaultAddr {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, allowlist.ErrSenderNotAllowed)
+			}
+		}
+	})
+
+	t.Run("ErrDeployerNotAllowed", func(t *testing.T) {
+		policy, err := allowlist.NewPolicy([]*chain.StatefulPrecompile{
+			{Name: allowlist.DeployerAllowListName, Address: allowlist.DeployerAllowListAddr},
+		})
+		assert.NoError(t, err)
+
+		pool := setupPool()
+		pool.SetAllowlists(policy)
+
+		// only the contract creations are restricted
+		tx := newTx(defaultAddr, 0, 1)
+		tx.To = &addr1
+
+		assert.NoError(t, pool.validateTx(signTx(tx)))
+
+		tx = newTx(defaultAddr, 0, 1)
+
+		assert.ErrorIs(t,
+			pool.validateTx(signTx(tx)),
+			allowlist.ErrDeployerNotAllowed,
+		)
+	})
+
 	t.Run("ErrBlockLimitExceeded", func(t *testing.T) {
 		pool := setupPool()
 
@@ -1948,3 +2030,344 @@ func TestGetTxs(t *testing.T) {
 		})
 	}
 }
+
+func TestPropagationGetTxs(t *testing.T) {
+	pool, err := newTestPool()
+	assert.NoError(t, err)
+
+	known := newTx(addr1, 0, 1)
+	known.ComputeHash()
+	pool.index.add(known)
+
+	unknown := newTx(addr2, 0, 1)
+	unknown.ComputeHash()
+
+	prop := newPropagation(hclog.NewNullLogger(), pool, nil)
+
+	resp, err := prop.GetTxs(context.Background(), hashesToProto([]types.Hash{known.Hash, unknown.Hash}))
+	assert.NoError(t, err)
+	assert.Len(t, resp.Txs, 1)
+
+	tx := new(types.Transaction)
+	assert.NoError(t, tx.UnmarshalRLP(resp.Txs[0].Raw.Value))
+	assert.Equal(t, known.Hash, tx.Hash)
+}
+
+func TestPropagationAnnounceDedup(t *testing.T) {
+	pool, err := newTestPool()
+	assert.NoError(t, err)
+
+	prop := newPropagation(hclog.NewNullLogger(), pool, nil)
+
+	hash := types.StringToHash("0x1")
+	prop.announce(hash)
+	prop.announce(hash)
+
+	assert.Len(t, prop.announceCh, 1)
+}
+
+func TestPropagationMarkKnown(t *testing.T) {
+	known, _ := lru.New(maxKnownTxs)
+	p := &txPeer{known: known}
+
+	hash1, hash2 := types.StringToHash("0x1"), types.StringToHash("0x2")
+
+	assert.Equal(t, []types.Hash{hash1}, p.markKnown(hash1))
+	assert.Equal(t, []types.Hash{hash2}, p.markKnown(hash1, hash2))
+	assert.Empty(t, p.markKnown(hash1, hash2))
+}
+
+func TestPropagationHashesProto(t *testing.T) {
+	hashes := []types.Hash{types.StringToHash("0x1"), types.StringToHash("0x2")}
+
+	assert.Equal(t, hashes, hashesFromProto(hashesToProto(hashes)))
+}
+
+// newNetworkTestPool returns a started txpool propagating transactions over the network server
+func newNetworkTestPool(t *testing.T, server *network.Server, config *Config) *TxPool {
+	t.Helper()
+
+	pool, err := NewTxPool(
+		hclog.NewNullLogger(),
+		forks.At(0),
+		defaultMockStore{DefaultHeader: mockHeader},
+		nil,
+		server,
+		nilMetrics,
+		config,
+	)
+	assert.NoError(t, err)
+
+	pool.SetSigner(crypto.NewEIP155Signer(100))
+
+	return pool
+}
+
+// createNetworkServers returns the given number of network servers, closed at the end of the test
+func createNetworkServers(t *testing.T, count int) []*network.Server {
+	t.Helper()
+
+	servers := make([]*network.Server, count)
+
+	for i := range servers {
+		// the peers only connect to the ones they are joined to
+		server, err := network.CreateServer(&network.CreateServerParams{
+			ConfigCallback: func(c *network.Config) {
+				c.NoDiscover = true
+			},
+		})
+		if err != nil {
+			t.Fatalf("Unable to create network server, %v", err)
+		}
+
+		servers[i] = server
+	}
+
+	t.Cleanup(func() {
+		for _, server := range servers {
+			assert.NoError(t, server.Close())
+		}
+	})
+
+	return servers
+}
+
+func TestPropagation_AnnounceAndFetch(t *testing.T) {
+	servers := createNetworkServers(t, 2)
+	key, _ := tests.GenerateKeyAndAddr(t)
+	signer := crypto.NewEIP155Signer(100)
+
+	// the full transactions are not gossiped, so they can only go through the propagation streams
+	announcer := newNetworkTestPool(t, servers[0], &Config{
+		PriceLimit: defaultPriceLimit,
+		MaxSlots:   defaultMaxSlots,
+		NoGossip:   true,
+	})
+	fetcher := newNetworkTestPool(t, servers[1], &Config{
+		PriceLimit: defaultPriceLimit,
+		MaxSlots:   defaultMaxSlots,
+		Sealing:    true,
+		NoGossip:   true,
+	})
+
+	// the pools are started before the peers connect, so that they open the streams on connection
+	for _, pool := range []*TxPool{announcer, fetcher} {
+		pool.Start()
+		defer pool.Close()
+	}
+
+	if joinErrors := network.MeshJoin(servers...); len(joinErrors) != 0 {
+		t.Fatalf("Unable to join servers, %v", joinErrors)
+	}
+
+	assert.Eventually(t, func() bool {
+		return announcer.propagation.getPeer(servers[1].AddrInfo().ID) != nil &&
+			fetcher.propagation.getPeer(servers[0].AddrInfo().ID) != nil
+	}, 5*time.Second, 50*time.Millisecond)
+
+	hashes := make([]types.Hash, 0, 3)
+
+	for nonce := uint64(0); nonce < 3; nonce++ {
+		tx, err := signer.SignTx(newTx(types.ZeroAddress, nonce, 1), key)
+		assert.NoError(t, err)
+		assert.NoError(t, announcer.AddTx(tx))
+
+		hashes = append(hashes, tx.Hash)
+	}
+
+	assert.Eventually(t, func() bool {
+		for _, hash := range hashes {
+			if _, ok := fetcher.index.get(hash); !ok {
+				return false
+			}
+		}
+
+		return true
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// the announced hashes are known by the fetcher, so they are not announced back
+	txPeer := fetcher.propagation.getPeer(servers[0].AddrInfo().ID)
+	assert.Empty(t, txPeer.markKnown(hashes...))
+}
+
+func TestPropagation_GossipFallback(t *testing.T) {
+	servers := createNetworkServers(t, 2)
+	key, _ := tests.GenerateKeyAndAddr(t)
+	signer := crypto.NewEIP155Signer(100)
+
+	publisher := newNetworkTestPool(t, servers[0], &Config{
+		PriceLimit: defaultPriceLimit,
+		MaxSlots:   defaultMaxSlots,
+	})
+	receiver := newNetworkTestPool(t, servers[1], &Config{
+		PriceLimit: defaultPriceLimit,
+		MaxSlots:   defaultMaxSlots,
+		Sealing:    true,
+	})
+
+	// the receiver doesn't support the propagation protocol, as an older node
+	receiver.propagation = nil
+
+	for _, pool := range []*TxPool{publisher, receiver} {
+		pool.Start()
+		defer pool.Close()
+	}
+
+	if joinErrors := network.MeshJoin(servers...); len(joinErrors) != 0 {
+		t.Fatalf("Unable to join servers, %v", joinErrors)
+	}
+
+	// the gossip is dropped until the topic subscriptions are exchanged,
+	// so new transactions are published until the receiver gets one
+	hashes := make([]types.Hash, 0)
+
+	assert.Eventually(t, func() bool {
+		tx, err := signer.SignTx(newTx(types.ZeroAddress, uint64(len(hashes)), 1), key)
+		assert.NoError(t, err)
+		assert.NoError(t, publisher.AddTx(tx))
+
+		hashes = append(hashes, tx.Hash)
+
+		for _, hash := range hashes {
+			if _, ok := receiver.index.get(hash); ok {
+				return true
+			}
+		}
+
+		return false
+	}, 10*time.Second, 200*time.Millisecond)
+
+	assert.Nil(t, publisher.propagation.getPeer(servers[1].AddrInfo().ID))
+}
+
+func TestPropagation_Relay(t *testing.T) {
+	servers := createNetworkServers(t, 3)
+	key, _ := tests.GenerateKeyAndAddr(t)
+	signer := crypto.NewEIP155Signer(100)
+
+	// only the validator is sealing, and it is only connected to the relay,
+	// so the transactions can only get to it through the relay
+	sender := newNetworkTestPool(t, servers[0], &Config{
+		PriceLimit: defaultPriceLimit,
+		MaxSlots:   defaultMaxSlots,
+		NoGossip:   true,
+	})
+	relay := newNetworkTestPool(t, servers[1], &Config{
+		PriceLimit: defaultPriceLimit,
+		MaxSlots:   defaultMaxSlots,
+		NoGossip:   true,
+	})
+	validator := newNetworkTestPool(t, servers[2], &Config{
+		PriceLimit: defaultPriceLimit,
+		MaxSlots:   defaultMaxSlots,
+		Sealing:    true,
+		NoGossip:   true,
+	})
+
+	for _, pool := range []*TxPool{sender, relay, validator} {
+		pool.Start()
+		defer pool.Close()
+	}
+
+	for _, pair := range [][2]*network.Server{{servers[0], servers[1]}, {servers[1], servers[2]}} {
+		err := network.JoinAndWait(pair[0], pair[1], network.DefaultBufferTimeout, network.DefaultJoinTimeout)
+		if err != nil {
+			t.Fatalf("Unable to join servers, %v", err)
+		}
+	}
+
+	assert.Eventually(t, func() bool {
+		return sender.propagation.getPeer(servers[1].AddrInfo().ID) != nil &&
+			relay.propagation.getPeer(servers[2].AddrInfo().ID) != nil
+	}, 5*time.Second, 50*time.Millisecond)
+
+	tx, err := signer.SignTx(newTx(types.ZeroAddress, 0, 1), key)
+	assert.NoError(t, err)
+	assert.NoError(t, sender.AddTx(tx))
+
+	assert.Eventually(t, func() bool {
+		_, ok := validator.index.get(tx.Hash)
+
+		return ok
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// the relay is not sealing, so it only keeps the transaction to serve it
+	_, ok := relay.index.get(tx.Hash)
+	assert.False(t, ok)
+	assert.True(t, relay.propagation.relayed.Contains(tx.Hash))
+}
+
+// propagationClient is a peer serving the given transactions, once released
+type propagationClient struct {
+	proto.TxnPropagationClient
+
+	release chan struct{}
+	txs     []*types.Transaction
+}
+
+func (c *propagationClient) GetTxs(
+	context.Context,
+	*proto.TxnHashes,
+	...grpc.CallOption,
+) (*proto.Txns, error) {
+	<-c.release
+
+	if c.txs == nil {
+		return nil, errors.New("unavailable")
+	}
+
+	resp := &proto.Txns{}
+	for _, tx := range c.txs {
+		resp.Txs = append(resp.Txs, &proto.Txn{Raw: &any.Any{Value: tx.MarshalRLP()}})
+	}
+
+	return resp, nil
+}
+
+func TestPropagation_FetchRetry(t *testing.T) {
+	pool, err := newTestPool()
+	assert.NoError(t, err)
+
+	tx := newTx(addr1, 0, 1)
+	tx.ComputeHash()
+
+	prop := newPropagation(hclog.NewNullLogger(), pool, nil)
+
+	newPeer := func(id peer.ID, client *propagationClient) *txPeer {
+		known, _ := lru.New(maxKnownTxs)
+		p := &txPeer{id: id, client: client, known: known}
+		prop.peers.Store(id, p)
+
+		return p
+	}
+
+	failing := newPeer("failing", &propagationClient{release: make(chan struct{})})
+	honest := newPeer("honest", &propagationClient{release: make(chan struct{}), txs: []*types.Transaction{tx}})
+
+	close(honest.client.(*propagationClient).release)
+
+	announce := func(p *txPeer) {
+		ctx := &libp2pGrpc.Context{Context: context.Background(), PeerID: p.id}
+
+		_, err := prop.AnnounceTxs(ctx, hashesToProto([]types.Hash{tx.Hash}))
+		assert.NoError(t, err)
+	}
+
+	// the hash is fetched from the first peer which announced it, and
+	// the second one is only remembered, until the first fetch fails
+	announce(failing)
+	announce(honest)
+
+	close(failing.client.(*propagationClient).release)
+
+	assert.Eventually(t, func() bool {
+		return prop.relayed.Contains(tx.Hash)
+	}, 5*time.Second, 50*time.Millisecond)
+
+	assert.Eventually(t, func() bool {
+		prop.inFlightLock.Lock()
+		defer prop.inFlightLock.Unlock()
+
+		return len(prop.inFlight) == 0
+	}, 5*time.Second, 50*time.Millisecond)
+}
@@ -0,0 +1,31 @@
+package types
+
+// AccessTuple is an account accessed by a transaction, along with its accessed storage slots
+type AccessTuple struct {
+	Address     Address `json:"address"`
+	StorageKeys []Hash  `json:"storageKeys"`
+}
+
+// AccessList is the list of the accounts and the storage slots accessed by a transaction
+type AccessList []AccessTuple
+
+// Equal checks if the access lists have the same accounts and storage slots, in the same order
+func (l AccessList) Equal(other AccessList) bool {
+	if len(l) != len(other) {
+		return false
+	}
+
+	for i, tuple := range l {
+		if tuple.Address != other[i].Address || len(tuple.StorageKeys) != len(other[i].StorageKeys) {
+			return false
+		}
+
+		for j, key := range tuple.StorageKeys {
+			if key != other[i].StorageKeys[j] {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,26 @@
+package types
+
+import "math/big"
+
+// OverrideAccount is the set of account fields overridden for a call.
+// Nil fields are left as they are in state
+type OverrideAccount struct {
+	Nonce     *uint64
+	Code      []byte
+	Balance   *big.Int
+	State     map[Hash]Hash // replaces the whole account storage
+	StateDiff map[Hash]Hash // replaces only the given storage slots
+}
+
+// StateOverride maps the accounts to the fields overridden for a call
+type StateOverride map[Address]OverrideAccount
+
+// BlockOverride is the set of block context fields overridden for a call.
+// Nil fields are taken from the block the call is executed on
+type BlockOverride struct {
+	Number     *uint64
+	Timestamp  *uint64
+	GasLimit   *uint64
+	Coinbase   *Address
+	Difficulty *big.Int
+}